@@ -0,0 +1,114 @@
+package beef
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	util "github.com/bsv-blockchain/go-sdk/util"
+)
+
+var (
+	// ErrBeefInvalidBumpIndex is returned when a transaction points to a BUMP that does not exist
+	ErrBeefInvalidBumpIndex = errors.New("invalid BEEF - transaction BUMP index out of range")
+	// ErrBeefInvalidLeafHash is returned when a BUMP leaf hash is not a valid 32 byte hex string
+	ErrBeefInvalidLeafHash = errors.New("invalid BUMP leaf hash")
+	// ErrBeefNoTransactions is returned when there are no transactions to encode
+	ErrBeefNoTransactions = errors.New("invalid BEEF - no transactions to encode")
+	// ErrBeefTxIDOnlyInV1 is returned when a TXID-only transaction is encoded as BEEF V1
+	ErrBeefTxIDOnlyInV1 = errors.New("invalid BEEF - TXID-only transactions require BEEF V2")
+)
+
+// Bytes will encode the decoded BEEF in its binary format
+//
+// The format is BEEF V1 (BRC-62) unless Format is BEEFV2 (BRC-96). If SubjectTxID is set,
+// the BEEF is wrapped as an Atomic BEEF (BRC-95)
+func (d *DecodedBEEF) Bytes() ([]byte, error) {
+	if len(d.Transactions) == 0 {
+		return nil, ErrBeefNoTransactions
+	} else if len(d.BUMPs) == 0 && !hasTxIDOnly(d.Transactions) {
+		return nil, ErrBeefNoLowestBump
+	}
+
+	buf := new(bytes.Buffer)
+	if d.IsAtomic() {
+		subject, err := hex.DecodeString(d.SubjectTxID)
+		if err != nil || len(subject) != hashBytesCount {
+			return nil, fmt.Errorf("subject %s: %w", d.SubjectTxID, ErrBeefSubjectTxNotFound)
+		}
+		buf.Write(bytes.Repeat([]byte{atomicBEEFPrefix}, atomicBytesCount))
+		buf.Write(util.ReverseBytes(subject))
+	}
+
+	version := byte(beefV1Version)
+	if d.Format == BEEFV2 {
+		version = beefV2Version
+	}
+	buf.Write([]byte{version, 0x00, BEEFMarkerPart1, BEEFMarkerPart2})
+
+	buf.Write(util.VarInt(len(d.BUMPs)).Bytes())
+	for i, bump := range d.BUMPs {
+		if err := bump.write(buf); err != nil {
+			return nil, fmt.Errorf("BUMP %d: %w", i, err)
+		}
+	}
+
+	buf.Write(util.VarInt(len(d.Transactions)).Bytes())
+	for i, tx := range d.Transactions {
+		if err := d.writeTx(buf, tx); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeTx will write the transaction (BEEF V1: the raw transaction and the BUMP flag,
+// BEEF V2: the data format and the BUMP index or txid before the raw transaction)
+func (d *DecodedBEEF) writeTx(buf *bytes.Buffer, tx *TxData) error {
+	if !tx.Unmined() && uint64(*tx.BumpIndex) >= uint64(len(d.BUMPs)) {
+		return fmt.Errorf("BUMP index %d: %w", *tx.BumpIndex, ErrBeefInvalidBumpIndex)
+	}
+
+	if d.Format != BEEFV2 {
+		if tx.TxIDOnly {
+			return ErrBeefTxIDOnlyInV1
+		}
+		buf.Write(tx.Transaction.Bytes())
+		if tx.Unmined() {
+			buf.WriteByte(HasNoBump)
+			return nil
+		}
+		buf.WriteByte(HasBump)
+		buf.Write(tx.BumpIndex.Bytes())
+		return nil
+	}
+
+	switch {
+	case tx.TxIDOnly:
+		txID, err := hex.DecodeString(tx.GetTxID())
+		if err != nil || len(txID) != hashBytesCount {
+			return fmt.Errorf("txid %s: %w", tx.GetTxID(), ErrBeefInvalidLeafHash)
+		}
+		buf.WriteByte(TxIDOnly)
+		buf.Write(util.ReverseBytes(txID))
+		return nil
+	case tx.Unmined():
+		buf.WriteByte(HasNoBump)
+	default:
+		buf.WriteByte(HasBump)
+		buf.Write(tx.BumpIndex.Bytes())
+	}
+	buf.Write(tx.Transaction.Bytes())
+	return nil
+}
+
+// Hex will encode the decoded BEEF in its binary format as a hex string
+func (d *DecodedBEEF) Hex() (string, error) {
+	beefBytes, err := d.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(beefBytes), nil
+}
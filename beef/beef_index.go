@@ -0,0 +1,87 @@
+package beef
+
+import "sync"
+
+// txIndex is the position of the transactions of a BEEF by txid (built once, on the first lookup)
+type txIndex struct {
+	indexed   []*TxData // The transactions of the index (to find if they were replaced)
+	mu        sync.RWMutex
+	positions map[string]int // The position of the first transaction with the txid
+}
+
+// FindTransaction will return the transaction with the txid (raw or TXID-only, nil if not in the BEEF)
+//
+// Decoded and built BEEFs have a txid index, built on the first lookup and safe for concurrent use. The index
+// is rebuilt if the transactions are appended, removed or replaced by a new slice, but a transaction replaced
+// in place by a transaction with another txid is not indexed (use Reindex). Other BEEFs are searched
+// transaction by transaction until Reindex is called
+func (d *DecodedBEEF) FindTransaction(txID string) *TxData {
+	if d.index == nil {
+		for _, tx := range d.Transactions {
+			if tx.GetTxID() == txID {
+				return tx
+			}
+		}
+		return nil
+	}
+
+	d.index.mu.RLock()
+	tx, ok := d.lookup(txID)
+	d.index.mu.RUnlock()
+	if ok {
+		return tx
+	}
+
+	d.index.mu.Lock()
+	defer d.index.mu.Unlock()
+	if tx, ok = d.lookup(txID); !ok {
+		d.reindex()
+		tx, _ = d.lookup(txID)
+	}
+	return tx
+}
+
+// Reindex will build the txid index of the transactions (see FindTransaction)
+//
+// The index of a BEEF without an index is created: it must not be used concurrently while it is created
+func (d *DecodedBEEF) Reindex() {
+	if d.index == nil {
+		d.index = new(txIndex)
+	}
+
+	d.index.mu.Lock()
+	defer d.index.mu.Unlock()
+	d.reindex()
+}
+
+// lookup will return the transaction with the txid, and false if the index is stale (the index must be locked)
+func (d *DecodedBEEF) lookup(txID string) (*TxData, bool) {
+	if d.index.positions == nil || !sameTransactions(d.index.indexed, d.Transactions) {
+		return nil, false
+	}
+
+	position, ok := d.index.positions[txID]
+	if !ok {
+		return nil, true
+	}
+	if tx := d.Transactions[position]; tx.GetTxID() == txID {
+		return tx, true
+	}
+	return nil, false // the transaction was replaced in place
+}
+
+// reindex will build the txid index of the transactions (the index must be locked)
+func (d *DecodedBEEF) reindex() {
+	d.index.indexed = d.Transactions
+	d.index.positions = make(map[string]int, len(d.Transactions))
+	for i, tx := range d.Transactions {
+		if _, ok := d.index.positions[tx.GetTxID()]; !ok {
+			d.index.positions[tx.GetTxID()] = i
+		}
+	}
+}
+
+// sameTransactions will return true if the slices are the same slice of transactions
+func sameTransactions(indexed, transactions []*TxData) bool {
+	return len(indexed) == len(transactions) && (len(indexed) == 0 || &indexed[0] == &transactions[0])
+}
@@ -0,0 +1,111 @@
+package beef
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodedBEEF_FindTransaction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("decoded transactions", func(t *testing.T) {
+		// given
+		decoded, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+
+		// when & then
+		for _, tx := range decoded.Transactions {
+			assert.Same(t, tx, decoded.FindTransaction(tx.GetTxID()))
+		}
+		assert.Nil(t, decoded.FindTransaction(newTestTx(1).TxID().String()))
+	})
+
+	t.Run("appended and removed transactions", func(t *testing.T) {
+		// given
+		decoded, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		first, appended := decoded.Transactions[0], &TxData{Transaction: newTestTx(1)}
+		require.NotNil(t, decoded.FindTransaction(first.GetTxID()))
+
+		// when
+		decoded.Transactions = append(decoded.Transactions[1:], appended)
+
+		// then
+		assert.Same(t, appended, decoded.FindTransaction(appended.GetTxID()))
+		assert.Nil(t, decoded.FindTransaction(first.GetTxID()))
+	})
+
+	t.Run("transaction replaced in place", func(t *testing.T) {
+		// given
+		decoded, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		first, replacement := decoded.Transactions[0], &TxData{Transaction: newTestTx(1)}
+		require.NotNil(t, decoded.FindTransaction(first.GetTxID()))
+
+		// when
+		decoded.Transactions[0] = replacement
+
+		// then
+		assert.Nil(t, decoded.FindTransaction(first.GetTxID()))
+		decoded.Reindex()
+		assert.Same(t, replacement, decoded.FindTransaction(replacement.GetTxID()))
+	})
+
+	t.Run("BEEF without index", func(t *testing.T) {
+		// given
+		tx := &TxData{Transaction: newTestTx(1)}
+		dBeef := &DecodedBEEF{Format: BEEFV1, Transactions: []*TxData{NewTxIDOnly(newTestTx(2).TxID().String()), tx}}
+
+		// when & then
+		assert.Same(t, tx, dBeef.FindTransaction(tx.GetTxID()))
+		dBeef.Reindex()
+		assert.Same(t, tx, dBeef.FindTransaction(tx.GetTxID()))
+	})
+
+	t.Run("concurrent lookups", func(t *testing.T) {
+		// given
+		decoded, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+
+		// when
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, tx := range decoded.Transactions {
+					assert.Same(t, tx, decoded.FindTransaction(tx.Transaction.TxID().String()))
+				}
+			}()
+		}
+
+		// then
+		wg.Wait()
+	})
+}
+
+// BenchmarkDecodedBEEF_FindTransaction benchmarks the lookup of every transaction of BEEFs with thousands of transactions
+func BenchmarkDecodedBEEF_FindTransaction(b *testing.B) {
+	for _, size := range []int{1000, 10000} {
+		transactions := make([]*TxData, 0, size)
+		for i := range size {
+			transactions = append(transactions, &TxData{Transaction: newTestTx(uint32(i))}) // #nosec G115 - test sizes
+		}
+		dBeef := &DecodedBEEF{Format: BEEFV1, Transactions: transactions}
+		dBeef.Reindex()
+
+		b.Run(fmt.Sprintf("%d transactions", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, tx := range transactions {
+					if dBeef.FindTransaction(tx.GetTxID()) != tx {
+						b.Fatal("transaction not found")
+					}
+				}
+			}
+		})
+	}
+}
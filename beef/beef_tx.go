@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
+	"sync"
 
 	sdk "github.com/bsv-blockchain/go-sdk/transaction"
 	util "github.com/bsv-blockchain/go-sdk/util"
@@ -18,12 +20,29 @@ const (
 const (
 	HasNoBump = 0x00
 	HasBump   = 0x01
+	TxIDOnly  = 0x02 // BEEF V2 only: the transaction is known by the receiver (no raw transaction)
+)
+
+// BEEFFormat is the version of the BEEF format
+type BEEFFormat uint8
+
+const (
+	BEEFV1 BEEFFormat = iota + 1 // BRC-62 (0100BEEF)
+	BEEFV2                       // BRC-96 (0200BEEF) with TXID-only entries
+)
+
+// Version bytes of the BEEF formats (the BEEF marker follows)
+const (
+	beefV1Version    = 0x01
+	beefV2Version    = 0x02
+	atomicBEEFPrefix = 0x01
 )
 
 const (
 	hashBytesCount    = 32
 	markerBytesCount  = 2
 	versionBytesCount = 2
+	atomicBytesCount  = 4
 	maxTreeHeight     = 64
 )
 
@@ -50,6 +69,8 @@ var (
 	ErrBeefInvalidTreeHeightMax = errors.New("invalid BEEF - treeHeight cannot be grater than 64")
 	// ErrBeefInsufficientBytesOffset is returned when there are insufficient bytes to extract offset
 	ErrBeefInsufficientBytesOffset = errors.New("insufficient bytes to extract offset")
+	// ErrBeefInsufficientBytesVarInt is returned when there are insufficient bytes to extract a VarInt
+	ErrBeefInsufficientBytesVarInt = errors.New("insufficient bytes to extract VarInt")
 	// ErrBeefInsufficientBytesFlag is returned when there are insufficient bytes to extract flag
 	ErrBeefInsufficientBytesFlag = errors.New("insufficient bytes to extract flag")
 	// ErrBeefInvalidFlag is returned when flag is invalid
@@ -58,100 +79,127 @@ var (
 	ErrBeefInvalidHasCMPFlag = errors.New("invalid HasCMP flag for transaction")
 	// ErrBeefIntegerOverflow is returned when an integer conversion would cause overflow
 	ErrBeefIntegerOverflow = errors.New("integer value exceeds maximum safe conversion range")
+	// ErrBeefInvalidVersion is returned when the BEEF version is not supported
+	ErrBeefInvalidVersion = errors.New("invalid BEEF version, expected V1 or V2")
+	// ErrBeefInvalidDataFormat is returned when the data format of a BEEF V2 transaction is invalid
+	ErrBeefInvalidDataFormat = errors.New("invalid data format for transaction")
+	// ErrBeefSubjectTxNotFound is returned when the subject transaction of an Atomic BEEF is missing
+	ErrBeefSubjectTxNotFound = errors.New("invalid Atomic BEEF - subject transaction not found")
 )
 
 type TxData struct {
 	Transaction *sdk.Transaction `json:"transaction"`
 	BumpIndex   *util.VarInt     `json:"bumpIndex"`
+	TxIDOnly    bool             `json:"txIdOnly,omitempty"` // BEEF V2: known transaction without a raw transaction
+
+	txID     string
+	txIDOnce sync.Once
+}
 
-	txID string
+// NewTxIDOnly will return a TXID-only entry (BEEF V2) for a transaction known by the receiver
+func NewTxIDOnly(txID string) *TxData {
+	return &TxData{TxIDOnly: true, txID: txID}
 }
 
 func (td *TxData) Unmined() bool {
 	return td.BumpIndex == nil
 }
 
+// GetTxID will return the txid of the transaction (safe for concurrent use)
+//
+// The txid is calculated once: the transaction must not be changed after
 func (td *TxData) GetTxID() string {
-	if len(td.txID) == 0 {
-		td.txID = td.Transaction.TxID().String()
-	}
+	td.txIDOnce.Do(func() {
+		if len(td.txID) == 0 {
+			td.txID = td.Transaction.TxID().String()
+		}
+	})
 
 	return td.txID
 }
 
 type DecodedBEEF struct {
-	BUMPs        BUMPs     `json:"bumps"`
-	Transactions []*TxData `json:"transactions"`
+	BUMPs        BUMPs      `json:"bumps"`
+	Format       BEEFFormat `json:"format"`
+	SubjectTxID  string     `json:"subjectTxId,omitempty"` // Atomic BEEF (BRC-95) only
+	Transactions []*TxData  `json:"transactions"`
+
+	index *txIndex // The txid index of the transactions (see FindTransaction)
 }
 
+// DecodeBEEF will decode BEEF V1 (BRC-62), BEEF V2 (BRC-96) and Atomic BEEF (BRC-95) hex streams
+//
+// The BEEF is decoded by the Decoder with the default limits (use NewDecoder for other limits)
 func DecodeBEEF(beefHex string) (*DecodedBEEF, error) {
-	beefBytes, err := extractBytesWithoutVersionAndMarker(beefHex)
-	if err != nil {
-		return nil, err
-	}
+	return NewDecoder(hex.NewDecoder(strings.NewReader(beefHex)), Limits{}).decode()
+}
 
-	bumps, remainingBytes, err := decodeBUMPs(beefBytes)
-	if err != nil {
-		return nil, err
+// IsAtomic will return true if the BEEF was an Atomic BEEF (BRC-95) with a subject transaction
+func (d *DecodedBEEF) IsAtomic() bool {
+	return len(d.SubjectTxID) > 0
+}
+
+func (d *DecodedBEEF) GetLatestTx() *sdk.Transaction {
+	// the subject transaction of an Atomic BEEF is the processed transaction
+	if d.IsAtomic() {
+		if subject := d.findTx(d.SubjectTxID); subject != nil {
+			return subject.Transaction
+		}
 	}
 
-	transactions, err := decodeTransactionsWithPathIndexes(remainingBytes)
-	if err != nil {
-		return nil, err
+	if len(d.Transactions) == 0 {
+		return nil
 	}
+	return d.Transactions[len(d.Transactions)-1].Transaction // get the last transaction as the processed transaction - it should be the last one because of khan's ordering (see ValidateTransactionOrder)
+}
 
-	return &DecodedBEEF{
-		BUMPs:        bumps,
-		Transactions: transactions,
-	}, nil
+// hasTxIDOnly will return true if any of the transactions is a TXID-only entry
+func hasTxIDOnly(transactions []*TxData) bool {
+	for _, tx := range transactions {
+		if tx.TxIDOnly {
+			return true
+		}
+	}
+	return false
 }
 
-func (d *DecodedBEEF) GetLatestTx() *sdk.Transaction {
-	return d.Transactions[len(d.Transactions)-1].Transaction // get the last transaction as the processed transaction - it should be the last one because of khan's ordering
+// findTx will return the (raw) transaction with the txid
+func (d *DecodedBEEF) findTx(txID string) *TxData {
+	if tx := d.FindTransaction(txID); tx != nil && !tx.TxIDOnly {
+		return tx
+	}
+	return nil
 }
 
-func decodeBUMPs(beefBytes []byte) ([]*BUMP, []byte, error) {
+func decodeBUMP(beefBytes []byte) (*BUMP, []byte, error) {
 	if len(beefBytes) == 0 {
-		return nil, nil, ErrBeefNoBytesForBump
+		return nil, nil, ErrBeefInsufficientBytesBlockHeight
 	}
-
-	nBump, bytesUsed := util.NewVarIntFromBytes(beefBytes)
-
-	if nBump == 0 {
-		return nil, nil, ErrBeefNoLowestBump
+	blockHeight, bytesUsed, err := varIntFromBytes(beefBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("BUMP blockHeight: %w", err)
 	}
-
 	beefBytes = beefBytes[bytesUsed:]
 
-	bumps := make([]*BUMP, 0, uint64(nBump))
-	for i := uint64(0); i < uint64(nBump); i++ {
-		if len(beefBytes) == 0 {
-			return nil, nil, ErrBeefInsufficientBytesBlockHeight
-		}
-		blockHeight, bytesUsed := util.NewVarIntFromBytes(beefBytes)
-		beefBytes = beefBytes[bytesUsed:]
-
-		treeHeight := beefBytes[0]
-		if int(treeHeight) > maxTreeHeight {
-			return nil, nil, fmt.Errorf("treeHeight: %d: %w", treeHeight, ErrBeefInvalidTreeHeight)
-		}
-		beefBytes = beefBytes[1:]
-
-		bumpPaths, remainingBytes, err := decodeBUMPPathsFromStream(int(treeHeight), beefBytes)
-		if err != nil {
-			return nil, nil, err
-		}
-		beefBytes = remainingBytes
-
-		bump := &BUMP{
-			BlockHeight: uint64(blockHeight),
-			Path:        bumpPaths,
-		}
+	if len(beefBytes) == 0 {
+		return nil, nil, ErrBeefNoBytesForPaths
+	}
+	treeHeight := beefBytes[0]
+	if int(treeHeight) > maxTreeHeight {
+		return nil, nil, fmt.Errorf("treeHeight: %d: %w", treeHeight, ErrBeefInvalidTreeHeight)
+	}
+	beefBytes = beefBytes[1:]
 
-		bumps = append(bumps, bump)
+	bumpPaths, remainingBytes, err := decodeBUMPPathsFromStream(int(treeHeight), beefBytes)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return bumps, beefBytes, nil
+	bump := &BUMP{
+		BlockHeight: uint64(blockHeight),
+		Path:        bumpPaths,
+	}
+	return bump, remainingBytes, nil
 }
 
 func decodeBUMPPathsFromStream(treeHeight int, hexBytes []byte) ([][]BUMPLeaf, []byte, error) {
@@ -161,7 +209,10 @@ func decodeBUMPPathsFromStream(treeHeight int, hexBytes []byte) ([][]BUMPLeaf, [
 		if len(hexBytes) == 0 {
 			return nil, nil, ErrBeefNoBytesForPaths
 		}
-		nLeaves, bytesUsed := util.NewVarIntFromBytes(hexBytes)
+		nLeaves, bytesUsed, err := varIntFromBytes(hexBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("number of leaves: %w", err)
+		}
 		hexBytes = hexBytes[bytesUsed:]
 		bumpPath, remainingBytes, err := decodeBUMPLevel(nLeaves, hexBytes)
 		if err != nil {
@@ -187,7 +238,10 @@ func decodeBUMPLevel(nLeaves util.VarInt, hexBytes []byte) ([]BUMPLeaf, []byte,
 			return nil, nil, fmt.Errorf("leaf %d of %d: %w", i, nLeavesInt, ErrBeefInsufficientBytesOffset)
 		}
 
-		offset, bytesUsed := util.NewVarIntFromBytes(hexBytes)
+		offset, bytesUsed, err := varIntFromBytes(hexBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("leaf %d of %d: %w", i, nLeavesInt, err)
+		}
 		hexBytes = hexBytes[bytesUsed:]
 
 		if len(hexBytes) == 0 {
@@ -230,72 +284,50 @@ func decodeBUMPLevel(nLeaves util.VarInt, hexBytes []byte) ([]BUMPLeaf, []byte,
 	return bumpPath, hexBytes, nil
 }
 
-func decodeTransactionsWithPathIndexes(bytes []byte) ([]*TxData, error) {
-	nTransactions, offset := util.NewVarIntFromBytes(bytes)
-
-	if nTransactions < 2 {
-		return nil, ErrBeefInsufficientTransactions
+// varIntFromBytes will read a VarInt from the bytes (unlike util.NewVarIntFromBytes, the length is checked)
+func varIntFromBytes(bytes []byte) (util.VarInt, int, error) {
+	if len(bytes) == 0 {
+		return 0, 0, ErrBeefInsufficientBytesVarInt
 	}
 
-	// Check for integer overflow before converting uint64 to int
-	if nTransactions > math.MaxInt {
-		return nil, fmt.Errorf("number of transactions %d: %w", nTransactions, ErrBeefIntegerOverflow)
+	size := 1
+	switch bytes[0] {
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 5
+	case 0xff:
+		size = 9
+	}
+	if len(bytes) < size {
+		return 0, 0, ErrBeefInsufficientBytesVarInt
 	}
-	nTransactionsInt := int(nTransactions) // #nosec G115 - overflow checked above
-
-	bytes = bytes[offset:]
 
-	transactions := make([]*TxData, 0, nTransactionsInt)
+	value, bytesUsed := util.NewVarIntFromBytes(bytes)
+	return value, bytesUsed, nil
+}
 
-	for i := 0; i < nTransactionsInt; i++ {
-		tx, offset, err := sdk.NewTransactionFromStream(bytes)
-		if err != nil {
-			return nil, err
-		}
-		bytes = bytes[offset:]
-
-		var pathIndex *util.VarInt
-
-		switch bytes[0] {
-		case HasBump:
-			value, offset := util.NewVarIntFromBytes(bytes[1:])
-			pathIndex = &value
-			bytes = bytes[1+offset:]
-		case HasNoBump:
-			bytes = bytes[1:]
-		default:
-			return nil, fmt.Errorf("transaction at index %d: %w", i, ErrBeefInvalidHasCMPFlag)
+func isAtomicPrefix(bytes []byte) bool {
+	for _, b := range bytes[:atomicBytesCount] {
+		if b != atomicBEEFPrefix {
+			return false
 		}
-
-		transactions = append(transactions, &TxData{
-			Transaction: tx,
-			BumpIndex:   pathIndex,
-		})
 	}
-
-	return transactions, nil
+	return true
 }
 
-func extractBytesWithoutVersionAndMarker(hexStream string) ([]byte, error) {
-	bytes, err := hex.DecodeString(hexStream)
-	if err != nil {
-		return nil, ErrBeefInvalidHexStream
-	}
-	if len(bytes) < 4 {
-		return nil, ErrBeefInvalidHexStream
+func parseVersion(bytes []byte) (BEEFFormat, error) {
+	if bytes[1] != 0x00 {
+		return 0, ErrBeefInvalidVersion
 	}
 
-	// removes version bytes
-	bytes = bytes[versionBytesCount:]
-	err = validateMarker(bytes)
-	if err != nil {
-		return nil, err
+	switch bytes[0] {
+	case beefV1Version:
+		return BEEFV1, nil
+	case beefV2Version:
+		return BEEFV2, nil
 	}
-
-	// removes marker bytes
-	bytes = bytes[markerBytesCount:]
-
-	return bytes, nil
+	return 0, ErrBeefInvalidVersion
 }
 
 func validateMarker(bytes []byte) error {
@@ -1,6 +1,9 @@
 package beef
 
 import (
+	"encoding/hex"
+	"io"
+	"sync"
 	"testing"
 
 	script "github.com/bsv-blockchain/go-sdk/script"
@@ -282,3 +285,219 @@ func TestDecodeBEEF_InvalidBeef_RetunrError(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeBEEF_BEEFV2AndAtomicBEEF(t *testing.T) {
+	// given a BEEF V1 (the parent is mined, the child is the subject)
+	decodedV1, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	parent, child := decodedV1.Transactions[0], decodedV1.Transactions[1]
+	merklePath := toMerklePath(t, decodedV1.BUMPs[0])
+
+	// and the same transactions encoded by the go-sdk
+	sdkBEEF := sdk.NewBeefV2()
+	parent.Transaction.MerklePath = merklePath
+	_, err = sdkBEEF.MergeTransaction(parent.Transaction)
+	require.NoError(t, err)
+	_, err = sdkBEEF.MergeTransaction(child.Transaction)
+	require.NoError(t, err)
+
+	t.Run("BEEF V2", func(t *testing.T) {
+		beefBytes, err := sdkBEEF.Bytes()
+		require.NoError(t, err)
+
+		// when
+		decoded, err := DecodeBEEF(hex.EncodeToString(beefBytes))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, BEEFV2, decoded.Format)
+		assert.False(t, decoded.IsAtomic())
+		assert.Equal(t, decodedV1.BUMPs, decoded.BUMPs)
+		require.Len(t, decoded.Transactions, 2)
+		assert.Equal(t, parent.GetTxID(), decoded.Transactions[0].GetTxID())
+		assert.Equal(t, uint64(0), uint64(*decoded.Transactions[0].BumpIndex))
+		assert.Equal(t, child.GetTxID(), decoded.GetLatestTx().TxID().String())
+
+		beefHex, err := decoded.Hex()
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(beefBytes), beefHex)
+	})
+
+	t.Run("Atomic BEEF", func(t *testing.T) {
+		beefBytes, err := sdkBEEF.AtomicBytes(child.Transaction.TxID())
+		require.NoError(t, err)
+
+		// when
+		decoded, err := DecodeBEEF(hex.EncodeToString(beefBytes))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, BEEFV2, decoded.Format)
+		assert.True(t, decoded.IsAtomic())
+		assert.Equal(t, child.GetTxID(), decoded.SubjectTxID)
+		assert.Equal(t, child.GetTxID(), decoded.GetLatestTx().TxID().String())
+
+		beefHex, err := decoded.Hex()
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(beefBytes), beefHex)
+	})
+
+	t.Run("Atomic BEEF V1", func(t *testing.T) {
+		atomic := *decodedV1
+		atomic.SubjectTxID = child.GetTxID()
+		beefHex, err := atomic.Hex()
+		require.NoError(t, err)
+		assert.Equal(t, "01010101", beefHex[:8])
+
+		// when
+		decoded, err := DecodeBEEF(beefHex)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, BEEFV1, decoded.Format)
+		assert.Equal(t, child.GetTxID(), decoded.SubjectTxID)
+	})
+
+	t.Run("TXID-only entries", func(t *testing.T) {
+		txidOnlyBEEF := sdkBEEF.Clone()
+		txidOnlyBEEF.MakeTxidOnly(parent.Transaction.TxID())
+		beefBytes, err := txidOnlyBEEF.Bytes()
+		require.NoError(t, err)
+
+		// when
+		decoded, err := DecodeBEEF(hex.EncodeToString(beefBytes))
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, decoded.Transactions, 2)
+		known := decoded.Transactions[0]
+		assert.True(t, known.TxIDOnly)
+		assert.Nil(t, known.Transaction)
+		assert.Equal(t, parent.GetTxID(), known.GetTxID())
+		assert.Equal(t, child.GetTxID(), decoded.GetLatestTx().TxID().String())
+
+		beefHex, err := decoded.Hex()
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(beefBytes), beefHex)
+
+		// TXID-only entries cannot be encoded as BEEF V1
+		decoded.Format = BEEFV1
+		_, err = decoded.Bytes()
+		require.ErrorIs(t, err, ErrBeefTxIDOnlyInV1)
+	})
+
+	t.Run("Atomic BEEF without the subject transaction", func(t *testing.T) {
+		beefBytes, err := sdkBEEF.AtomicBytes(child.Transaction.TxID())
+		require.NoError(t, err)
+		copy(beefBytes[atomicBytesCount:], make([]byte, hashBytesCount))
+
+		// when
+		decoded, err := DecodeBEEF(hex.EncodeToString(beefBytes))
+
+		// then
+		require.ErrorIs(t, err, ErrBeefSubjectTxNotFound)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		decoded, err := DecodeBEEF("0300beef" + testBEEFHex[8:])
+		require.ErrorIs(t, err, ErrBeefInvalidVersion)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("invalid data format", func(t *testing.T) {
+		beefBytes, err := sdkBEEF.Bytes()
+		require.NoError(t, err)
+		beefHex := hex.EncodeToString(beefBytes)
+		txsAt := len(beefHex) - len(hex.EncodeToString(child.Transaction.Bytes())) - 2
+
+		// when
+		decoded, err := DecodeBEEF(beefHex[:txsAt] + "03" + beefHex[txsAt+2:])
+
+		// then
+		require.ErrorIs(t, err, ErrBeefInvalidDataFormat)
+		assert.Nil(t, decoded)
+	})
+}
+
+func TestDecodeBEEF_TruncatedBEEF(t *testing.T) {
+	t.Parallel()
+
+	decoded, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	decoded.Format = BEEFV2
+	v2Hex, err := decoded.Hex()
+	require.NoError(t, err)
+	decoded.SubjectTxID = decoded.Transactions[len(decoded.Transactions)-1].GetTxID()
+	atomicHex, err := decoded.Hex()
+	require.NoError(t, err)
+
+	for name, beefHex := range map[string]string{"BEEF V1": testBEEFHex, "BEEF V2": v2Hex, "Atomic BEEF": atomicHex} {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < len(beefHex); i += 2 {
+				require.NotPanics(t, func() {
+					_, err = DecodeBEEF(beefHex[:i])
+				}, i)
+				require.Error(t, err, i)
+			}
+		})
+	}
+
+	t.Run("BUMP index without bytes", func(t *testing.T) {
+		_, err := DecodeBEEF("0200beef000201")
+		requireDecodeError(t, err, io.ErrUnexpectedEOF, 7)
+	})
+
+	t.Run("truncated VarInt", func(t *testing.T) {
+		_, err := DecodeBEEF("0100beef01fe")
+		requireDecodeError(t, err, io.ErrUnexpectedEOF, 6)
+	})
+
+	t.Run("odd length hex", func(t *testing.T) {
+		_, err := DecodeBEEF(testBEEFHex + "0")
+		requireDecodeError(t, err, io.ErrUnexpectedEOF, int64(len(testBEEFHex)/2))
+	})
+}
+
+func TestDecodeBEEF_TrailingBytes(t *testing.T) {
+	t.Parallel()
+
+	// when
+	decoded, err := DecodeBEEF(testBEEFHex + "00")
+
+	// then
+	requireDecodeError(t, err, ErrBeefTrailingBytes, int64(len(testBEEFHex)/2))
+	assert.Nil(t, decoded)
+}
+
+func TestTxData_GetTxID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("concurrent calls", func(t *testing.T) {
+		// given
+		tx := newTestTx(1)
+		txDt := &TxData{Transaction: tx}
+
+		// when
+		txIDs := make([]string, 8)
+		var wg sync.WaitGroup
+		for i := range txIDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				txIDs[i] = txDt.GetTxID()
+			}()
+		}
+		wg.Wait()
+
+		// then
+		for _, txID := range txIDs {
+			assert.Equal(t, tx.TxID().String(), txID)
+		}
+	})
+
+	t.Run("TXID-only transaction", func(t *testing.T) {
+		txID := newTestTx(1).TxID().String()
+		assert.Equal(t, txID, NewTxIDOnly(txID).GetTxID())
+	})
+}
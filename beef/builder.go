@@ -0,0 +1,207 @@
+package beef
+
+import (
+	"errors"
+	"fmt"
+
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	util "github.com/bsv-blockchain/go-sdk/util"
+)
+
+var (
+	// ErrBuilderNilTransaction is returned when a nil transaction is added to the builder
+	ErrBuilderNilTransaction = errors.New("cannot add a nil transaction to BEEF")
+	// ErrBuilderTxNotInBump is returned when the BUMP of a transaction does not contain its txid
+	ErrBuilderTxNotInBump = errors.New("BUMP does not contain the transaction")
+	// ErrBuilderCyclicTransactions is returned when the transactions cannot be ordered topologically
+	ErrBuilderCyclicTransactions = errors.New("transactions cannot be ordered - cyclic dependency")
+)
+
+// Builder will build a BEEF (BRC-62) from transactions, their ancestors and the BUMPs of the mined ones
+//
+// BUMPs for the same block are merged into one, and the transactions are ordered topologically (parents first)
+type Builder struct {
+	bumps BUMPs
+	order []string
+	txs   map[string]*TxData
+}
+
+// NewBuilder will return an empty BEEF builder
+func NewBuilder() *Builder {
+	return &Builder{txs: make(map[string]*TxData)}
+}
+
+// AddTransaction will add the transaction, with its BUMP if the transaction is mined
+//
+// If bump is nil, the merkle path of the transaction (tx.MerklePath) is used if set.
+// For unmined transactions, the source transactions of the inputs (if set) are added too
+func (b *Builder) AddTransaction(tx *sdk.Transaction, bump *BUMP) error {
+	if tx == nil {
+		return ErrBuilderNilTransaction
+	}
+	if bump == nil && tx.MerklePath != nil {
+		bump = bumpFromMerklePath(tx.MerklePath)
+	}
+
+	txData := &TxData{Transaction: tx}
+	txID := txData.GetTxID()
+
+	// Already added (only a BUMP can be added later)
+	existing, ok := b.txs[txID]
+	if ok && (bump == nil || !existing.Unmined()) {
+		return nil
+	}
+
+	if bump != nil {
+		if !bump.containsTx(txID) {
+			return fmt.Errorf("tx %s, block %d: %w", txID, bump.BlockHeight, ErrBuilderTxNotInBump)
+		}
+		index, err := b.addBUMP(bump)
+		if err != nil {
+			return err
+		}
+		bumpIndex := util.VarInt(index)
+		txData.BumpIndex = &bumpIndex
+	}
+
+	if ok {
+		existing.BumpIndex = txData.BumpIndex
+		return nil
+	}
+	b.txs[txID] = txData
+	b.order = append(b.order, txID)
+
+	// Mined transactions do not need their ancestors
+	if !txData.Unmined() {
+		return nil
+	}
+	for _, input := range tx.Inputs {
+		if input.SourceTransaction == nil {
+			continue
+		}
+		if err := b.AddTransaction(input.SourceTransaction, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Build will return the decoded BEEF (use Bytes or Hex to serialize it)
+func (b *Builder) Build() (*DecodedBEEF, error) {
+	if len(b.txs) == 0 {
+		return nil, ErrBeefNoTransactions
+	} else if len(b.bumps) == 0 {
+		return nil, ErrBeefNoLowestBump
+	}
+
+	transactions, err := b.sortTransactions()
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedBEEF{
+		BUMPs:        b.bumps,
+		Format:       BEEFV1,
+		Transactions: transactions,
+		index:        new(txIndex),
+	}, nil
+}
+
+// addBUMP will add the BUMP (merged with the BUMP of the same block, if any) and return its index
+func (b *Builder) addBUMP(bump *BUMP) (int, error) {
+	for i, existing := range b.bumps {
+		if existing.BlockHeight != bump.BlockHeight {
+			continue
+		}
+		merged, err := MergeBUMPs(existing, bump)
+		if err != nil {
+			return 0, fmt.Errorf("block %d: %w", bump.BlockHeight, err)
+		}
+		b.bumps[i] = merged
+		return i, nil
+	}
+
+	b.bumps = append(b.bumps, bump)
+	return len(b.bumps) - 1, nil
+}
+
+// sortTransactions will order the transactions topologically using Kahn's algorithm (parents first)
+//
+// Transactions without dependencies between them keep the order in which they were added
+func (b *Builder) sortTransactions() ([]*TxData, error) {
+	inDegree := make(map[string]int, len(b.order))
+	children := make(map[string][]string, len(b.order))
+	for _, txID := range b.order {
+		parents := make(map[string]struct{})
+		for _, input := range b.txs[txID].Transaction.Inputs {
+			if input.SourceTXID == nil {
+				continue
+			}
+			parentID := input.SourceTXID.String()
+			if _, found := b.txs[parentID]; !found {
+				continue
+			} else if _, seen := parents[parentID]; seen {
+				continue
+			}
+			parents[parentID] = struct{}{}
+			children[parentID] = append(children[parentID], txID)
+			inDegree[txID]++
+		}
+	}
+
+	queue := make([]string, 0, len(b.order))
+	for _, txID := range b.order {
+		if inDegree[txID] == 0 {
+			queue = append(queue, txID)
+		}
+	}
+
+	sorted := make([]*TxData, 0, len(b.order))
+	for len(queue) > 0 {
+		txID := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, b.txs[txID])
+		for _, child := range children[txID] {
+			if inDegree[child]--; inDegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	if len(sorted) != len(b.order) {
+		return nil, ErrBuilderCyclicTransactions
+	}
+	return sorted, nil
+}
+
+// containsTx will return true if the BUMP has a txid leaf for the transaction
+func (b *BUMP) containsTx(txID string) bool {
+	if len(b.Path) == 0 {
+		return false
+	}
+	for _, leaf := range b.Path[0] {
+		if leaf.TxId && leaf.Hash == txID {
+			return true
+		}
+	}
+	return false
+}
+
+// bumpFromMerklePath will convert the go-sdk merkle path into a BUMP
+func bumpFromMerklePath(merklePath *sdk.MerklePath) *BUMP {
+	bump := &BUMP{BlockHeight: uint64(merklePath.BlockHeight), Path: make([][]BUMPLeaf, len(merklePath.Path))}
+	for i, level := range merklePath.Path {
+		bump.Path[i] = make([]BUMPLeaf, 0, len(level))
+		for _, element := range level {
+			leaf := BUMPLeaf{
+				Duplicate: element.Duplicate != nil && *element.Duplicate,
+				Offset:    element.Offset,
+				TxId:      element.Txid != nil && *element.Txid,
+			}
+			if element.Hash != nil {
+				leaf.Hash = element.Hash.String()
+			}
+			bump.Path[i] = append(bump.Path[i], leaf)
+		}
+	}
+	return bump
+}
@@ -0,0 +1,227 @@
+package beef
+
+import (
+	"bytes"
+	"testing"
+
+	script "github.com/bsv-blockchain/go-sdk/script"
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTx will return a transaction spending the first output of the parents (the lock time makes it unique)
+func newTestTx(lockTime uint32, parents ...*sdk.Transaction) *sdk.Transaction {
+	tx := sdk.NewTransaction()
+	tx.LockTime = lockTime
+	for _, parent := range parents {
+		tx.AddInput(&sdk.TransactionInput{
+			SourceTXID:        parent.TxID(),
+			SourceTransaction: parent,
+			SourceTxOutIndex:  0,
+			UnlockingScript:   &script.Script{},
+			SequenceNumber:    0xffffffff,
+		})
+	}
+	tx.AddOutput(&sdk.TransactionOutput{Satoshis: 1000, LockingScript: &script.Script{script.OpTRUE}})
+	return tx
+}
+
+// newTestBlock will return the BUMPs of the first two transactions of a four transaction block
+func newTestBlock(t *testing.T, blockHeight uint64, first, second *sdk.Transaction) (*BUMP, *BUMP) {
+	third, fourth := newTestTx(3), newTestTx(4)
+	right, err := merkleTreeParentStr(third.TxID().String(), fourth.TxID().String())
+	require.NoError(t, err)
+
+	firstID, secondID := first.TxID().String(), second.TxID().String()
+	firstBUMP := &BUMP{BlockHeight: blockHeight, Path: [][]BUMPLeaf{
+		{{Hash: firstID, TxId: true, Offset: 0}, {Hash: secondID, Offset: 1}},
+		{{Hash: right, Offset: 1}},
+	}}
+	secondBUMP := &BUMP{BlockHeight: blockHeight, Path: [][]BUMPLeaf{
+		{{Hash: firstID, Offset: 0}, {Hash: secondID, TxId: true, Offset: 1}},
+		{{Hash: right, Offset: 1}},
+	}}
+	return firstBUMP, secondBUMP
+}
+
+func TestBuilder_Build(t *testing.T) {
+	t.Run("round trip of a decoded BEEF", func(t *testing.T) {
+		// given
+		decodedBEEF, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		parent, child := decodedBEEF.Transactions[0].Transaction, decodedBEEF.Transactions[1].Transaction
+		child.Inputs[0].SourceTransaction = parent
+
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(parent, decodedBEEF.BUMPs[0]))
+		require.NoError(t, builder.AddTransaction(child, nil))
+
+		// when
+		built, err := builder.Build()
+		require.NoError(t, err)
+		beefHex, err := built.Hex()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, testBEEFHex, beefHex)
+	})
+
+	t.Run("ancestors are added and ordered topologically", func(t *testing.T) {
+		// given
+		first, second := newTestTx(1), newTestTx(2)
+		firstBUMP, secondBUMP := newTestBlock(t, 800000, first, second)
+		first.MerklePath = toMerklePath(t, firstBUMP)
+		second.MerklePath = toMerklePath(t, secondBUMP)
+		parent := newTestTx(10, first)
+		child := newTestTx(11, parent, second)
+
+		// when
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(child, nil))
+		built, err := builder.Build()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, built.Transactions, 4)
+		index := make(map[string]int)
+		for i, tx := range built.Transactions {
+			index[tx.GetTxID()] = i
+		}
+		assert.Less(t, index[first.TxID().String()], index[parent.TxID().String()])
+		assert.Less(t, index[parent.TxID().String()], index[child.TxID().String()])
+		assert.Less(t, index[second.TxID().String()], index[child.TxID().String()])
+		assert.Equal(t, child.TxID(), built.GetLatestTx().TxID())
+
+		beefHex, err := built.Hex()
+		require.NoError(t, err)
+		decoded, err := DecodeBEEF(beefHex)
+		require.NoError(t, err)
+		assert.Equal(t, built.BUMPs, decoded.BUMPs)
+		assert.Equal(t, child.TxID(), decoded.GetLatestTx().TxID())
+	})
+
+	t.Run("parents added after the child come first", func(t *testing.T) {
+		// given
+		first, second := newTestTx(1), newTestTx(2)
+		firstBUMP, _ := newTestBlock(t, 800000, first, second)
+		child := newTestTx(10, first)
+		child.Inputs[0].SourceTransaction = nil
+
+		// when
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(child, nil))
+		require.NoError(t, builder.AddTransaction(first, firstBUMP))
+		built, err := builder.Build()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, first.TxID().String(), built.Transactions[0].GetTxID())
+		assert.Equal(t, child.TxID().String(), built.Transactions[1].GetTxID())
+		assert.True(t, built.Transactions[1].Unmined())
+	})
+
+	t.Run("BUMPs of the same block are merged", func(t *testing.T) {
+		// given
+		first, second := newTestTx(1), newTestTx(2)
+		firstBUMP, secondBUMP := newTestBlock(t, 800000, first, second)
+		child := newTestTx(10, first, second)
+		child.Inputs[0].SourceTransaction, child.Inputs[1].SourceTransaction = nil, nil
+
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(first, firstBUMP))
+		require.NoError(t, builder.AddTransaction(second, secondBUMP))
+		require.NoError(t, builder.AddTransaction(child, nil))
+
+		// when
+		built, err := builder.Build()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, built.BUMPs, 1)
+		assert.True(t, built.BUMPs[0].Path[0][0].TxId)
+		assert.True(t, built.BUMPs[0].Path[0][1].TxId)
+		assert.Len(t, built.BUMPs[0].Path[1], 1)
+		assert.Equal(t, uint64(0), uint64(*built.Transactions[0].BumpIndex))
+		assert.Equal(t, uint64(0), uint64(*built.Transactions[1].BumpIndex))
+
+		root, err := built.BUMPs[0].CalculateMerkleRoot()
+		require.NoError(t, err)
+		expectedRoot, err := firstBUMP.CalculateMerkleRoot()
+		require.NoError(t, err)
+		assert.Equal(t, expectedRoot, root)
+	})
+
+	t.Run("a BUMP can be added to a known transaction", func(t *testing.T) {
+		// given
+		first, second := newTestTx(1), newTestTx(2)
+		firstBUMP, _ := newTestBlock(t, 800000, first, second)
+		child := newTestTx(10, first)
+
+		// when
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(child, nil))
+		require.NoError(t, builder.AddTransaction(first, firstBUMP))
+		built, err := builder.Build()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, built.Transactions, 2)
+		assert.False(t, built.Transactions[0].Unmined())
+	})
+}
+
+func TestBuilder_HandlingErrors(t *testing.T) {
+	first, second := newTestTx(1), newTestTx(2)
+	firstBUMP, secondBUMP := newTestBlock(t, 800000, first, second)
+
+	t.Run("nil transaction", func(t *testing.T) {
+		require.ErrorIs(t, NewBuilder().AddTransaction(nil, nil), ErrBuilderNilTransaction)
+	})
+
+	t.Run("transaction not in the BUMP", func(t *testing.T) {
+		require.ErrorIs(t, NewBuilder().AddTransaction(second, firstBUMP), ErrBuilderTxNotInBump)
+	})
+
+	t.Run("different merkle roots for the same block", func(t *testing.T) {
+		other := newTestTx(5)
+		otherBUMP, _ := newTestBlock(t, 800000, other, second)
+
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(second, secondBUMP))
+		require.ErrorIs(t, builder.AddTransaction(other, otherBUMP), ErrBumpMerkleRootMismatch)
+	})
+
+	t.Run("no transactions", func(t *testing.T) {
+		_, err := NewBuilder().Build()
+		require.ErrorIs(t, err, ErrBeefNoTransactions)
+	})
+
+	t.Run("no BUMPs", func(t *testing.T) {
+		builder := NewBuilder()
+		require.NoError(t, builder.AddTransaction(newTestTx(10), nil))
+		_, err := builder.Build()
+		require.ErrorIs(t, err, ErrBeefNoLowestBump)
+	})
+}
+
+// toMerklePath will convert the BUMP into a go-sdk merkle path
+func toMerklePath(t *testing.T, bump *BUMP) *sdk.MerklePath {
+	buf := new(bytes.Buffer)
+	require.NoError(t, bump.write(buf))
+	merklePath, err := sdk.NewMerklePathFromBinary(buf.Bytes())
+	require.NoError(t, err)
+	return merklePath
+}
+
+func BenchmarkBuilder_Build(b *testing.B) {
+	decodedBEEF, _ := DecodeBEEF(testBEEFHex)
+	parent, child := decodedBEEF.Transactions[0].Transaction, decodedBEEF.Transactions[1].Transaction
+	child.Inputs[0].SourceTransaction = parent
+	for i := 0; i < b.N; i++ {
+		builder := NewBuilder()
+		_ = builder.AddTransaction(parent, decodedBEEF.BUMPs[0])
+		_ = builder.AddTransaction(child, nil)
+		_, _ = builder.Build()
+	}
+}
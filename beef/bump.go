@@ -83,7 +83,7 @@ func calculateMerkleRoot(baseLeaf BUMPLeaf, bump BUMP) (string, error) {
 			}
 
 			var err error
-			leafInPair, err = calculateFromChildren(newOffset, previousBLevel)
+			leafInPair, err = computeLeaf(bump, i, newOffset)
 			if err != nil {
 				return "", err
 			}
@@ -146,6 +146,27 @@ func calculateFromChildren(offset uint64, bumpLeaves []BUMPLeaf) (*BUMPLeaf, err
 	}, nil
 }
 
+// computeLeaf will compute the leaf at the level from its children (compact BUMPs omit the leaves
+// which can be computed from lower levels, so the children can be computed too)
+func computeLeaf(bump BUMP, level int, offset uint64) (*BUMPLeaf, error) {
+	if level == 0 {
+		return nil, ErrBumpChildNotFound
+	}
+
+	children := make([]BUMPLeaf, 0, 2)
+	for _, childOffset := range []uint64{offset * 2, offset*2 + 1} {
+		child := findLeafByOffset(childOffset, bump.Path[level-1])
+		if child == nil {
+			var err error
+			if child, err = computeLeaf(bump, level-1, childOffset); err != nil {
+				return nil, err
+			}
+		}
+		children = append(children, *child)
+	}
+	return calculateFromChildren(offset, children)
+}
+
 func prepareNodes(baseLeaf BUMPLeaf, offset uint64, leafInPair BUMPLeaf, newOffset uint64) (string, string) {
 	var baseLeafHash, pairLeafHash string
 
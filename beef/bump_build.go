@@ -0,0 +1,178 @@
+package beef
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrBumpNoTargets is returned when a BUMP is built without target transactions
+	ErrBumpNoTargets = errors.New("no target transactions for the BUMP")
+	// ErrBumpTargetNotFound is returned when a target transaction is not in the block
+	ErrBumpTargetNotFound = errors.New("target transaction not found in the block")
+	// ErrBumpTooFewTransactions is returned when the block has less than two transactions
+	ErrBumpTooFewTransactions = errors.New("a BUMP requires at least two transactions in the block")
+	// ErrBumpBlockHeightMismatch is returned when BUMPs of different blocks are merged
+	ErrBumpBlockHeightMismatch = errors.New("different block heights")
+)
+
+// NewBUMP will build a compact BUMP (BRC-74) proving the target transactions
+//
+// The txIDs are all the transaction ids of the block, in block order (the coinbase first).
+// Only the leaves required to compute the merkle root of the targets are included
+func NewBUMP(blockHeight uint64, txIDs []string, targets ...string) (*BUMP, error) {
+	if len(txIDs) < 2 {
+		return nil, ErrBumpTooFewTransactions
+	} else if len(targets) == 0 {
+		return nil, ErrBumpNoTargets
+	}
+
+	positions := make(map[string]uint64, len(txIDs))
+	for i, txID := range txIDs {
+		positions[txID] = uint64(i)
+	}
+	onPath := make(map[uint64]bool, len(targets))
+	for _, target := range targets {
+		offset, ok := positions[target]
+		if !ok {
+			return nil, fmt.Errorf("txid %s: %w", target, ErrBumpTargetNotFound)
+		}
+		onPath[offset] = true
+	}
+
+	bump := &BUMP{BlockHeight: blockHeight}
+	level := txIDs
+	for len(level) > 1 {
+		leaves := make([]BUMPLeaf, 0, 2*len(onPath))
+		for offset := range onPath {
+			// The targets are only at the lowest level (higher levels are computed)
+			if len(bump.Path) == 0 {
+				leaves = append(leaves, BUMPLeaf{Hash: level[offset], TxId: true, Offset: offset})
+			}
+
+			pair := getOffsetPair(offset)
+			switch {
+			case onPath[pair]:
+				continue
+			case pair >= uint64(len(level)):
+				leaves = append(leaves, BUMPLeaf{Duplicate: true, Offset: pair})
+			default:
+				leaves = append(leaves, BUMPLeaf{Hash: level[pair], Offset: pair})
+			}
+		}
+		sortLeaves(leaves)
+		bump.Path = append(bump.Path, leaves)
+
+		next, err := nextMerkleLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		level = next
+		onPath = parentOffsets(onPath)
+	}
+	return bump, nil
+}
+
+// MergeBUMPs will merge two BUMPs of the same block into one compact BUMP
+//
+// The txid leaves of both BUMPs are kept, and the leaves which can be computed from lower levels are removed
+func MergeBUMPs(a, b *BUMP) (*BUMP, error) {
+	if a.BlockHeight != b.BlockHeight {
+		return nil, fmt.Errorf("%d and %d: %w", a.BlockHeight, b.BlockHeight, ErrBumpBlockHeightMismatch)
+	} else if len(a.Path) != len(b.Path) {
+		return nil, ErrBumpMerkleRootMismatch
+	}
+	rootA, err := a.CalculateMerkleRoot()
+	if err != nil {
+		return nil, err
+	}
+	rootB, err := b.CalculateMerkleRoot()
+	if err != nil {
+		return nil, err
+	}
+	if rootA != rootB {
+		return nil, ErrBumpMerkleRootMismatch
+	}
+
+	merged := &BUMP{BlockHeight: a.BlockHeight, Path: make([][]BUMPLeaf, len(a.Path))}
+	for i := range a.Path {
+		leaves := make(map[uint64]BUMPLeaf, len(a.Path[i])+len(b.Path[i]))
+		for _, leaf := range append(append([]BUMPLeaf{}, a.Path[i]...), b.Path[i]...) {
+			if existing, ok := leaves[leaf.Offset]; ok {
+				leaf.TxId = leaf.TxId || existing.TxId
+			}
+			leaves[leaf.Offset] = leaf
+		}
+
+		level := make([]BUMPLeaf, 0, len(leaves))
+		for _, leaf := range leaves {
+			level = append(level, leaf)
+		}
+		sortLeaves(level)
+		merged.Path[i] = level
+	}
+	merged.compact()
+	return merged, nil
+}
+
+// compact will remove the leaves which are not required to compute the merkle root of the txid leaves
+//
+// A leaf is required if it is a txid leaf or the pair of a node on the path of a txid leaf
+// (and that pair is not on the path of another txid leaf)
+func (b *BUMP) compact() {
+	if len(b.Path) == 0 {
+		return
+	}
+
+	onPath := make(map[uint64]bool)
+	for _, leaf := range b.Path[0] {
+		if leaf.TxId {
+			onPath[leaf.Offset] = true
+		}
+	}
+
+	for i, level := range b.Path {
+		leaves := make([]BUMPLeaf, 0, len(level))
+		for _, leaf := range level {
+			if (i == 0 && leaf.TxId) || (!onPath[leaf.Offset] && onPath[getOffsetPair(leaf.Offset)]) {
+				leaves = append(leaves, leaf)
+			}
+		}
+		b.Path[i] = leaves
+		onPath = parentOffsets(onPath)
+	}
+}
+
+// nextMerkleLevel will return the parents of the nodes (the last node is duplicated if the count is odd)
+func nextMerkleLevel(level []string) ([]string, error) {
+	next := make([]string, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		right := level[i]
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		parent, err := merkleTreeParentStr(level[i], right)
+		if err != nil {
+			return nil, err
+		}
+		next = append(next, parent)
+	}
+	return next, nil
+}
+
+// parentOffsets will return the offsets of the parents of the nodes on the next level
+func parentOffsets(offsets map[uint64]bool) map[uint64]bool {
+	parents := make(map[uint64]bool, len(offsets))
+	for offset := range offsets {
+		parents[offset/2] = true
+	}
+	return parents
+}
+
+// sortLeaves will sort the leaves by offset (as required by BRC-74)
+func sortLeaves(leaves []BUMPLeaf) {
+	sort.Slice(leaves, func(x, y int) bool {
+		return leaves[x].Offset < leaves[y].Offset
+	})
+}
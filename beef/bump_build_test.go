@@ -0,0 +1,208 @@
+package beef
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBlockTxIDs will return the txids of a block with the number of transactions
+func newTestBlockTxIDs(count int) []string {
+	txIDs := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("tx-%d", i)))
+		txIDs = append(txIDs, hex.EncodeToString(hash[:]))
+	}
+	return txIDs
+}
+
+// testMerkleRoot will return the merkle root of the txids (computed level by level)
+func testMerkleRoot(t *testing.T, txIDs []string) string {
+	level := txIDs
+	for len(level) > 1 {
+		var err error
+		level, err = nextMerkleLevel(level)
+		require.NoError(t, err)
+	}
+	return level[0]
+}
+
+func TestNewBUMP(t *testing.T) {
+	t.Parallel()
+
+	for _, count := range []int{2, 3, 4, 5, 7, 8, 13, 16, 17} {
+		t.Run(fmt.Sprintf("block of %d transactions", count), func(t *testing.T) {
+			// given
+			txIDs := newTestBlockTxIDs(count)
+			expectedRoot := testMerkleRoot(t, txIDs)
+
+			for i, txID := range txIDs {
+				// when
+				bump, err := NewBUMP(800000, txIDs, txID)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, uint64(800000), bump.BlockHeight)
+				assert.True(t, bump.containsTx(txID))
+				root, err := bump.CalculateMerkleRoot()
+				require.NoError(t, err)
+				assert.Equal(t, expectedRoot, root, "target %d", i)
+				assert.Len(t, bump.Path[0], 2)
+				for _, level := range bump.Path[1:] {
+					assert.Len(t, level, 1)
+				}
+			}
+		})
+	}
+
+	t.Run("multiple targets share the path", func(t *testing.T) {
+		// given
+		txIDs := newTestBlockTxIDs(8)
+
+		// when
+		bump, err := NewBUMP(800000, txIDs, txIDs[0], txIDs[1], txIDs[4])
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, [][]BUMPLeaf{
+			{
+				{Hash: txIDs[0], TxId: true, Offset: 0},
+				{Hash: txIDs[1], TxId: true, Offset: 1},
+				{Hash: txIDs[4], TxId: true, Offset: 4},
+				{Hash: txIDs[5], Offset: 5},
+			},
+			{{Hash: mustParent(t, txIDs[2], txIDs[3]), Offset: 1}, {Hash: mustParent(t, txIDs[6], txIDs[7]), Offset: 3}},
+			{},
+		}, bump.Path)
+		root, err := bump.CalculateMerkleRoot()
+		require.NoError(t, err)
+		assert.Equal(t, testMerkleRoot(t, txIDs), root)
+	})
+
+	t.Run("the last transaction of an odd level is duplicated", func(t *testing.T) {
+		// given
+		txIDs := newTestBlockTxIDs(5)
+
+		// when
+		bump, err := NewBUMP(800000, txIDs, txIDs[4])
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, BUMPLeaf{Duplicate: true, Offset: 5}, bump.Path[0][1])
+		assert.Equal(t, BUMPLeaf{Duplicate: true, Offset: 3}, bump.Path[1][0])
+	})
+}
+
+func TestNewBUMP_HandlingErrors(t *testing.T) {
+	t.Parallel()
+
+	txIDs := newTestBlockTxIDs(4)
+	testCases := map[string]struct {
+		txIDs         []string
+		targets       []string
+		expectedError error
+	}{
+		"no transactions": {
+			expectedError: ErrBumpTooFewTransactions,
+		},
+		"only the coinbase": {
+			txIDs:         txIDs[:1],
+			targets:       txIDs[:1],
+			expectedError: ErrBumpTooFewTransactions,
+		},
+		"no targets": {
+			txIDs:         txIDs,
+			expectedError: ErrBumpNoTargets,
+		},
+		"target not in the block": {
+			txIDs:         txIDs[:2],
+			targets:       txIDs[3:],
+			expectedError: ErrBumpTargetNotFound,
+		},
+		"invalid txid": {
+			txIDs:         []string{"zz", txIDs[0]},
+			targets:       txIDs[:1],
+			expectedError: hex.InvalidByteError('z'),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bump, err := NewBUMP(800000, tc.txIDs, tc.targets...)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, bump)
+		})
+	}
+}
+
+func TestMergeBUMPs(t *testing.T) {
+	t.Parallel()
+
+	for _, count := range []int{2, 5, 8, 13, 16} {
+		t.Run(fmt.Sprintf("block of %d transactions", count), func(t *testing.T) {
+			txIDs := newTestBlockTxIDs(count)
+			for i := range txIDs {
+				for j := range txIDs {
+					// given
+					a, err := NewBUMP(800000, txIDs, txIDs[i])
+					require.NoError(t, err)
+					b, err := NewBUMP(800000, txIDs, txIDs[j])
+					require.NoError(t, err)
+
+					// when
+					merged, err := MergeBUMPs(a, b)
+
+					// then the merged BUMP is as compact as a BUMP built with both targets
+					require.NoError(t, err)
+					expected, err := NewBUMP(800000, txIDs, txIDs[i], txIDs[j])
+					require.NoError(t, err)
+					assert.Equal(t, expected, merged, "targets %d and %d", i, j)
+
+					root, err := merged.CalculateMerkleRoot()
+					require.NoError(t, err)
+					assert.Equal(t, testMerkleRoot(t, txIDs), root)
+				}
+			}
+		})
+	}
+
+	t.Run("different block heights", func(t *testing.T) {
+		txIDs := newTestBlockTxIDs(4)
+		a, _ := NewBUMP(800000, txIDs, txIDs[0])
+		b, _ := NewBUMP(800001, txIDs, txIDs[1])
+
+		_, err := MergeBUMPs(a, b)
+		require.ErrorIs(t, err, ErrBumpBlockHeightMismatch)
+	})
+
+	t.Run("different merkle roots", func(t *testing.T) {
+		txIDs, otherTxIDs := newTestBlockTxIDs(4), newTestBlockTxIDs(3)
+		a, _ := NewBUMP(800000, txIDs, txIDs[0])
+		b, _ := NewBUMP(800000, otherTxIDs, otherTxIDs[1])
+
+		_, err := MergeBUMPs(a, b)
+		require.ErrorIs(t, err, ErrBumpMerkleRootMismatch)
+
+		c, _ := NewBUMP(800000, newTestBlockTxIDs(5), txIDs[0])
+		_, err = MergeBUMPs(a, c)
+		require.ErrorIs(t, err, ErrBumpMerkleRootMismatch)
+	})
+}
+
+// mustParent will return the merkle tree parent of the nodes
+func mustParent(t *testing.T, left, right string) string {
+	parent, err := merkleTreeParentStr(left, right)
+	require.NoError(t, err)
+	return parent
+}
+
+func BenchmarkNewBUMP(b *testing.B) {
+	txIDs := newTestBlockTxIDs(10000)
+	for i := 0; i < b.N; i++ {
+		_, _ = NewBUMP(800000, txIDs, txIDs[i%len(txIDs)])
+	}
+}
@@ -0,0 +1,80 @@
+package beef
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	util "github.com/bsv-blockchain/go-sdk/util"
+)
+
+// ErrBumpTrailingBytes is returned when there are bytes left after the decoded BUMP
+var ErrBumpTrailingBytes = errors.New("invalid BUMP - unexpected bytes after the BUMP")
+
+// DecodeBUMP will decode the BUMP (BRC-74) from its hex string
+func DecodeBUMP(bumpHex string) (*BUMP, error) {
+	bumpBytes, err := hex.DecodeString(bumpHex)
+	if err != nil {
+		return nil, err
+	}
+
+	bump, remainingBytes, err := decodeBUMP(bumpBytes)
+	if err != nil {
+		return nil, err
+	} else if len(remainingBytes) > 0 {
+		return nil, fmt.Errorf("%d bytes: %w", len(remainingBytes), ErrBumpTrailingBytes)
+	}
+	return bump, nil
+}
+
+// Bytes will encode the BUMP in the BRC-74 binary format
+func (b *BUMP) Bytes() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := b.write(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Hex will encode the BUMP in the BRC-74 binary format as a hex string
+func (b *BUMP) Hex() (string, error) {
+	bumpBytes, err := b.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bumpBytes), nil
+}
+
+// write will write the BUMP in the BRC-74 binary format
+func (b *BUMP) write(buf *bytes.Buffer) error {
+	if len(b.Path) > maxTreeHeight {
+		return fmt.Errorf("treeHeight: %d: %w", len(b.Path), ErrBeefInvalidTreeHeight)
+	}
+
+	buf.Write(util.VarInt(b.BlockHeight).Bytes())
+	buf.WriteByte(byte(len(b.Path)))
+	for _, level := range b.Path {
+		buf.Write(util.VarInt(len(level)).Bytes())
+		for _, leaf := range level {
+			buf.Write(util.VarInt(leaf.Offset).Bytes())
+
+			switch {
+			case leaf.Duplicate:
+				buf.WriteByte(duplicateFlag)
+				continue
+			case leaf.TxId:
+				buf.WriteByte(txIDFlag)
+			default:
+				buf.WriteByte(dataFlag)
+			}
+
+			hash, err := hex.DecodeString(leaf.Hash)
+			if err != nil || len(hash) != hashBytesCount {
+				return fmt.Errorf("offset %d: %w", leaf.Offset, ErrBeefInvalidLeafHash)
+			}
+			buf.Write(util.ReverseBytes(hash))
+		}
+	}
+	return nil
+}
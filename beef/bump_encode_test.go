@@ -0,0 +1,97 @@
+package beef
+
+import (
+	"testing"
+
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBUMP_Hex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip of a decoded BEEF BUMP", func(t *testing.T) {
+		// given
+		decodedBEEF, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		bump := decodedBEEF.BUMPs[0]
+
+		// when
+		bumpHex, err := bump.Hex()
+		require.NoError(t, err)
+		decoded, err := DecodeBUMP(bumpHex)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, bump, decoded)
+		assert.Contains(t, testBEEFHex, bumpHex)
+	})
+
+	t.Run("same encoding as the go-sdk", func(t *testing.T) {
+		// given
+		txIDs := newTestBlockTxIDs(13)
+		bump, err := NewBUMP(800000, txIDs, txIDs[2], txIDs[7], txIDs[12])
+		require.NoError(t, err)
+
+		// when
+		bumpHex, err := bump.Hex()
+		require.NoError(t, err)
+
+		// then
+		merklePath, err := sdk.NewMerklePathFromHex(bumpHex)
+		require.NoError(t, err)
+		assert.Equal(t, bumpHex, merklePath.Hex())
+		root, err := merklePath.ComputeRootHex(&txIDs[7])
+		require.NoError(t, err)
+		assert.Equal(t, testMerkleRoot(t, txIDs), root)
+	})
+}
+
+func TestDecodeBUMP_HandlingErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		bumpHex       string
+		expectedError error
+	}{
+		"empty": {
+			bumpHex:       "",
+			expectedError: ErrBeefInsufficientBytesBlockHeight,
+		},
+		"no tree height": {
+			bumpHex:       "fe8a6a0c00",
+			expectedError: ErrBeefNoBytesForPaths,
+		},
+		"missing levels": {
+			bumpHex:       "fe8a6a0c0002",
+			expectedError: ErrBeefNoBytesForPaths,
+		},
+		"trailing bytes": {
+			bumpHex:       "fe8a6a0c00010101010000",
+			expectedError: ErrBumpTrailingBytes,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bump, err := DecodeBUMP(tc.bumpHex)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, bump)
+		})
+	}
+
+	t.Run("invalid hex", func(t *testing.T) {
+		_, err := DecodeBUMP("zz")
+		require.Error(t, err)
+	})
+}
+
+func BenchmarkBUMP_Hex(b *testing.B) {
+	txIDs := newTestBlockTxIDs(1000)
+	bump, _ := NewBUMP(800000, txIDs, txIDs[10], txIDs[500])
+	for i := 0; i < b.N; i++ {
+		bumpHex, _ := bump.Hex()
+		_, _ = DecodeBUMP(bumpHex)
+	}
+}
@@ -0,0 +1,184 @@
+package beef
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	util "github.com/bsv-blockchain/go-sdk/util"
+)
+
+// TSC proof target types and proof types (BRC-10)
+const (
+	TSCTargetTypeHash       = "hash"       // The target is the block hash (the default)
+	TSCTargetTypeHeader     = "header"     // The target is the block header (80 bytes)
+	TSCTargetTypeMerkleRoot = "merkleRoot" // The target is the merkle root
+	TSCProofTypeBranch      = "branch"     // The proof is a merkle branch (the default)
+	TSCProofTypeTree        = "tree"       // The proof is a merkle tree (not supported)
+
+	tscDuplicateNode = "*"
+	blockHeaderBytes = 80
+)
+
+var (
+	// ErrTSCUnsupportedProof is returned when the TSC proof is composite or a merkle tree
+	ErrTSCUnsupportedProof = errors.New("unsupported TSC proof - only non composite branches are supported")
+	// ErrTSCInvalidIndex is returned when the index of the TSC proof is beyond the nodes of the proof
+	ErrTSCInvalidIndex = errors.New("invalid TSC proof - index out of range for the nodes")
+	// ErrTSCInvalidTarget is returned when the target of the TSC proof cannot be decoded
+	ErrTSCInvalidTarget = errors.New("invalid TSC proof target")
+)
+
+// TSCProof is the merkle proof in the TSC JSON format (BRC-10)
+type TSCProof struct {
+	Composite  bool     `json:"composite,omitempty"`
+	Index      uint64   `json:"index"`
+	Nodes      []string `json:"nodes"`
+	ProofType  string   `json:"proofType,omitempty"`
+	Target     string   `json:"target"`
+	TargetType string   `json:"targetType,omitempty"`
+	TxOrID     string   `json:"txOrId"`
+}
+
+// NewBUMPFromTSC will convert the TSC merkle proof (BRC-10) of a transaction mined in the block into a BUMP
+//
+// The block height is required, as TSC proofs do not contain it. If the target is the merkle root
+// or the block header, the merkle root of the proof is verified (block hashes cannot be verified)
+func NewBUMPFromTSC(blockHeight uint64, proof *TSCProof) (*BUMP, error) {
+	if proof.Composite || (len(proof.ProofType) > 0 && proof.ProofType != TSCProofTypeBranch) {
+		return nil, ErrTSCUnsupportedProof
+	} else if len(proof.Nodes) == 0 {
+		return nil, ErrBumpTooFewTransactions
+	} else if len(proof.Nodes) > maxTreeHeight || proof.Index>>len(proof.Nodes) != 0 {
+		return nil, fmt.Errorf("index %d, %d nodes: %w", proof.Index, len(proof.Nodes), ErrTSCInvalidIndex)
+	}
+
+	txID, err := tscTxID(proof.TxOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	bump := &BUMP{BlockHeight: blockHeight, Path: make([][]BUMPLeaf, len(proof.Nodes))}
+	offset := proof.Index
+	for i, node := range proof.Nodes {
+		leaf := BUMPLeaf{Offset: getOffsetPair(offset)}
+		if node == tscDuplicateNode {
+			leaf.Duplicate = true
+		} else if err = validateHash(node); err != nil {
+			return nil, fmt.Errorf("node %d: %w", i, err)
+		} else {
+			leaf.Hash = node
+		}
+
+		bump.Path[i] = []BUMPLeaf{leaf}
+		offset /= 2
+	}
+	bump.Path[0] = append(bump.Path[0], BUMPLeaf{Hash: txID, TxId: true, Offset: proof.Index})
+	sortLeaves(bump.Path[0])
+
+	merkleRoot, err := bump.CalculateMerkleRoot()
+	if err != nil {
+		return nil, err
+	}
+	expectedRoot, err := tscMerkleRoot(proof)
+	if err != nil {
+		return nil, err
+	} else if len(expectedRoot) > 0 && expectedRoot != merkleRoot {
+		return nil, fmt.Errorf("merkle root %s, target %s: %w", merkleRoot, expectedRoot, ErrBumpMerkleRootMismatch)
+	}
+	return bump, nil
+}
+
+// TSCProof will return the TSC merkle proof (BRC-10) of the transaction, with the merkle root as the target
+func (b *BUMP) TSCProof(txID string) (*TSCProof, error) {
+	if len(b.Path) == 0 {
+		return nil, fmt.Errorf("txid %s: %w", txID, ErrBumpTargetNotFound)
+	}
+	var txLeaf *BUMPLeaf
+	for _, leaf := range b.Path[0] {
+		if leaf.TxId && leaf.Hash == txID {
+			txLeaf = &leaf
+			break
+		}
+	}
+	if txLeaf == nil {
+		return nil, fmt.Errorf("txid %s: %w", txID, ErrBumpTargetNotFound)
+	}
+
+	merkleRoot, err := calculateMerkleRoot(*txLeaf, *b)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &TSCProof{
+		Index:      txLeaf.Offset,
+		Nodes:      make([]string, 0, len(b.Path)),
+		ProofType:  TSCProofTypeBranch,
+		Target:     merkleRoot,
+		TargetType: TSCTargetTypeMerkleRoot,
+		TxOrID:     txID,
+	}
+	offset := txLeaf.Offset
+	for i := range b.Path {
+		pair := getOffsetPair(offset)
+		leaf := findLeafByOffset(pair, b.Path[i])
+		if leaf == nil {
+			if leaf, err = computeLeaf(*b, i, pair); err != nil {
+				return nil, err
+			}
+		}
+
+		if leaf.Duplicate {
+			proof.Nodes = append(proof.Nodes, tscDuplicateNode)
+		} else {
+			proof.Nodes = append(proof.Nodes, leaf.Hash)
+		}
+		offset /= 2
+	}
+	return proof, nil
+}
+
+// tscTxID will return the txid of the TSC proof (the txOrId field is a txid or a raw transaction)
+func tscTxID(txOrID string) (string, error) {
+	if len(txOrID) == 2*hashBytesCount {
+		return txOrID, validateHash(txOrID)
+	}
+
+	tx, err := sdk.NewTransactionFromHex(txOrID)
+	if err != nil {
+		return "", err
+	}
+	return tx.TxID().String(), nil
+}
+
+// tscMerkleRoot will return the merkle root of the target (empty if the target is a block hash)
+func tscMerkleRoot(proof *TSCProof) (string, error) {
+	switch proof.TargetType {
+	case TSCTargetTypeMerkleRoot:
+		if err := validateHash(proof.Target); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrTSCInvalidTarget, err)
+		}
+		return proof.Target, nil
+	case TSCTargetTypeHeader:
+		header, err := hex.DecodeString(proof.Target)
+		if err != nil || len(header) != blockHeaderBytes {
+			return "", fmt.Errorf("header %s: %w", proof.Target, ErrTSCInvalidTarget)
+		}
+		// The merkle root follows the version (4 bytes) and the previous block hash (32 bytes)
+		return hex.EncodeToString(util.ReverseBytes(header[36:68])), nil
+	case "", TSCTargetTypeHash:
+		return "", nil
+	default:
+		return "", fmt.Errorf("target type %s: %w", proof.TargetType, ErrTSCInvalidTarget)
+	}
+}
+
+// validateHash will return an error if the hash is not a valid 32 byte hex string
+func validateHash(hash string) error {
+	hashBytes, err := hex.DecodeString(hash)
+	if err != nil || len(hashBytes) != hashBytesCount {
+		return fmt.Errorf("hash %s: %w", hash, ErrBeefInvalidLeafHash)
+	}
+	return nil
+}
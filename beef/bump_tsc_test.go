@@ -0,0 +1,176 @@
+package beef
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	util "github.com/bsv-blockchain/go-sdk/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBlockHeader will return a block header (hex) with the merkle root
+func newTestBlockHeader(t *testing.T, merkleRoot string) string {
+	root, err := hex.DecodeString(merkleRoot)
+	require.NoError(t, err)
+	header := make([]byte, blockHeaderBytes)
+	copy(header[36:68], util.ReverseBytes(root))
+	return hex.EncodeToString(header)
+}
+
+func TestNewBUMPFromTSC(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip of a TSC proof", func(t *testing.T) {
+		txIDs := newTestBlockTxIDs(13)
+		for i, txID := range txIDs {
+			// given
+			bump, err := NewBUMP(800000, txIDs, txID)
+			require.NoError(t, err)
+
+			// when
+			proof, err := bump.TSCProof(txID)
+			require.NoError(t, err)
+			converted, err := NewBUMPFromTSC(800000, proof)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, bump, converted, "target %d", i)
+			assert.Equal(t, uint64(i), proof.Index) //nolint:gosec // G115: test index
+			assert.Equal(t, testMerkleRoot(t, txIDs), proof.Target)
+		}
+	})
+
+	t.Run("TSC proof JSON", func(t *testing.T) {
+		// given
+		txIDs := newTestBlockTxIDs(3)
+		proofJSON := `{
+			"index": 2,
+			"txOrId": "` + txIDs[2] + `",
+			"targetType": "header",
+			"target": "` + newTestBlockHeader(t, testMerkleRoot(t, txIDs)) + `",
+			"nodes": ["*", "` + mustParent(t, txIDs[0], txIDs[1]) + `"]
+		}`
+		var proof *TSCProof
+		require.NoError(t, json.Unmarshal([]byte(proofJSON), &proof))
+
+		// when
+		bump, err := NewBUMPFromTSC(800000, proof)
+
+		// then
+		require.NoError(t, err)
+		expected, err := NewBUMP(800000, txIDs, txIDs[2])
+		require.NoError(t, err)
+		assert.Equal(t, expected, bump)
+	})
+
+	t.Run("raw transaction and block hash target", func(t *testing.T) {
+		// given
+		decodedBEEF, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		tx := decodedBEEF.Transactions[0].Transaction
+		proof, err := decodedBEEF.BUMPs[0].TSCProof(tx.TxID().String())
+		require.NoError(t, err)
+		proof.TxOrID = tx.Hex()
+		proof.TargetType = TSCTargetTypeHash
+		proof.Target = strings.Repeat("00", 32)
+
+		// when
+		bump, err := NewBUMPFromTSC(decodedBEEF.BUMPs[0].BlockHeight, proof)
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, bump.containsTx(tx.TxID().String()))
+		root, err := bump.CalculateMerkleRoot()
+		require.NoError(t, err)
+		expectedRoot, err := decodedBEEF.BUMPs[0].CalculateMerkleRoot()
+		require.NoError(t, err)
+		assert.Equal(t, expectedRoot, root)
+	})
+}
+
+func TestNewBUMPFromTSC_HandlingErrors(t *testing.T) {
+	t.Parallel()
+
+	txIDs := newTestBlockTxIDs(4)
+	validNodes := []string{txIDs[1], mustParent(t, txIDs[2], txIDs[3])}
+	testCases := map[string]struct {
+		proof         *TSCProof
+		expectedError error
+	}{
+		"composite proof": {
+			proof:         &TSCProof{Composite: true, TxOrID: txIDs[0], Nodes: validNodes},
+			expectedError: ErrTSCUnsupportedProof,
+		},
+		"tree proof": {
+			proof:         &TSCProof{ProofType: TSCProofTypeTree, TxOrID: txIDs[0], Nodes: validNodes},
+			expectedError: ErrTSCUnsupportedProof,
+		},
+		"no nodes": {
+			proof:         &TSCProof{TxOrID: txIDs[0]},
+			expectedError: ErrBumpTooFewTransactions,
+		},
+		"index out of range": {
+			proof:         &TSCProof{Index: 4, TxOrID: txIDs[0], Nodes: validNodes},
+			expectedError: ErrTSCInvalidIndex,
+		},
+		"invalid node": {
+			proof:         &TSCProof{TxOrID: txIDs[0], Nodes: []string{"abcd", validNodes[1]}},
+			expectedError: ErrBeefInvalidLeafHash,
+		},
+		"invalid merkle root target": {
+			proof:         &TSCProof{TxOrID: txIDs[0], Nodes: validNodes, Target: "abcd", TargetType: TSCTargetTypeMerkleRoot},
+			expectedError: ErrTSCInvalidTarget,
+		},
+		"invalid header target": {
+			proof:         &TSCProof{TxOrID: txIDs[0], Nodes: validNodes, Target: "abcd", TargetType: TSCTargetTypeHeader},
+			expectedError: ErrTSCInvalidTarget,
+		},
+		"unknown target type": {
+			proof:         &TSCProof{TxOrID: txIDs[0], Nodes: validNodes, TargetType: "height"},
+			expectedError: ErrTSCInvalidTarget,
+		},
+		"different merkle root": {
+			proof:         &TSCProof{TxOrID: txIDs[0], Nodes: validNodes, Target: txIDs[3], TargetType: TSCTargetTypeMerkleRoot},
+			expectedError: ErrBumpMerkleRootMismatch,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bump, err := NewBUMPFromTSC(800000, tc.proof)
+			require.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, bump)
+		})
+	}
+
+	t.Run("invalid raw transaction", func(t *testing.T) {
+		_, err := NewBUMPFromTSC(800000, &TSCProof{TxOrID: "0100", Nodes: validNodes})
+		require.Error(t, err)
+	})
+}
+
+func TestBUMP_TSCProof_HandlingErrors(t *testing.T) {
+	t.Parallel()
+
+	txIDs := newTestBlockTxIDs(4)
+	bump, err := NewBUMP(800000, txIDs, txIDs[0])
+	require.NoError(t, err)
+
+	_, err = bump.TSCProof(txIDs[1])
+	require.ErrorIs(t, err, ErrBumpTargetNotFound)
+
+	_, err = (&BUMP{}).TSCProof(txIDs[0])
+	require.ErrorIs(t, err, ErrBumpTargetNotFound)
+}
+
+func BenchmarkNewBUMPFromTSC(b *testing.B) {
+	txIDs := newTestBlockTxIDs(1000)
+	bump, _ := NewBUMP(800000, txIDs, txIDs[10])
+	proof, _ := bump.TSCProof(txIDs[10])
+	for i := 0; i < b.N; i++ {
+		_, _ = NewBUMPFromTSC(800000, proof)
+	}
+}
@@ -0,0 +1,526 @@
+package beef
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	util "github.com/bsv-blockchain/go-sdk/util"
+)
+
+// Default resource limits of the BEEF decoder
+const (
+	DefaultMaxBUMPs        = 1000     // Maximum number of BUMPs (blocks)
+	DefaultMaxLeaves       = 1 << 20  // Maximum number of leaves of all the BUMPs
+	DefaultMaxSize         = 32 << 20 // Maximum size of the binary BEEF (32 MiB)
+	DefaultMaxTransactions = 10000    // Maximum number of transactions
+	DefaultMaxTreeHeight   = maxTreeHeight
+)
+
+// Sizes of the transaction fields (used to read raw transactions)
+const (
+	txVersionBytes  = 4
+	txLockTimeBytes = 4
+	outpointBytes   = 36
+	sequenceBytes   = 4
+	satoshisBytes   = 8
+)
+
+var (
+	// ErrBeefTooLarge is returned when the BEEF is larger than the size limit
+	ErrBeefTooLarge = errors.New("invalid BEEF - size limit exceeded")
+	// ErrBeefTooManyBUMPs is returned when the BEEF has more BUMPs than the limit
+	ErrBeefTooManyBUMPs = errors.New("invalid BEEF - BUMP limit exceeded")
+	// ErrBeefTooManyLeaves is returned when the BUMPs of the BEEF have more leaves than the limit
+	ErrBeefTooManyLeaves = errors.New("invalid BEEF - BUMP leaf limit exceeded")
+	// ErrBeefTooManyTransactions is returned when the BEEF has more transactions than the limit
+	ErrBeefTooManyTransactions = errors.New("invalid BEEF - transaction limit exceeded")
+	// ErrBeefTrailingBytes is returned when there are bytes after the last transaction of the BEEF
+	ErrBeefTrailingBytes = errors.New("invalid BEEF - unexpected bytes after the last transaction")
+)
+
+// Limits are the resource limits of the BEEF decoder (zero values use the defaults)
+type Limits struct {
+	MaxBUMPs        uint64 // Maximum number of BUMPs
+	MaxLeaves       uint64 // Maximum number of leaves of all the BUMPs
+	MaxSize         int64  // Maximum size of the binary BEEF in bytes (a hex BEEF can be twice as long)
+	MaxTransactions uint64 // Maximum number of transactions (including TXID-only entries)
+	MaxTreeHeight   int    // Maximum tree height of a BUMP (cannot be more than 64)
+}
+
+// DefaultLimits will return the default resource limits of the BEEF decoder
+func DefaultLimits() Limits {
+	return Limits{
+		MaxBUMPs:        DefaultMaxBUMPs,
+		MaxLeaves:       DefaultMaxLeaves,
+		MaxSize:         DefaultMaxSize,
+		MaxTransactions: DefaultMaxTransactions,
+		MaxTreeHeight:   DefaultMaxTreeHeight,
+	}
+}
+
+// withDefaults will return the limits with the defaults for the zero values
+func (l Limits) withDefaults() Limits {
+	defaults := DefaultLimits()
+	if l.MaxBUMPs == 0 {
+		l.MaxBUMPs = defaults.MaxBUMPs
+	}
+	if l.MaxLeaves == 0 {
+		l.MaxLeaves = defaults.MaxLeaves
+	}
+	if l.MaxSize <= 0 {
+		l.MaxSize = defaults.MaxSize
+	}
+	if l.MaxTransactions == 0 {
+		l.MaxTransactions = defaults.MaxTransactions
+	}
+	if l.MaxTreeHeight <= 0 || l.MaxTreeHeight > maxTreeHeight {
+		l.MaxTreeHeight = defaults.MaxTreeHeight
+	}
+	return l
+}
+
+// DecodeError is the error of the BEEF decoder with the byte offset of the failure
+//
+// The offset is in the binary BEEF (for a hex BEEF, the offset in the hex string is twice the offset)
+type DecodeError struct {
+	Err    error
+	Offset int64
+}
+
+// Error will return the error message with the offset
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cannot decode BEEF at byte %d: %s", e.Offset, e.Err.Error())
+}
+
+// Unwrap will return the underlying error (use errors.Is with the ErrBeef errors)
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder will decode a BEEF (V1, V2 or Atomic BEEF) from a stream, in binary or hex format
+//
+// The size, the number of BUMPs, leaves and transactions and the tree heights are limited, and nothing
+// is allocated before the bytes are read (a declared length cannot exceed the remaining size). The stream
+// must end with the last transaction of the BEEF
+type Decoder struct {
+	leaves uint64
+	limits Limits
+	offset int64
+	reader *bufio.Reader
+}
+
+// NewDecoder will return a BEEF decoder reading from r with the limits (zero values use the defaults)
+func NewDecoder(r io.Reader, limits Limits) *Decoder {
+	return &Decoder{
+		limits: limits.withDefaults(),
+		reader: bufio.NewReader(r),
+	}
+}
+
+// Decode will decode the BEEF, the errors are a *DecodeError with the offset of the failure
+func (d *Decoder) Decode() (*DecodedBEEF, error) {
+	// Hex BEEF starts with an ASCII zero (binary BEEF starts with 0x01 or 0x02)
+	first, err := d.reader.Peek(1)
+	if err != nil {
+		return nil, d.fail(ErrBeefInvalidHexStream)
+	} else if first[0] == '0' {
+		d.reader = bufio.NewReader(hex.NewDecoder(d.reader))
+	}
+	return d.decode()
+}
+
+// decode will decode the binary BEEF of the reader
+func (d *Decoder) decode() (*DecodedBEEF, error) {
+	header, err := d.readBytes(atomicBytesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// the Atomic BEEF prefix and the subject txid precede the version
+	var subjectTxID string
+	if isAtomicPrefix(header) {
+		var subject []byte
+		if subject, err = d.readBytes(hashBytesCount); err != nil {
+			return nil, err
+		}
+		subjectTxID = hex.EncodeToString(util.ReverseBytes(subject))
+		if header, err = d.readBytes(versionBytesCount + markerBytesCount); err != nil {
+			return nil, err
+		}
+	}
+
+	format, err := parseVersion(header)
+	if err != nil {
+		return nil, d.failAt(d.offset-int64(len(header)), err)
+	}
+	if err = validateMarker(header[versionBytesCount:]); err != nil {
+		return nil, d.failAt(d.offset-markerBytesCount, err)
+	}
+
+	bumps, err := d.decodeBUMPs(format)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := d.decodeTransactions(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = d.readEnd(); err != nil {
+		return nil, err
+	}
+
+	if len(bumps) == 0 && !hasTxIDOnly(transactions) {
+		return nil, d.fail(ErrBeefNoLowestBump)
+	}
+
+	decodedBEEF := &DecodedBEEF{
+		BUMPs:        bumps,
+		Format:       format,
+		SubjectTxID:  subjectTxID,
+		Transactions: transactions,
+		index:        new(txIndex),
+	}
+	if decodedBEEF.IsAtomic() && decodedBEEF.findTx(subjectTxID) == nil {
+		return nil, d.fail(fmt.Errorf("subject %s: %w", subjectTxID, ErrBeefSubjectTxNotFound))
+	}
+	return decodedBEEF, nil
+}
+
+// decodeBUMPs will decode the BUMPs (BEEF V2 can have no BUMPs if the ancestors are TXID-only)
+func (d *Decoder) decodeBUMPs(format BEEFFormat) (BUMPs, error) {
+	start := d.offset
+	nBUMPs, err := d.readVarInt()
+	if err != nil {
+		return nil, err
+	} else if nBUMPs == 0 && format != BEEFV2 {
+		return nil, d.failAt(start, ErrBeefNoLowestBump)
+	} else if nBUMPs > d.limits.MaxBUMPs {
+		return nil, d.failAt(start, fmt.Errorf("%d BUMPs: %w", nBUMPs, ErrBeefTooManyBUMPs))
+	}
+
+	bumps := make(BUMPs, 0, nBUMPs)
+	for i := uint64(0); i < nBUMPs; i++ {
+		bump, err := d.decodeBUMP()
+		if err != nil {
+			return nil, err
+		}
+		bumps = append(bumps, bump)
+	}
+	return bumps, nil
+}
+
+// decodeBUMP will decode a BUMP (BRC-74)
+func (d *Decoder) decodeBUMP() (*BUMP, error) {
+	blockHeight, err := d.readVarInt()
+	if err != nil {
+		return nil, err
+	}
+
+	start := d.offset
+	treeHeight, err := d.readByte()
+	if err != nil {
+		return nil, err
+	} else if int(treeHeight) > d.limits.MaxTreeHeight {
+		return nil, d.failAt(start, fmt.Errorf("treeHeight: %d: %w", treeHeight, ErrBeefInvalidTreeHeight))
+	}
+
+	bump := &BUMP{BlockHeight: blockHeight, Path: make([][]BUMPLeaf, 0, treeHeight)}
+	for i := 0; i < int(treeHeight); i++ {
+		start = d.offset
+		nLeaves, err := d.readVarInt()
+		if err != nil {
+			return nil, err
+		}
+		if d.leaves += nLeaves; nLeaves > d.limits.MaxLeaves || d.leaves > d.limits.MaxLeaves {
+			return nil, d.failAt(start, fmt.Errorf("%d leaves: %w", nLeaves, ErrBeefTooManyLeaves))
+		}
+
+		// a leaf is at least two bytes (offset and duplicate flag)
+		level := make([]BUMPLeaf, 0, min(nLeaves, d.remaining()/2))
+		for j := uint64(0); j < nLeaves; j++ {
+			leaf, err := d.decodeBUMPLeaf()
+			if err != nil {
+				return nil, err
+			}
+			level = append(level, leaf)
+		}
+		bump.Path = append(bump.Path, level)
+	}
+	return bump, nil
+}
+
+// decodeBUMPLeaf will decode a leaf (offset, flag and hash) of a BUMP level
+func (d *Decoder) decodeBUMPLeaf() (BUMPLeaf, error) {
+	offset, err := d.readVarInt()
+	if err != nil {
+		return BUMPLeaf{}, err
+	}
+
+	start := d.offset
+	flag, err := d.readByte()
+	if err != nil {
+		return BUMPLeaf{}, err
+	}
+
+	switch flag {
+	case duplicateFlag:
+		return BUMPLeaf{Offset: offset, Duplicate: true}, nil
+	case dataFlag, txIDFlag:
+		hash, err := d.readBytes(hashBytesCount)
+		if err != nil {
+			return BUMPLeaf{}, err
+		}
+		return BUMPLeaf{
+			Hash:   hex.EncodeToString(util.ReverseBytes(hash)),
+			Offset: offset,
+			TxId:   flag == txIDFlag,
+		}, nil
+	default:
+		return BUMPLeaf{}, d.failAt(start, fmt.Errorf("flag %d: %w", flag, ErrBeefInvalidFlag))
+	}
+}
+
+// decodeTransactions will decode the transactions (BEEF V1: raw transaction and BUMP flag,
+// BEEF V2: data format, BUMP index or txid and raw transaction)
+func (d *Decoder) decodeTransactions(format BEEFFormat) ([]*TxData, error) {
+	start := d.offset
+	nTransactions, err := d.readVarInt()
+	if err != nil {
+		return nil, err
+	} else if nTransactions < 2 {
+		return nil, d.failAt(start, ErrBeefInsufficientTransactions)
+	} else if nTransactions > d.limits.MaxTransactions {
+		return nil, d.failAt(start, fmt.Errorf("%d transactions: %w", nTransactions, ErrBeefTooManyTransactions))
+	}
+
+	transactions := make([]*TxData, 0, nTransactions)
+	for i := uint64(0); i < nTransactions; i++ {
+		var tx *TxData
+		if format == BEEFV2 {
+			tx, err = d.decodeTransactionWithDataFormat()
+		} else {
+			tx, err = d.decodeTransactionWithPathIndex()
+		}
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, nil
+}
+
+// decodeTransactionWithPathIndex will decode a BEEF V1 transaction (raw transaction and BUMP flag)
+func (d *Decoder) decodeTransactionWithPathIndex() (*TxData, error) {
+	tx, err := d.readTransaction()
+	if err != nil {
+		return nil, err
+	}
+
+	start := d.offset
+	flag, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch flag {
+	case HasBump:
+		bumpIndex, err := d.readVarInt()
+		if err != nil {
+			return nil, err
+		}
+		pathIndex := util.VarInt(bumpIndex)
+		return &TxData{Transaction: tx, BumpIndex: &pathIndex}, nil
+	case HasNoBump:
+		return &TxData{Transaction: tx}, nil
+	default:
+		return nil, d.failAt(start, ErrBeefInvalidHasCMPFlag)
+	}
+}
+
+// decodeTransactionWithDataFormat will decode a BEEF V2 transaction (data format, BUMP index or txid and raw transaction)
+func (d *Decoder) decodeTransactionWithDataFormat() (*TxData, error) {
+	start := d.offset
+	format, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var pathIndex *util.VarInt
+	switch format {
+	case TxIDOnly:
+		txID, err := d.readBytes(hashBytesCount)
+		if err != nil {
+			return nil, err
+		}
+		return NewTxIDOnly(hex.EncodeToString(util.ReverseBytes(txID))), nil
+	case HasBump:
+		bumpIndex, err := d.readVarInt()
+		if err != nil {
+			return nil, err
+		}
+		value := util.VarInt(bumpIndex)
+		pathIndex = &value
+	case HasNoBump:
+	default:
+		return nil, d.failAt(start, fmt.Errorf("format %d: %w", format, ErrBeefInvalidDataFormat))
+	}
+
+	tx, err := d.readTransaction()
+	if err != nil {
+		return nil, err
+	}
+	return &TxData{Transaction: tx, BumpIndex: pathIndex}, nil
+}
+
+// readTransaction will read a raw transaction (the lengths are checked against the size limit before reading)
+func (d *Decoder) readTransaction() (*sdk.Transaction, error) {
+	start := d.offset
+	raw := new(bytes.Buffer)
+
+	copyBytes := func(n uint64) error {
+		b, err := d.readBytes(n)
+		raw.Write(b)
+		return err
+	}
+	copyVarInt := func() (uint64, error) {
+		value, err := d.readVarInt()
+		raw.Write(util.VarInt(value).Bytes())
+		return value, err
+	}
+	copyScript := func(fixedBytes uint64) error {
+		if err := copyBytes(fixedBytes); err != nil {
+			return err
+		}
+		length, err := copyVarInt()
+		if err != nil {
+			return err
+		}
+		return copyBytes(length)
+	}
+
+	if err := copyBytes(txVersionBytes); err != nil {
+		return nil, err
+	}
+	nInputs, err := copyVarInt()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < nInputs; i++ {
+		if err = copyScript(outpointBytes); err != nil {
+			return nil, err
+		} else if err = copyBytes(sequenceBytes); err != nil {
+			return nil, err
+		}
+	}
+	nOutputs, err := copyVarInt()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < nOutputs; i++ {
+		if err = copyScript(satoshisBytes); err != nil {
+			return nil, err
+		}
+	}
+	if err = copyBytes(txLockTimeBytes); err != nil {
+		return nil, err
+	}
+
+	tx, err := sdk.NewTransactionFromBytes(raw.Bytes())
+	if err != nil {
+		return nil, d.failAt(start, err)
+	}
+	return tx, nil
+}
+
+// readBytes will read n bytes (the size limit is checked before allocating)
+func (d *Decoder) readBytes(n uint64) ([]byte, error) {
+	if n > d.remaining() {
+		return nil, d.fail(fmt.Errorf("%d bytes: %w", n, ErrBeefTooLarge))
+	}
+
+	b := make([]byte, n)
+	read, err := io.ReadFull(d.reader, b)
+	d.offset += int64(read)
+	if err != nil {
+		return nil, d.fail(d.readError(err))
+	}
+	return b, nil
+}
+
+// readEnd will check that the stream ends (the BEEF cannot be followed by other bytes)
+func (d *Decoder) readEnd() error {
+	if _, err := d.reader.Peek(1); err == nil {
+		return d.fail(ErrBeefTrailingBytes)
+	} else if !errors.Is(err, io.EOF) {
+		return d.fail(d.readError(err))
+	}
+	return nil
+}
+
+// remaining will return the number of bytes which can be read before the size limit
+func (d *Decoder) remaining() uint64 {
+	return uint64(d.limits.MaxSize - d.offset) //nolint:gosec // G115: the offset never exceeds the size limit
+}
+
+// readByte will read one byte
+func (d *Decoder) readByte() (byte, error) {
+	b, err := d.readBytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// readVarInt will read a VarInt (1, 3, 5 or 9 bytes)
+func (d *Decoder) readVarInt() (uint64, error) {
+	prefix, err := d.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	var length uint64
+	switch prefix {
+	case 0xfd:
+		length = 2
+	case 0xfe:
+		length = 4
+	case 0xff:
+		length = 8
+	default:
+		return uint64(prefix), nil
+	}
+
+	b, err := d.readBytes(length)
+	if err != nil {
+		return 0, err
+	}
+	value, _ := util.NewVarIntFromBytes(append([]byte{prefix}, b...))
+	return uint64(value), nil
+}
+
+// readError will return the error of a failed read (hex decoding errors are invalid hex streams)
+func (d *Decoder) readError(err error) error {
+	var invalidByte hex.InvalidByteError
+	switch {
+	case errors.As(err, &invalidByte):
+		return fmt.Errorf("%w: %w", ErrBeefInvalidHexStream, err)
+	case errors.Is(err, io.EOF):
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// fail will return the error at the current offset
+func (d *Decoder) fail(err error) error {
+	return d.failAt(d.offset, err)
+}
+
+// failAt will return the error at the offset
+func (d *Decoder) failAt(offset int64, err error) error {
+	return &DecodeError{Err: err, Offset: offset}
+}
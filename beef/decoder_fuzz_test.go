@@ -0,0 +1,34 @@
+package beef
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// FuzzDecoder_Decode tests the BEEF decoder with arbitrary binary inputs.
+// It verifies that the decoder does not panic and only returns decode errors.
+func FuzzDecoder_Decode(f *testing.F) {
+	// Seed corpus with representative examples
+	beefBytes, _ := hex.DecodeString(testBEEFHex)
+	seeds := [][]byte{
+		beefBytes,
+		[]byte(testBEEFHex),
+		beefBytes[:100],
+		{0x02, 0x00, 0xBE, 0xEF, 0x00, 0x02, 0x02},
+		{0x01, 0x01, 0x01, 0x01},
+		{},
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		_, err := NewDecoder(bytes.NewReader(input), Limits{MaxSize: 1 << 16}).Decode()
+		var decodeErr *DecodeError
+		if err != nil && !errors.As(err, &decodeErr) {
+			t.Fatalf("unexpected error type: %v", err)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package beef
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBEEFV2 will return the transactions of the test BEEF encoded by the go-sdk as BEEF V2
+func newTestBEEFV2(t *testing.T) (*sdk.Beef, *sdk.Transaction) {
+	decodedBEEF, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	parent, child := decodedBEEF.Transactions[0].Transaction, decodedBEEF.Transactions[1].Transaction
+	parent.MerklePath = toMerklePath(t, decodedBEEF.BUMPs[0])
+
+	sdkBEEF := sdk.NewBeefV2()
+	_, err = sdkBEEF.MergeTransaction(parent)
+	require.NoError(t, err)
+	_, err = sdkBEEF.MergeTransaction(child)
+	require.NoError(t, err)
+	return sdkBEEF, child
+}
+
+// requireDecodeError will check the error and the offset of the decode error
+func requireDecodeError(t *testing.T, err, expectedError error, expectedOffset int64) {
+	require.ErrorIs(t, err, expectedError)
+	var decodeErr *DecodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, expectedOffset, decodeErr.Offset)
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	t.Run("hex and binary BEEF are decoded as DecodeBEEF", func(t *testing.T) {
+		// given
+		sdkBEEF, child := newTestBEEFV2(t)
+		beefV2, err := sdkBEEF.Bytes()
+		require.NoError(t, err)
+		atomicBEEF, err := sdkBEEF.AtomicBytes(child.TxID())
+		require.NoError(t, err)
+		beefV1, err := hex.DecodeString(testBEEFHex)
+		require.NoError(t, err)
+
+		for name, beefBytes := range map[string][]byte{"BEEF V1": beefV1, "BEEF V2": beefV2, "Atomic BEEF": atomicBEEF} {
+			t.Run(name, func(t *testing.T) {
+				expected, err := DecodeBEEF(hex.EncodeToString(beefBytes))
+				require.NoError(t, err)
+
+				// when
+				fromBinary, err := NewDecoder(bytes.NewReader(beefBytes), DefaultLimits()).Decode()
+				require.NoError(t, err)
+				fromHex, err := NewDecoder(strings.NewReader(hex.EncodeToString(beefBytes)), Limits{}).Decode()
+				require.NoError(t, err)
+
+				// then
+				assert.Equal(t, fromBinary, fromHex)
+				assert.Equal(t, expected.BUMPs, fromBinary.BUMPs)
+				assert.Equal(t, expected.Format, fromBinary.Format)
+				assert.Equal(t, expected.SubjectTxID, fromBinary.SubjectTxID)
+				require.Len(t, fromBinary.Transactions, len(expected.Transactions))
+				for i, tx := range expected.Transactions {
+					assert.Equal(t, tx.GetTxID(), fromBinary.Transactions[i].GetTxID())
+					assert.Equal(t, tx.BumpIndex, fromBinary.Transactions[i].BumpIndex)
+				}
+			})
+		}
+	})
+
+	t.Run("TXID-only transactions", func(t *testing.T) {
+		// given
+		sdkBEEF, child := newTestBEEFV2(t)
+		sdkBEEF.MakeTxidOnly(child.Inputs[0].SourceTXID)
+		beefBytes, err := sdkBEEF.Bytes()
+		require.NoError(t, err)
+
+		// when
+		decoded, err := NewDecoder(bytes.NewReader(beefBytes), Limits{}).Decode()
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, decoded.Transactions[0].TxIDOnly)
+		assert.Equal(t, child.Inputs[0].SourceTXID.String(), decoded.Transactions[0].GetTxID())
+		assert.Equal(t, child.TxID(), decoded.GetLatestTx().TxID())
+	})
+}
+
+func TestDecoder_Decode_Limits(t *testing.T) {
+	beefBytes, err := hex.DecodeString(testBEEFHex)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		limits         Limits
+		expectedError  error
+		expectedOffset int64
+	}{
+		"size": {
+			limits:         Limits{MaxSize: 100},
+			expectedError:  ErrBeefTooLarge,
+			expectedOffset: 83,
+		},
+		"tree height": {
+			limits:         Limits{MaxTreeHeight: 6},
+			expectedError:  ErrBeefInvalidTreeHeight,
+			expectedOffset: 10,
+		},
+		"leaves": {
+			limits:         Limits{MaxLeaves: 7},
+			expectedError:  ErrBeefTooManyLeaves,
+			expectedOffset: 255,
+		},
+		"transactions": {
+			limits:         Limits{MaxTransactions: 1},
+			expectedError:  ErrBeefTooManyTransactions,
+			expectedOffset: 290,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := NewDecoder(bytes.NewReader(beefBytes), tc.limits).Decode()
+			requireDecodeError(t, err, tc.expectedError, tc.expectedOffset)
+			assert.Nil(t, decoded)
+		})
+	}
+}
+
+func TestDecoder_Decode_HandlingErrors(t *testing.T) {
+	testCases := map[string]struct {
+		beefHex        string
+		expectedError  error
+		expectedOffset int64
+	}{
+		"empty": {
+			beefHex:        "",
+			expectedError:  ErrBeefInvalidHexStream,
+			expectedOffset: 0,
+		},
+		"invalid hex": {
+			beefHex:        "0100bezz",
+			expectedError:  ErrBeefInvalidHexStream,
+			expectedOffset: 3,
+		},
+		"invalid version": {
+			beefHex:        "0300beef",
+			expectedError:  ErrBeefInvalidVersion,
+			expectedOffset: 0,
+		},
+		"invalid marker": {
+			beefHex:        "0100beee",
+			expectedError:  ErrBeefInvalidMarker,
+			expectedOffset: 2,
+		},
+		"truncated": {
+			beefHex:        testBEEFHex[:200],
+			expectedError:  io.ErrUnexpectedEOF,
+			expectedOffset: 100,
+		},
+		"no BUMPs in BEEF V1": {
+			beefHex:        "0100beef00",
+			expectedError:  ErrBeefNoLowestBump,
+			expectedOffset: 4,
+		},
+		"huge number of BUMPs": {
+			beefHex:        "0100beefffffffffffffffffff",
+			expectedError:  ErrBeefTooManyBUMPs,
+			expectedOffset: 4,
+		},
+		"huge number of transactions": {
+			beefHex:        "0200beef00ffffffffffffffffff",
+			expectedError:  ErrBeefTooManyTransactions,
+			expectedOffset: 5,
+		},
+		"huge number of leaves": {
+			beefHex:        "0100beef01fe636d0c0001ffffffffffffffffff",
+			expectedError:  ErrBeefTooManyLeaves,
+			expectedOffset: 11,
+		},
+		"huge script length": {
+			beefHex:        "0200beef0002000100000001" + strings.Repeat("00", 36) + "ffffffffffffffff7f",
+			expectedError:  ErrBeefTooLarge,
+			expectedOffset: 57,
+		},
+		"invalid leaf flag": {
+			beefHex:        "0100beef01fe636d0c00010100" + "03",
+			expectedError:  ErrBeefInvalidFlag,
+			expectedOffset: 13,
+		},
+		"invalid data format": {
+			beefHex:        "0200beef000203",
+			expectedError:  ErrBeefInvalidDataFormat,
+			expectedOffset: 6,
+		},
+		"one transaction": {
+			beefHex:        "0200beef0001",
+			expectedError:  ErrBeefInsufficientTransactions,
+			expectedOffset: 5,
+		},
+		"trailing bytes": {
+			beefHex:        testBEEFHex + "0100beef",
+			expectedError:  ErrBeefTrailingBytes,
+			expectedOffset: int64(len(testBEEFHex) / 2),
+		},
+		"trailing invalid hex": {
+			beefHex:        testBEEFHex + "zz",
+			expectedError:  ErrBeefInvalidHexStream,
+			expectedOffset: int64(len(testBEEFHex) / 2),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			decoded, err := NewDecoder(strings.NewReader(tc.beefHex), Limits{}).Decode()
+
+			// then
+			requireDecodeError(t, err, tc.expectedError, tc.expectedOffset)
+			assert.Nil(t, decoded)
+		})
+	}
+
+	t.Run("trailing bytes of a binary BEEF", func(t *testing.T) {
+		beefBytes, err := hex.DecodeString(testBEEFHex)
+		require.NoError(t, err)
+
+		_, err = NewDecoder(bytes.NewReader(append(beefBytes, 0x00)), Limits{}).Decode()
+		requireDecodeError(t, err, ErrBeefTrailingBytes, int64(len(beefBytes)))
+	})
+
+	t.Run("reader error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		reader := io.MultiReader(strings.NewReader("0100be"), &failingReader{err: readErr})
+
+		_, err := NewDecoder(reader, Limits{}).Decode()
+		require.ErrorIs(t, err, readErr)
+	})
+}
+
+// failingReader is a reader that always returns the error
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func BenchmarkDecoder_Decode(b *testing.B) {
+	beefBytes, _ := hex.DecodeString(testBEEFHex)
+	for i := 0; i < b.N; i++ {
+		_, _ = NewDecoder(bytes.NewReader(beefBytes), Limits{}).Decode()
+	}
+}
@@ -0,0 +1,137 @@
+package beef
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// ErrBeefDoubleSpend is returned when two transactions of the BEEF spend the same outpoint
+	ErrBeefDoubleSpend = errors.New("invalid BEEF - outpoint spent by two transactions")
+	// ErrBeefDuplicateTransaction is returned when a transaction is in the BEEF more than once
+	ErrBeefDuplicateTransaction = errors.New("invalid BEEF - duplicate transaction")
+	// ErrBeefInvalidOrder is returned when a transaction is before its parent (not in topological order)
+	ErrBeefInvalidOrder = errors.New("invalid BEEF - transaction before its parent")
+	// ErrBeefNoProcessedTransaction is returned when the processed transaction is not a raw transaction
+	ErrBeefNoProcessedTransaction = errors.New("invalid BEEF - the processed transaction is not a raw transaction")
+)
+
+// StructureError is the error of the structure validation with the transaction of the failure
+type StructureError struct {
+	Err   error
+	Index int // The index of the transaction in the BEEF
+	TxID  string
+}
+
+// Error will return the error message with the transaction
+func (e *StructureError) Error() string {
+	return fmt.Sprintf("transaction %d (%s): %s", e.Index, e.TxID, e.Err.Error())
+}
+
+// Unwrap will return the underlying error (use errors.Is with the ErrBeef errors)
+func (e *StructureError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateStructure will check that the BEEF is consistent (the errors are a *StructureError)
+//
+// The BUMP indexes exist, no transaction is duplicated, the transactions are in topological order
+// (parents first, as required by GetLatestTx) and no outpoint is spent twice. The decoders do not
+// validate the structure: it is done by the SPV (see the spv package rules)
+func ValidateStructure(d *DecodedBEEF) error {
+	for _, validate := range []func(d *DecodedBEEF) error{
+		ValidateBUMPIndexes,
+		ValidateUniqueTransactions,
+		ValidateTransactionOrder,
+		ValidateNoDoubleSpends,
+	} {
+		if err := validate(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateBUMPIndexes will check that the BUMP indexes of the mined transactions are BUMPs of the BEEF
+func ValidateBUMPIndexes(d *DecodedBEEF) error {
+	for i, tx := range d.Transactions {
+		if !tx.Unmined() && uint64(*tx.BumpIndex) >= uint64(len(d.BUMPs)) {
+			return newStructureError(i, tx, fmt.Errorf("BUMP index %d: %w", *tx.BumpIndex, ErrBeefInvalidBumpIndex))
+		}
+	}
+	return nil
+}
+
+// ValidateUniqueTransactions will check that every transaction is in the BEEF once
+func ValidateUniqueTransactions(d *DecodedBEEF) error {
+	seen := make(map[string]struct{}, len(d.Transactions))
+	for i, tx := range d.Transactions {
+		if _, ok := seen[tx.GetTxID()]; ok {
+			return newStructureError(i, tx, ErrBeefDuplicateTransaction)
+		}
+		seen[tx.GetTxID()] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateTransactionOrder will check that the parents in the BEEF are before their children (Kahn's ordering),
+// and that the processed transaction (the last one or the Atomic BEEF subject) is a raw transaction
+func ValidateTransactionOrder(d *DecodedBEEF) error {
+	if len(d.Transactions) == 0 {
+		return ErrBeefNoTransactions
+	}
+
+	positions := make(map[string]int, len(d.Transactions))
+	for i, tx := range d.Transactions {
+		if _, ok := positions[tx.GetTxID()]; !ok {
+			positions[tx.GetTxID()] = i
+		}
+	}
+
+	for i, tx := range d.Transactions {
+		if tx.TxIDOnly {
+			continue
+		}
+		for _, input := range tx.Transaction.Inputs {
+			if input.SourceTXID == nil {
+				continue
+			}
+			if parent, ok := positions[input.SourceTXID.String()]; ok && parent >= i {
+				return newStructureError(i, tx, fmt.Errorf("parent %s: %w", input.SourceTXID, ErrBeefInvalidOrder))
+			}
+		}
+	}
+
+	if d.GetLatestTx() == nil {
+		last := len(d.Transactions) - 1
+		return newStructureError(last, d.Transactions[last], ErrBeefNoProcessedTransaction)
+	}
+	return nil
+}
+
+// ValidateNoDoubleSpends will check that no outpoint is spent by two transactions of the BEEF
+func ValidateNoDoubleSpends(d *DecodedBEEF) error {
+	spent := make(map[string]struct{})
+	for i, tx := range d.Transactions {
+		if tx.TxIDOnly {
+			continue
+		}
+		for _, input := range tx.Transaction.Inputs {
+			if input.SourceTXID == nil {
+				continue
+			}
+			outpoint := input.SourceTXID.String() + ":" + strconv.FormatUint(uint64(input.SourceTxOutIndex), 10)
+			if _, ok := spent[outpoint]; ok {
+				return newStructureError(i, tx, fmt.Errorf("outpoint %s: %w", outpoint, ErrBeefDoubleSpend))
+			}
+			spent[outpoint] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// newStructureError will return the structure error of the transaction
+func newStructureError(index int, tx *TxData, err error) *StructureError {
+	return &StructureError{Err: err, Index: index, TxID: tx.GetTxID()}
+}
@@ -0,0 +1,144 @@
+package beef
+
+import (
+	"testing"
+
+	util "github.com/bsv-blockchain/go-sdk/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStructureBEEF will return a valid BEEF of a mined transaction, its unmined child and grandchild
+func newTestStructureBEEF(t *testing.T) *DecodedBEEF {
+	first, second := newTestTx(1), newTestTx(2)
+	firstBUMP, _ := newTestBlock(t, 800000, first, second)
+	parent := newTestTx(10, first)
+	child := newTestTx(11, parent)
+
+	bumpIndex := util.VarInt(0)
+	return &DecodedBEEF{
+		BUMPs:  BUMPs{firstBUMP},
+		Format: BEEFV1,
+		Transactions: []*TxData{
+			{Transaction: first, BumpIndex: &bumpIndex},
+			{Transaction: parent},
+			{Transaction: child},
+		},
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid BEEF", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("BUMP index out of range", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+		bumpIndex := util.VarInt(1)
+		dBeef.Transactions[0].BumpIndex = &bumpIndex
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		requireStructureError(t, err, ErrBeefInvalidBumpIndex, 0, dBeef.Transactions[0].GetTxID())
+	})
+
+	t.Run("duplicate transaction", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+		parent := dBeef.Transactions[1]
+		dBeef.Transactions = append(dBeef.Transactions, &TxData{Transaction: parent.Transaction})
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		requireStructureError(t, err, ErrBeefDuplicateTransaction, 3, parent.GetTxID())
+	})
+
+	t.Run("child before its parent", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+		dBeef.Transactions[1], dBeef.Transactions[2] = dBeef.Transactions[2], dBeef.Transactions[1]
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		requireStructureError(t, err, ErrBeefInvalidOrder, 1, dBeef.Transactions[1].GetTxID())
+	})
+
+	t.Run("processed transaction is TXID-only", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+		dBeef.Format = BEEFV2
+		dBeef.Transactions = append(dBeef.Transactions, NewTxIDOnly(newTestTx(12).TxID().String()))
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		requireStructureError(t, err, ErrBeefNoProcessedTransaction, 3, dBeef.Transactions[3].GetTxID())
+	})
+
+	t.Run("no transactions", func(t *testing.T) {
+		// given
+		dBeef := &DecodedBEEF{Format: BEEFV1}
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		require.ErrorIs(t, err, ErrBeefNoTransactions)
+		assert.Nil(t, dBeef.GetLatestTx())
+	})
+
+	t.Run("outpoint spent by two transactions", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+		conflict := newTestTx(12, dBeef.Transactions[0].Transaction)
+		dBeef.Transactions = append(dBeef.Transactions, &TxData{Transaction: conflict})
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		requireStructureError(t, err, ErrBeefDoubleSpend, 3, conflict.TxID().String())
+	})
+
+	t.Run("outpoint spent twice by a transaction", func(t *testing.T) {
+		// given
+		dBeef := newTestStructureBEEF(t)
+		parent := dBeef.Transactions[1].Transaction
+		child := newTestTx(11, parent, parent)
+		dBeef.Transactions[2] = &TxData{Transaction: child}
+
+		// when
+		err := ValidateStructure(dBeef)
+
+		// then
+		requireStructureError(t, err, ErrBeefDoubleSpend, 2, child.TxID().String())
+	})
+}
+
+// requireStructureError will check the error and the failed transaction of the structure error
+func requireStructureError(t *testing.T, err, expected error, index int, txID string) {
+	t.Helper()
+
+	require.ErrorIs(t, err, expected)
+	var structureErr *StructureError
+	require.ErrorAs(t, err, &structureErr)
+	assert.Equal(t, index, structureErr.Index)
+	assert.Equal(t, txID, structureErr.TxID)
+}
@@ -0,0 +1,291 @@
+package paymail
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache key prefixes
+const (
+	cacheKeyPrefixHTTP = "paymail:http:"
+	cacheKeyPrefixSRV  = "paymail:srv:"
+)
+
+// MemoryCache is an in-memory LRU cache with per-entry TTLs
+//
+// This is the default cache used by WithCache(nil)
+type MemoryCache struct {
+	items map[string]*list.Element
+	lru   *list.List
+	mu    sync.Mutex
+	size  int
+}
+
+// memoryCacheItem is a single entry in the memory cache
+type memoryCacheItem struct {
+	expires time.Time
+	key     string
+	value   []byte
+}
+
+// NewMemoryCache will create a new in-memory LRU cache holding up to size entries
+func NewMemoryCache(size int) *MemoryCache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	return &MemoryCache{
+		items: make(map[string]*list.Element, size),
+		lru:   list.New(),
+		size:  size,
+	}
+}
+
+// Get will return the value for the key (if found and not expired)
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*memoryCacheItem)
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		m.removeElement(element)
+		return nil, false
+	}
+
+	m.lru.MoveToFront(element)
+	return item.value, true
+}
+
+// Set will store the value for the key, evicting the least recently used entry if full
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	// Update an existing entry
+	if element, ok := m.items[key]; ok {
+		item := element.Value.(*memoryCacheItem)
+		item.value = value
+		item.expires = expires
+		m.lru.MoveToFront(element)
+		return
+	}
+
+	// Evict the oldest entry
+	if m.lru.Len() >= m.size {
+		if oldest := m.lru.Back(); oldest != nil {
+			m.removeElement(oldest)
+		}
+	}
+
+	m.items[key] = m.lru.PushFront(&memoryCacheItem{expires: expires, key: key, value: value})
+}
+
+// Delete will remove the key from the cache
+func (m *MemoryCache) Delete(_ context.Context, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.removeElement(element)
+	}
+}
+
+// Len will return the number of entries in the cache (including expired entries not yet evicted)
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+// removeElement will remove the element from the list and the index (lock must be held)
+func (m *MemoryCache) removeElement(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.items, element.Value.(*memoryCacheItem).key)
+}
+
+// cachedResponse is a cached HTTP response
+type cachedResponse struct {
+	Body       []byte    `json:"body"`
+	ETag       string    `json:"etag,omitempty"`
+	Expires    time.Time `json:"expires"`
+	StatusCode int       `json:"status_code"`
+}
+
+// cacheControl is the parsed Cache-Control header
+type cacheControl struct {
+	maxAge    time.Duration
+	hasMaxAge bool
+	noCache   bool
+	noStore   bool
+}
+
+// parseCacheControl will parse the directives used from the Cache-Control header
+func parseCacheControl(header string) (cc cacheControl) {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				cc.maxAge = time.Duration(seconds) * time.Second
+				cc.hasMaxAge = true
+			}
+		}
+	}
+	return cc
+}
+
+// getCachedRequest is a GET request that uses the client cache (if enabled)
+//
+// Fresh entries are returned without a request, stale entries with an ETag are
+// revalidated using If-None-Match and Cache-Control (no-store, no-cache, max-age) is honored
+func (c *Client) getCachedRequest(ctx context.Context, requestURL string) (response StandardResponse, err error) {
+	// Caching is disabled
+	if c.options.cache == nil {
+		return c.getRequest(ctx, requestURL)
+	}
+
+	// Return the entry if it's still fresh
+	key := cacheKeyPrefixHTTP + requestURL
+	entry := c.loadCachedResponse(ctx, key)
+	if entry != nil && time.Now().Before(entry.Expires) {
+		return StandardResponse{Body: entry.Body, StatusCode: entry.StatusCode}, nil
+	}
+
+	// Revalidate a stale entry
+	var headers map[string]string
+	if entry != nil && len(entry.ETag) > 0 {
+		headers = map[string]string{"If-None-Match": entry.ETag}
+	}
+
+	// Fire the GET request
+	if response, err = c.getRequestWithHeaders(ctx, requestURL, headers); err != nil {
+		return response, err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && headers != nil:
+		// Still valid, keep the cached body (and the etag if no new one was sent)
+		if response.Header == nil {
+			response.Header = make(http.Header)
+		}
+		if len(response.Header.Get("ETag")) == 0 {
+			response.Header.Set("ETag", entry.ETag)
+		}
+		response.Body = entry.Body
+		response.StatusCode = entry.StatusCode
+		c.storeCachedResponse(ctx, key, response)
+	case response.StatusCode == http.StatusOK:
+		c.storeCachedResponse(ctx, key, response)
+	default:
+		c.options.cache.Delete(ctx, key)
+	}
+
+	return response, err
+}
+
+// loadCachedResponse will return the cached response (if found)
+func (c *Client) loadCachedResponse(ctx context.Context, key string) *cachedResponse {
+	data, ok := c.options.cache.Get(ctx, key)
+	if !ok {
+		return nil
+	}
+	entry := new(cachedResponse)
+	if err := json.Unmarshal(data, entry); err != nil {
+		c.options.cache.Delete(ctx, key)
+		return nil
+	}
+	return entry
+}
+
+// storeCachedResponse will store the response using the Cache-Control and ETag headers
+func (c *Client) storeCachedResponse(ctx context.Context, key string, response StandardResponse) {
+	cc := parseCacheControl(response.Header.Get("Cache-Control"))
+	if cc.noStore {
+		c.options.cache.Delete(ctx, key)
+		return
+	}
+
+	// Set the freshness (no-cache always revalidates)
+	freshness := c.options.cacheTTL
+	if cc.noCache {
+		freshness = 0
+	} else if cc.hasMaxAge {
+		freshness = cc.maxAge
+	}
+
+	// Keep entries with an ETag around for revalidation
+	ttl := freshness
+	etag := response.Header.Get("ETag")
+	if len(etag) > 0 {
+		ttl += c.options.cacheTTL
+	}
+	if ttl <= 0 {
+		c.options.cache.Delete(ctx, key)
+		return
+	}
+
+	data, err := json.Marshal(&cachedResponse{
+		Body:       response.Body,
+		ETag:       etag,
+		Expires:    time.Now().Add(freshness),
+		StatusCode: response.StatusCode,
+	})
+	if err != nil {
+		return
+	}
+	c.options.cache.Set(ctx, key, data, ttl)
+}
+
+// srvCacheKey will return the cache key for an SRV lookup
+func srvCacheKey(service, protocol, domainName string) string {
+	return cacheKeyPrefixSRV + service + "." + protocol + "." + domainName
+}
+
+// loadCachedSRV will return the cached SRV records (if found)
+//
+// The records are stored as returned by the resolver, so they are re-ordered on every lookup
+func (c *Client) loadCachedSRV(ctx context.Context, key string) []*net.SRV {
+	if c.options.cache == nil {
+		return nil
+	}
+	data, ok := c.options.cache.Get(ctx, key)
+	if !ok {
+		return nil
+	}
+	var records []*net.SRV
+	if err := json.Unmarshal(data, &records); err != nil || len(records) == 0 {
+		c.options.cache.Delete(ctx, key)
+		return nil
+	}
+	return records
+}
+
+// storeCachedSRV will store the SRV records
+func (c *Client) storeCachedSRV(ctx context.Context, key string, records []*net.SRV) {
+	if c.options.cache == nil || c.options.srvCacheTTL <= 0 {
+		return
+	}
+	if data, err := json.Marshal(records); err == nil {
+		c.options.cache.Set(ctx, key, data, c.options.srvCacheTTL)
+	}
+}
@@ -0,0 +1,337 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryCache will test the methods of the MemoryCache
+func TestMemoryCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("set and get", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), time.Minute)
+
+		value, ok := cache.Get(ctx, "key")
+		require.True(t, ok)
+		assert.Equal(t, []byte("value"), value)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+
+		value, ok := cache.Get(ctx, "missing")
+		assert.False(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("update existing key", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), time.Minute)
+		cache.Set(ctx, "key", []byte("updated"), time.Minute)
+
+		value, ok := cache.Get(ctx, "key")
+		require.True(t, ok)
+		assert.Equal(t, []byte("updated"), value)
+		assert.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("expired entry", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		_, ok := cache.Get(ctx, "key")
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("no expiration", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), 0)
+
+		_, ok := cache.Get(ctx, "key")
+		assert.True(t, ok)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		cache.Set(ctx, "key", []byte("value"), time.Minute)
+		cache.Delete(ctx, "key")
+		cache.Delete(ctx, "missing")
+
+		_, ok := cache.Get(ctx, "key")
+		assert.False(t, ok)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		cache := NewMemoryCache(2)
+		cache.Set(ctx, "first", []byte("1"), time.Minute)
+		cache.Set(ctx, "second", []byte("2"), time.Minute)
+
+		// Touch the first key, so the second is the oldest
+		_, ok := cache.Get(ctx, "first")
+		require.True(t, ok)
+
+		cache.Set(ctx, "third", []byte("3"), time.Minute)
+		assert.Equal(t, 2, cache.Len())
+
+		_, ok = cache.Get(ctx, "second")
+		assert.False(t, ok)
+		_, ok = cache.Get(ctx, "first")
+		assert.True(t, ok)
+		_, ok = cache.Get(ctx, "third")
+		assert.True(t, ok)
+	})
+
+	t.Run("default size", func(t *testing.T) {
+		cache := NewMemoryCache(0)
+		assert.Equal(t, defaultCacheSize, cache.size)
+	})
+}
+
+// Test_parseCacheControl will test the method parseCacheControl()
+func Test_parseCacheControl(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header    string
+		maxAge    time.Duration
+		hasMaxAge bool
+		noCache   bool
+		noStore   bool
+	}{
+		{"", 0, false, false, false},
+		{"max-age=60", 60 * time.Second, true, false, false},
+		{"public, MAX-AGE=120", 120 * time.Second, true, false, false},
+		{`max-age="30"`, 30 * time.Second, true, false, false},
+		{"max-age=-1", 0, false, false, false},
+		{"max-age=abc", 0, false, false, false},
+		{"no-cache", 0, false, true, false},
+		{"no-store", 0, false, false, true},
+		{"private, no-cache, no-store, max-age=0", 0, true, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			cc := parseCacheControl(test.header)
+			assert.Equal(t, test.maxAge, cc.maxAge)
+			assert.Equal(t, test.hasMaxAge, cc.hasMaxAge)
+			assert.Equal(t, test.noCache, cc.noCache)
+			assert.Equal(t, test.noStore, cc.noStore)
+		})
+	}
+}
+
+// mockCachedCapabilities is used for mocking a capabilities response with cache headers
+//
+// If the request matches the etag, a 304 is returned
+func mockCachedCapabilities(cacheControl, etag string) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, "https://"+testDomain+":443/.well-known/"+DefaultServiceName,
+		func(req *http.Request) (*http.Response, error) {
+			if len(etag) > 0 && req.Header.Get("If-None-Match") == etag {
+				return httpmock.NewStringResponse(http.StatusNotModified, ""), nil
+			}
+			resp := httpmock.NewStringResponse(
+				http.StatusOK,
+				`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities":
+{"6745385c3fc0": false,"pki": "`+testServerURL+`id/{alias}@{domain.tld}",
+"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}"}}`,
+			)
+			if len(cacheControl) > 0 {
+				resp.Header.Set("Cache-Control", cacheControl)
+			}
+			if len(etag) > 0 {
+				resp.Header.Set("ETag", etag)
+			}
+			return resp, nil
+		},
+	)
+}
+
+// TestClient_CachedRequests will test the caching of capabilities, PKI and SRV responses
+func TestClient_CachedRequests(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	capabilitiesURL := "GET https://" + testDomain + ":443/.well-known/" + DefaultServiceName
+
+	t.Run("capabilities are cached", func(t *testing.T) {
+		client := newTestClient(t, WithCache(nil))
+
+		mockCachedCapabilities("", "")
+
+		for i := 0; i < 3; i++ {
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.True(t, response.Has(BRFCPki, ""))
+		}
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()[capabilitiesURL])
+	})
+
+	t.Run("caching disabled by default", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockCachedCapabilities("", "")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, httpmock.GetCallCountInfo()[capabilitiesURL])
+	})
+
+	t.Run("no-store is not cached", func(t *testing.T) {
+		client := newTestClient(t, WithCache(nil))
+
+		mockCachedCapabilities("no-store", "")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, httpmock.GetCallCountInfo()[capabilitiesURL])
+	})
+
+	t.Run("max-age of zero without etag is not cached", func(t *testing.T) {
+		client := newTestClient(t, WithCache(nil))
+
+		mockCachedCapabilities("max-age=0", "")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, httpmock.GetCallCountInfo()[capabilitiesURL])
+	})
+
+	t.Run("no-cache revalidates with etag", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		client := newTestClient(t, WithCache(cache))
+
+		mockCachedCapabilities("no-cache", `"v1"`)
+
+		// First request stores the response
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		// Second request is revalidated (304) and uses the cached body
+		response, err = client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, DefaultBsvAliasVersion, response.BsvAlias)
+		assert.True(t, response.Has(BRFCPki, ""))
+		assert.Equal(t, 2, httpmock.GetCallCountInfo()[capabilitiesURL])
+
+		// Entry is still in the cache
+		entry := client.(*Client).loadCachedResponse(context.Background(), cacheKeyPrefixHTTP+"https://"+testDomain+":443/.well-known/"+DefaultServiceName)
+		require.NotNil(t, entry)
+		assert.Equal(t, `"v1"`, entry.ETag)
+	})
+
+	t.Run("max-age expires", func(t *testing.T) {
+		client := newTestClient(t, WithCache(nil), WithCacheTTL(0))
+
+		mockCachedCapabilities("max-age=1", `"v2"`)
+
+		_, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		_, err = client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()[capabilitiesURL])
+	})
+
+	t.Run("error responses are not cached", func(t *testing.T) {
+		client := newTestClient(t, WithCache(nil))
+
+		mockCapabilities(http.StatusBadRequest)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.Error(t, err)
+		}
+		assert.Equal(t, 2, httpmock.GetCallCountInfo()[capabilitiesURL])
+	})
+
+	t.Run("pki is cached", func(t *testing.T) {
+		client := newTestClient(t, WithCache(nil))
+
+		mockGetPKI(http.StatusOK)
+
+		for i := 0; i < 2; i++ {
+			pki, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+			require.NoError(t, err)
+			require.NotNil(t, pki)
+			assert.Equal(t, testAlias+"@"+testDomain, pki.Handle)
+		}
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("srv records are cached", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		client := newTestClient(t, WithCache(cache))
+
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.NotNil(t, srv)
+
+		// Swap the resolver, the cached record should still be returned
+		_ = client.WithCustomResolver(nil)
+
+		srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.NotNil(t, srv)
+		assert.Equal(t, "www."+testDomain, srv.Target)
+		assert.Equal(t, uint16(DefaultPort), srv.Port)
+	})
+}
+
+// ExampleWithCache example using WithCache()
+//
+// See more examples in /examples/
+func ExampleWithCache() {
+	// Load the client (with the default in-memory cache)
+	client := newTestClient(nil, WithCache(nil))
+
+	mockCapabilities(http.StatusOK)
+
+	// Only the first request hits the network
+	for i := 0; i < 3; i++ {
+		_, _ = client.GetCapabilities(testDomain, DefaultPort)
+	}
+	fmt.Printf("requests: %d", httpmock.GetTotalCallCount())
+	// Output:requests: 1
+}
+
+// BenchmarkMemoryCache_Get benchmarks the method Get()
+func BenchmarkMemoryCache_Get(b *testing.B) {
+	cache := NewMemoryCache(defaultCacheSize)
+	ctx := context.Background()
+	cache.Set(ctx, "key", []byte("value"), time.Minute)
+	for i := 0; i < b.N; i++ {
+		_, _ = cache.Get(ctx, "key")
+	}
+}
+
+// BenchmarkMemoryCache_Set benchmarks the method Set()
+func BenchmarkMemoryCache_Set(b *testing.B) {
+	cache := NewMemoryCache(defaultCacheSize)
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		cache.Set(ctx, fmt.Sprintf("key-%d", i), []byte("value"), time.Minute)
+	}
+}
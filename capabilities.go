@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -95,6 +96,14 @@ func (c *CapabilitiesPayload) GetBool(brfcID, alternateID string) bool {
 //
 // Specs: http://bsvalias.org/02-02-capability-discovery.html
 func (c *Client) GetCapabilities(target string, port int) (response *CapabilitiesResponse, err error) {
+	return c.GetCapabilitiesCtx(context.Background(), target, port)
+}
+
+// GetCapabilitiesCtx is the context-aware version of GetCapabilities
+func (c *Client) GetCapabilitiesCtx(ctx context.Context, target string, port int) (response *CapabilitiesResponse, err error) {
+	ctx, end := c.startSpan(ctx, "GetCapabilities", "", target)
+	defer func() { end(err) }()
+
 	// Basic requirements for the request
 	if len(target) == 0 {
 		err = ErrCapabilitiesMissingTarget
@@ -108,9 +117,9 @@ func (c *Client) GetCapabilities(target string, port int) (response *Capabilitie
 	// https://<host-discovery-target>:<host-discovery-port>/.well-known/bsvalias[network]
 	reqURL := fmt.Sprintf("https://%s/.well-known/%s%s", net.JoinHostPort(target, fmt.Sprintf("%d", port)), DefaultServiceName, c.options.network.URLSuffix())
 
-	// Fire the GET request
+	// Fire the GET request (or use the cached response)
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getCachedRequest(ctx, reqURL); err != nil {
 		return response, err
 	}
 
@@ -119,11 +128,7 @@ func (c *Client) GetCapabilities(target string, port int) (response *Capabilitie
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return response, err
-		}
-		err = fmt.Errorf("code %d, message: %s: %w", response.StatusCode, serverError.Message, ErrCapabilitiesBadResponse)
+		err = newResponseError(&resp, reqURL, "", ErrCapabilitiesBadResponse)
 		return response, err
 	}
 
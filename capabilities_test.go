@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -246,6 +247,19 @@ func TestClient_GetCapabilities(t *testing.T) {
 		require.Equal(t, "https://examples.com/v1/bsvalias/pike/outputs/{alias}@{domain.tld}", *response.Pike.Outputs)
 		require.Equal(t, "https://examples.com/v1/bsvalias/contact/invite/{alias}@{domain.tld}", *response.Pike.Invite)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockCapabilities(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		response, err := client.GetCapabilitiesCtx(ctx, testDomain, DefaultPort)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, response)
+	})
 }
 
 // mockCapabilities is used for mocking the response
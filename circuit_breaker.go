@@ -0,0 +1,244 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is when the circuit breaker for the host is open (requests fail fast)
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of the circuit breaker for a host
+type CircuitState int
+
+// Circuit breaker states
+const (
+	CircuitClosed   CircuitState = iota // Requests are sent (the host is healthy)
+	CircuitOpen                         // Requests fail fast (the host is failing)
+	CircuitHalfOpen                     // Trial requests are sent to check if the host recovered
+)
+
+// String will return the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerPolicy is the policy used by the per-host circuit breaker
+//
+// A failure is a transport error (timeout, connection refused, etc.) or a 5xx response
+type CircuitBreakerPolicy struct {
+	FailureThreshold int           // Consecutive failures before the circuit opens
+	HalfOpenRequests int           // Trial requests allowed at once while half-open
+	OpenTimeout      time.Duration // Time the circuit stays open before trial requests are allowed
+}
+
+// DefaultCircuitBreakerPolicy will return the default circuit breaker policy
+//
+// The circuit opens after 5 consecutive failures and allows one trial request after 30 seconds
+func DefaultCircuitBreakerPolicy() CircuitBreakerPolicy {
+	return CircuitBreakerPolicy{
+		FailureThreshold: defaultCircuitFailureThreshold,
+		HalfOpenRequests: 1,
+		OpenTimeout:      defaultCircuitOpenTimeout,
+	}
+}
+
+// CircuitOpenError is returned when a request is rejected by the circuit breaker
+//
+// It wraps ErrCircuitOpen, use errors.Is(err, ErrCircuitOpen) or errors.As(err, &circuitErr)
+type CircuitOpenError struct {
+	Host       string        // The host of the request
+	RetryAfter time.Duration // Time until trial requests are allowed (zero if half-open)
+}
+
+// Error will return the error message
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s (retry after %s)", ErrCircuitOpen.Error(), e.Host, e.RetryAfter)
+}
+
+// Unwrap will return ErrCircuitOpen
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// circuitResult is the outcome of a request (as seen by the circuit breaker)
+type circuitResult int
+
+const (
+	circuitSuccess circuitResult = iota // The host responded
+	circuitFailure                      // The host failed (transport error or 5xx)
+	circuitIgnored                      // The request did not reach the host (canceled, rate limited)
+)
+
+// circuit is the circuit breaker state for a single host
+type circuit struct {
+	failures int          // Consecutive failures (while closed)
+	inFlight int          // Trial requests in flight (while half-open)
+	openedAt time.Time    // Time the circuit opened
+	state    CircuitState // Current state
+}
+
+// circuitBreaker holds the circuits for all hosts
+type circuitBreaker struct {
+	circuits map[string]*circuit
+	mu       sync.Mutex
+	now      func() time.Time
+	policy   CircuitBreakerPolicy
+}
+
+// newCircuitBreaker will create a circuit breaker (zero values in the policy use the defaults)
+func newCircuitBreaker(policy CircuitBreakerPolicy) *circuitBreaker {
+	defaults := DefaultCircuitBreakerPolicy()
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = defaults.FailureThreshold
+	}
+	if policy.HalfOpenRequests <= 0 {
+		policy.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+	if policy.OpenTimeout <= 0 {
+		policy.OpenTimeout = defaults.OpenTimeout
+	}
+	return &circuitBreaker{
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+		policy:   policy,
+	}
+}
+
+// intercept is the interceptor that rejects requests to hosts with an open circuit
+func (b *circuitBreaker) intercept(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+	host := requestHost(req.URL)
+	if err := b.allow(host); err != nil {
+		return StandardResponse{}, err
+	}
+
+	response, err := next(ctx, req)
+	switch {
+	case err != nil && (ctx.Err() != nil || errors.Is(err, ErrRateLimited)):
+		b.record(host, circuitIgnored)
+	case err != nil || response.StatusCode >= http.StatusInternalServerError:
+		b.record(host, circuitFailure)
+	default:
+		b.record(host, circuitSuccess)
+	}
+	return response, err
+}
+
+// allow will return an error if the request to the host must be rejected
+func (b *circuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{state: CircuitClosed}
+		b.circuits[host] = c
+	}
+
+	switch c.state {
+	case CircuitClosed:
+		return nil
+	case CircuitOpen:
+		if wait := b.policy.OpenTimeout - b.now().Sub(c.openedAt); wait > 0 {
+			return &CircuitOpenError{Host: host, RetryAfter: wait}
+		}
+		c.state = CircuitHalfOpen
+		c.inFlight = 0
+	case CircuitHalfOpen:
+	}
+
+	// Half-open: only allow a limited number of trial requests
+	if c.inFlight >= b.policy.HalfOpenRequests {
+		return &CircuitOpenError{Host: host}
+	}
+	c.inFlight++
+	return nil
+}
+
+// record will update the circuit of the host with the result of the request
+func (b *circuitBreaker) record(host string, result circuitResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		return
+	}
+
+	switch c.state {
+	case CircuitClosed:
+		if result == circuitSuccess {
+			c.failures = 0
+		} else if result == circuitFailure {
+			if c.failures++; c.failures >= b.policy.FailureThreshold {
+				b.open(c)
+			}
+		}
+	case CircuitHalfOpen:
+		c.inFlight = max(c.inFlight-1, 0)
+		if result == circuitSuccess {
+			c.state = CircuitClosed
+			c.failures = 0
+		} else if result == circuitFailure {
+			b.open(c)
+		}
+	case CircuitOpen: // Started before the circuit opened
+	}
+}
+
+// open will open the circuit
+func (b *circuitBreaker) open(c *circuit) {
+	c.state = CircuitOpen
+	c.openedAt = b.now()
+	c.failures = 0
+	c.inFlight = 0
+}
+
+// states will return the current state of every known host
+func (b *circuitBreaker) states() map[string]CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make(map[string]CircuitState, len(b.circuits))
+	for host, c := range b.circuits {
+		state := c.state
+		if state == CircuitOpen && b.now().Sub(c.openedAt) >= b.policy.OpenTimeout {
+			state = CircuitHalfOpen // The next request is a trial request
+		}
+		states[host] = state
+	}
+	return states
+}
+
+// CircuitStates will return the circuit breaker state of every host the client has sent requests to
+//
+// Returns nil if the circuit breaker is disabled (see WithCircuitBreaker)
+func (c *Client) CircuitStates() map[string]CircuitState {
+	if c.breaker == nil {
+		return nil
+	}
+	return c.breaker.states()
+}
+
+// requestHost will return the host (and port) of the request url
+func requestHost(requestURL string) string {
+	u, err := url.Parse(requestURL)
+	if err != nil || len(u.Host) == 0 {
+		return requestURL
+	}
+	return strings.ToLower(u.Host)
+}
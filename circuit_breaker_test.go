@@ -0,0 +1,231 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// testClock is a manual clock for the circuit breaker and rate limiter
+type testClock struct {
+	now time.Time
+}
+
+// Now will return the current time of the clock
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// Advance will move the clock forward
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestBreakerClient will return a client with the circuit breaker (and a manual clock)
+func newTestBreakerClient(t *testing.T, policy CircuitBreakerPolicy, opts ...ClientOps) (*Client, *testClock) {
+	client, ok := newTestClient(t, append([]ClientOps{WithCircuitBreaker(policy)}, opts...)...).(*Client)
+	require.True(t, ok)
+	clock := &testClock{now: time.Now()}
+	client.breaker.now = clock.Now
+	return client, clock
+}
+
+// getTestPKI will get the test PKI (using the test server)
+func getTestPKI(client ClientInterface) error {
+	_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	return err
+}
+
+// TestCircuitState_String will test the method String()
+func TestCircuitState_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "closed", CircuitClosed.String())
+	assert.Equal(t, "open", CircuitOpen.String())
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	assert.Equal(t, "unknown", CircuitState(10).String())
+}
+
+// TestClient_CircuitBreaker will test the circuit breaker set with WithCircuitBreaker()
+func TestClient_CircuitBreaker(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	policy := CircuitBreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Minute}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := newTestClient(t)
+		assert.Nil(t, client.CircuitStates())
+	})
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		client, _ := newTestBreakerClient(t, policy)
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.ErrorIs(t, getTestPKI(client), ErrPKIBadResponse)
+		assert.Equal(t, CircuitClosed, client.CircuitStates()["test.com"])
+		require.ErrorIs(t, getTestPKI(client), ErrPKIBadResponse)
+		assert.Equal(t, CircuitOpen, client.CircuitStates()["test.com"])
+
+		// Fails fast without sending the request
+		mockGetPKI(http.StatusOK)
+		err := getTestPKI(client)
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+
+		var circuitErr *CircuitOpenError
+		require.ErrorAs(t, err, &circuitErr)
+		assert.Equal(t, "test.com", circuitErr.Host)
+		assert.Equal(t, time.Minute, circuitErr.RetryAfter)
+	})
+
+	t.Run("success resets the failures", func(t *testing.T) {
+		client, _ := newTestBreakerClient(t, policy)
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+		mockGetPKI(http.StatusOK)
+		require.NoError(t, getTestPKI(client))
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+		assert.Equal(t, CircuitClosed, client.CircuitStates()["test.com"])
+	})
+
+	t.Run("client errors are not failures", func(t *testing.T) {
+		client, _ := newTestBreakerClient(t, policy)
+
+		mockGetPKI(http.StatusNotFound)
+		for i := 0; i < 3; i++ {
+			require.ErrorIs(t, getTestPKI(client), ErrPKIBadResponse)
+		}
+		assert.Equal(t, CircuitClosed, client.CircuitStates()["test.com"])
+	})
+
+	t.Run("half-open trial request closes the circuit", func(t *testing.T) {
+		client, clock := newTestBreakerClient(t, policy)
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+		require.Error(t, getTestPKI(client))
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, CircuitHalfOpen, client.CircuitStates()["test.com"])
+
+		mockGetPKI(http.StatusOK)
+		require.NoError(t, getTestPKI(client))
+		assert.Equal(t, CircuitClosed, client.CircuitStates()["test.com"])
+	})
+
+	t.Run("half-open trial failure opens the circuit", func(t *testing.T) {
+		client, clock := newTestBreakerClient(t, policy)
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+		require.Error(t, getTestPKI(client))
+
+		clock.Advance(time.Minute)
+		require.ErrorIs(t, getTestPKI(client), ErrPKIBadResponse)
+		assert.Equal(t, CircuitOpen, client.CircuitStates()["test.com"])
+		require.ErrorIs(t, getTestPKI(client), ErrCircuitOpen)
+	})
+
+	t.Run("half-open limits the trial requests", func(t *testing.T) {
+		client, clock := newTestBreakerClient(t, policy)
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+		require.Error(t, getTestPKI(client))
+		clock.Advance(time.Minute)
+
+		// Allow the first trial request, reject the second one
+		require.NoError(t, client.breaker.allow("test.com"))
+		err := client.breaker.allow("test.com")
+		require.ErrorIs(t, err, ErrCircuitOpen)
+	})
+
+	t.Run("canceled requests are ignored", func(t *testing.T) {
+		client, _ := newTestBreakerClient(t, CircuitBreakerPolicy{FailureThreshold: 1})
+
+		mockGetPKI(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, CircuitClosed, client.CircuitStates()["test.com"])
+	})
+
+	t.Run("circuits are per host", func(t *testing.T) {
+		client, _ := newTestBreakerClient(t, CircuitBreakerPolicy{FailureThreshold: 1})
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+
+		httpmock.RegisterResponder(http.MethodGet, "https://other.com/api/v1/bsvalias/id/mrz@other.com",
+			httpmock.NewStringResponder(http.StatusOK, `{"bsvalias": "1.0","handle": "mrz@other.com","pubkey": "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"}`))
+		_, err := client.GetPKI("https://other.com/api/v1/bsvalias/id/{alias}@{domain.tld}", testAlias, "other.com")
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]CircuitState{"test.com": CircuitOpen, "other.com": CircuitClosed}, client.CircuitStates())
+	})
+
+	t.Run("state is reported as a metric", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		client, _ := newTestBreakerClient(t, CircuitBreakerPolicy{FailureThreshold: 1},
+			WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+		mockGetPKI(http.StatusInternalServerError)
+		require.Error(t, getTestPKI(client))
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		var points []metricdata.DataPoint[int64]
+		for _, m := range rm.ScopeMetrics[0].Metrics {
+			if m.Name == "paymail.client.circuit.state" {
+				gauge, ok := m.Data.(metricdata.Gauge[int64])
+				require.True(t, ok)
+				points = gauge.DataPoints
+			}
+		}
+		require.Len(t, points, 1)
+		assert.Equal(t, int64(CircuitOpen), points[0].Value)
+		domain, _ := points[0].Attributes.Value(AttributeDomain)
+		assert.Equal(t, "test.com", domain.AsString())
+	})
+}
+
+// ExampleWithCircuitBreaker example using WithCircuitBreaker()
+//
+// See more examples in /examples/
+func ExampleWithCircuitBreaker() {
+	// Load the client (open the circuit after 2 consecutive failures)
+	client := newTestClient(nil, WithCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 2}))
+
+	mockGetPKI(http.StatusServiceUnavailable)
+
+	// The provider is down
+	for i := 0; i < 3; i++ {
+		err := getTestPKI(client)
+		fmt.Println(client.CircuitStates()["test.com"], errors.Is(err, ErrCircuitOpen))
+	}
+	// Output:closed false
+	// open false
+	// open true
+}
+
+// BenchmarkClient_CircuitBreaker benchmarks a request with the circuit breaker enabled
+func BenchmarkClient_CircuitBreaker(b *testing.B) {
+	client := newTestClient(nil, WithCircuitBreaker(DefaultCircuitBreakerPolicy()))
+	mockGetPKI(http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_ = getTestPKI(client)
+	}
+}
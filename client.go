@@ -1,9 +1,17 @@
 package paymail
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/miekg/dns"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-paymail/interfaces"
 )
@@ -11,25 +19,43 @@ import (
 type (
 	// Client is the Paymail client configuration and options
 	Client struct {
+		breaker    *circuitBreaker        // Per-host circuit breaker (disabled if nil)
 		httpClient *resty.Client          // HTTP client for GET/POST requests
+		limiter    *rateLimiter           // Per-host rate limiter (disabled if nil)
 		options    *ClientOptions         // Options are all the default settings / configuration
 		resolver   interfaces.DNSResolver // Resolver for DNS look ups
+		telemetry  *telemetry             // OpenTelemetry tracer and instruments
 	}
 
 	// ClientOptions holds all the configuration for client requests and default resources
 	ClientOptions struct {
-		brfcSpecs         []*BRFCSpec   // List of BRFC specifications
-		dnsPort           string        // Default DNS port for SRV checks
-		dnsTimeout        time.Duration // Default timeout in seconds for DNS fetching
-		httpTimeout       time.Duration // Default timeout in seconds for GET requests
-		nameServer        string        // Default name server for DNS checks
-		nameServerNetwork string        // Default name server network
-		requestTracing    bool          // If enabled, it will trace the request timing
-		retryCount        int           // Default retry count for HTTP requests
-		sslDeadline       time.Duration // Default timeout in seconds for SSL deadline
-		sslTimeout        time.Duration // Default timeout in seconds for SSL timeout
-		userAgent         string        // User agent for all outgoing requests
-		network           Network       // The bitcoin network to operate on
+		brfcSpecs         []*BRFCSpec           // List of BRFC specifications
+		cache             interfaces.Cache      // Cache for capabilities, PKI and SRV responses (disabled if nil)
+		cacheTTL          time.Duration         // Default freshness for cached responses (without cache headers)
+		circuitBreaker    *CircuitBreakerPolicy // Per-host circuit breaker policy (disabled if nil)
+		dnssecAnchors     []*dns.DS             // Trust anchors for the DNSSEC validation (root zone if empty)
+		dnsOverHTTPS      string                // DNS-over-HTTPS endpoint for SRV and host lookups (disabled if empty)
+		dnsOverTLS        string                // DNS-over-TLS server for SRV and host lookups (disabled if empty)
+		dnsPort           string                // Default DNS port for SRV checks
+		dnsTimeout        time.Duration         // Default timeout in seconds for DNS fetching
+		dnsTLSConfig      *tls.Config           // TLS configuration for DoH and DoT (system roots if nil)
+		httpTimeout       time.Duration         // Default timeout in seconds for GET requests
+		interceptors      []Interceptor         // Interceptors that run around every HTTP request
+		meterProvider     metric.MeterProvider  // OpenTelemetry meter provider (global provider if nil)
+		nameServer        string                // Default name server for DNS checks
+		nameServerNetwork string                // Default name server network
+		requestTracing    bool                  // If enabled, it will trace the request timing
+		retryCount        int                   // Default retry count for HTTP requests
+		sslDeadline       time.Duration         // Default timeout in seconds for SSL deadline
+		sslTimeout        time.Duration         // Default timeout in seconds for SSL timeout
+		userAgent         string                // User agent for all outgoing requests
+		network           Network               // The bitcoin network to operate on
+		rateLimit         *RateLimitPolicy      // Per-host rate limit policy (disabled if nil)
+		srvCacheTTL       time.Duration         // Time to keep SRV records in the cache
+		srvPolicy         SRVPolicy             // Policy used for validating SRV records
+		tlsRootCAs        *x509.CertPool        // Root certificates for the TLS checks (system roots if nil)
+		tracerProvider    trace.TracerProvider  // OpenTelemetry tracer provider (global provider if nil)
+		verifyResolutions bool                  // If enabled, the output signature of ResolveAddress responses is verified
 	}
 )
 
@@ -63,8 +89,31 @@ func NewClient(opts ...ClientOps) (ClientInterface, error) {
 		}
 	}
 
-	// Set the resolver
-	if client.resolver == nil {
+	// Set the circuit breaker and rate limiter
+	if client.options.circuitBreaker != nil {
+		client.breaker = newCircuitBreaker(*client.options.circuitBreaker)
+	}
+	if client.options.rateLimit != nil {
+		client.limiter = newRateLimiter(*client.options.rateLimit)
+	}
+
+	// Set the telemetry
+	if client.telemetry, err = newTelemetry(client.options.tracerProvider, client.options.meterProvider); err != nil {
+		return nil, err
+	}
+	if client.breaker != nil {
+		if err = client.telemetry.observeCircuits(client.breaker); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the resolver (DoH, DoT or classic DNS to the name server)
+	switch {
+	case len(client.options.dnsOverHTTPS) > 0:
+		client.resolver = NewDoHResolver(client.options.dnsOverHTTPS, client.options.dnsTLSConfig, client.options.dnsTimeout)
+	case len(client.options.dnsOverTLS) > 0:
+		client.resolver = NewDoTResolver(client.options.dnsOverTLS, client.options.dnsTLSConfig, client.options.dnsTimeout)
+	default:
 		r := client.defaultResolver()
 		client.resolver = &r
 	}
@@ -101,59 +150,44 @@ func (c *Client) GetResolver() interfaces.DNSResolver {
 }
 
 // getRequest is a standard GET request for all outgoing HTTP requests
-func (c *Client) getRequest(requestURL string) (response StandardResponse, err error) {
-	// Set the user agent
-	req := c.httpClient.R().SetHeader("User-Agent", c.options.userAgent)
-
-	// Enable tracing
-	if c.options.requestTracing {
-		req.EnableTrace()
-	}
-
-	// Fire the request
-	var resp *resty.Response
-	if resp, err = req.Get(requestURL); err != nil {
-		return response, err
-	}
+//
+// The context is attached to the request and carries cancellation and deadlines
+func (c *Client) getRequest(ctx context.Context, requestURL string) (response StandardResponse, err error) {
+	return c.getRequestWithHeaders(ctx, requestURL, nil)
+}
 
-	// Tracing enabled?
-	if c.options.requestTracing {
-		response.Tracing = resp.Request.TraceInfo()
+// getRequestWithHeaders is a standard GET request with additional request headers
+func (c *Client) getRequestWithHeaders(ctx context.Context, requestURL string,
+	headers map[string]string,
+) (response StandardResponse, err error) {
+	req := c.newRequest(http.MethodGet, requestURL)
+	for key, value := range headers {
+		req.Header.Set(key, value)
 	}
-
-	// Set the status code
-	response.StatusCode = resp.StatusCode()
-
-	// Set the body
-	response.Body = resp.Body()
-	return response, err
+	return c.doRequest(ctx, req)
 }
 
 // postRequest is a standard POST request for all outgoing HTTP requests
-func (c *Client) postRequest(requestURL string, data interface{}) (response StandardResponse, err error) {
-	// Set the user agent
-	req := c.httpClient.R().SetBody(data).SetHeader("User-Agent", c.options.userAgent)
-
-	// Enable tracing
-	if c.options.requestTracing {
-		req.EnableTrace()
-	}
+//
+// The context is attached to the request and carries cancellation and deadlines
+func (c *Client) postRequest(ctx context.Context, requestURL string, data interface{}) (response StandardResponse, err error) {
+	req := c.newRequest(http.MethodPost, requestURL)
 
-	// Fire the request
-	var resp *resty.Response
-	if resp, err = req.Post(requestURL); err != nil {
+	// Encode the body
+	if req.Body, err = json.Marshal(data); err != nil {
 		return response, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.doRequest(ctx, req)
+}
 
-	// Tracing enabled?
-	if c.options.requestTracing {
-		response.Tracing = resp.Request.TraceInfo()
+// newRequest will create a new request with the default headers (user agent)
+func (c *Client) newRequest(method, requestURL string) *Request {
+	req := &Request{
+		Header: make(http.Header),
+		Method: method,
+		URL:    requestURL,
 	}
-
-	// Set the status code
-	response.StatusCode = resp.StatusCode()
-
-	// Set the body
-	response.Body = resp.Body()
-	return response, err
+	req.Header.Set("User-Agent", c.options.userAgent)
+	return req
 }
@@ -1,9 +1,14 @@
 package paymail
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/miekg/dns"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-paymail/interfaces"
 )
@@ -18,6 +23,7 @@ type ClientOps func(c *ClientOptions)
 func defaultClientOptions() (opts *ClientOptions, err error) {
 	// Set the default options
 	opts = &ClientOptions{
+		cacheTTL:          defaultCacheTTL,
 		dnsPort:           defaultDNSPort,
 		dnsTimeout:        defaultDNSTimeout,
 		httpTimeout:       defaultHTTPTimeout,
@@ -29,6 +35,8 @@ func defaultClientOptions() (opts *ClientOptions, err error) {
 		sslTimeout:        defaultSSLTimeout,
 		userAgent:         defaultUserAgent,
 		network:           Network(defaultNetwork),
+		srvCacheTTL:       defaultSRVCacheTTL,
+		srvPolicy:         DefaultSRVPolicy(),
 	}
 
 	// Load the default BRFC specs
@@ -84,6 +92,43 @@ func WithNameServerNetwork(network string) ClientOps {
 	}
 }
 
+// WithDNSOverHTTPS will resolve SRV and host records using DNS-over-HTTPS (RFC 8484).
+// The endpoint is the url of the DoH server (IE: https://dns.google/dns-query).
+// Default is classic DNS to the name server (see WithNameServer).
+func WithDNSOverHTTPS(endpoint string) ClientOps {
+	return func(c *ClientOptions) {
+		c.dnsOverHTTPS = endpoint
+		c.dnsOverTLS = ""
+	}
+}
+
+// WithDNSOverTLS will resolve SRV and host records using DNS-over-TLS (RFC 7858).
+// The address is the host of the DoT server (IE: dns.google), the default port is 853.
+// Default is classic DNS to the name server (see WithNameServer).
+func WithDNSOverTLS(address string) ClientOps {
+	return func(c *ClientOptions) {
+		c.dnsOverTLS = address
+		c.dnsOverHTTPS = ""
+	}
+}
+
+// WithDNSTLSConfig will set the TLS configuration used for DNS-over-HTTPS and DNS-over-TLS.
+// Default is the system configuration (system root certificates).
+func WithDNSTLSConfig(config *tls.Config) ClientOps {
+	return func(c *ClientOptions) {
+		c.dnsTLSConfig = config
+	}
+}
+
+// WithDNSSECTrustAnchors will overwrite the trust anchors used for the DNSSEC chain-of-trust validation.
+// The anchors are the DS records of the root zone (or of the zone the validation should stop at).
+// Default is DefaultDNSSECTrustAnchors() (the IANA root zone keys).
+func WithDNSSECTrustAnchors(anchors ...*dns.DS) ClientOps {
+	return func(c *ClientOptions) {
+		c.dnssecAnchors = anchors
+	}
+}
+
 // WithRequestTracing will enable tracing.
 // Tracing is disabled by default.
 func WithRequestTracing() ClientOps {
@@ -92,6 +137,16 @@ func WithRequestTracing() ClientOps {
 	}
 }
 
+// WithResolutionVerification will verify the output signature of ResolveAddress responses
+// using the receiver's PubKey (discovered from the capabilities and the PKI request).
+// Responses with a missing or invalid signature are rejected.
+// Verification is disabled by default.
+func WithResolutionVerification() ClientOps {
+	return func(c *ClientOptions) {
+		c.verifyResolutions = true
+	}
+}
+
 // WithRetryCount will overwrite the default retry count for http requests.
 // Default retries is 2.
 func WithRetryCount(retries int) ClientOps {
@@ -116,6 +171,14 @@ func WithSSLDeadline(timeout time.Duration) ClientOps {
 	}
 }
 
+// WithTLSRootCAs will overwrite the root certificates used by CheckSSL, CheckTLS and CheckPaymailTLS.
+// Default is the system root certificates.
+func WithTLSRootCAs(roots *x509.CertPool) ClientOps {
+	return func(c *ClientOptions) {
+		c.tlsRootCAs = roots
+	}
+}
+
 // WithUserAgent will overwrite the default useragent.
 // Default is go-paymail + version.
 func WithUserAgent(userAgent string) ClientOps {
@@ -132,6 +195,93 @@ func WithNetwork(n Network) ClientOps {
 	}
 }
 
+// WithCache will enable caching of capabilities, PKI and SRV responses.
+// If no cache is supplied, an in-memory LRU cache is used.
+// Default is no caching.
+func WithCache(cache interfaces.Cache) ClientOps {
+	return func(c *ClientOptions) {
+		if cache == nil {
+			cache = NewMemoryCache(defaultCacheSize)
+		}
+		c.cache = cache
+	}
+}
+
+// WithCacheTTL will overwrite the default freshness of cached responses
+// when the paymail host does not send any cache headers.
+// Default is 5 minutes.
+func WithCacheTTL(ttl time.Duration) ClientOps {
+	return func(c *ClientOptions) {
+		c.cacheTTL = ttl
+	}
+}
+
+// WithSRVCacheTTL will overwrite the default time SRV records are cached.
+// Default is 5 minutes.
+func WithSRVCacheTTL(ttl time.Duration) ClientOps {
+	return func(c *ClientOptions) {
+		c.srvCacheTTL = ttl
+	}
+}
+
+// WithSRVPolicy will overwrite the policy used for validating SRV records.
+// Default is DefaultSRVPolicy() (any port, priority and weight, the target must resolve).
+func WithSRVPolicy(policy SRVPolicy) ClientOps {
+	return func(c *ClientOptions) {
+		c.srvPolicy = policy
+	}
+}
+
+// WithInterceptors will add interceptors that run around every paymail HTTP request.
+// Interceptors run in the order they are given (and after any previously added).
+// Default is no interceptors.
+func WithInterceptors(interceptors ...Interceptor) ClientOps {
+	return func(c *ClientOptions) {
+		for _, interceptor := range interceptors {
+			if interceptor != nil {
+				c.interceptors = append(c.interceptors, interceptor)
+			}
+		}
+	}
+}
+
+// WithCircuitBreaker will enable a circuit breaker per paymail host.
+// After consecutive failures, requests to the host fail fast with ErrCircuitOpen.
+// Zero values in the policy use the DefaultCircuitBreakerPolicy() values.
+// Default is no circuit breaker.
+func WithCircuitBreaker(policy CircuitBreakerPolicy) ClientOps {
+	return func(c *ClientOptions) {
+		c.circuitBreaker = &policy
+	}
+}
+
+// WithRateLimit will enable a token bucket rate limiter per paymail host.
+// Requests wait for a token, or fail with ErrRateLimited if it is not available before the context deadline.
+// Default is no rate limit.
+func WithRateLimit(policy RateLimitPolicy) ClientOps {
+	return func(c *ClientOptions) {
+		if policy.RequestsPerSecond > 0 {
+			c.rateLimit = &policy
+		}
+	}
+}
+
+// WithTracerProvider will set the OpenTelemetry tracer provider used for client spans.
+// Default is the global tracer provider (otel.GetTracerProvider()).
+func WithTracerProvider(provider trace.TracerProvider) ClientOps {
+	return func(c *ClientOptions) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider will set the OpenTelemetry meter provider used for client metrics.
+// Default is the global meter provider (otel.GetMeterProvider()).
+func WithMeterProvider(provider metric.MeterProvider) ClientOps {
+	return func(c *ClientOptions) {
+		c.meterProvider = provider
+	}
+}
+
 // WithCustomResolver will allow you to supply a custom  dns resolver,
 // useful for testing etc.
 func (c *Client) WithCustomResolver(resolver interfaces.DNSResolver) ClientInterface {
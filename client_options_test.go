@@ -1,10 +1,16 @@
 package paymail
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Tests for client functional options (client_options.go)
@@ -28,6 +34,65 @@ func TestWithDNSTimeout(t *testing.T) {
 	assert.Equal(t, 30*time.Second, opts.dnsTimeout)
 }
 
+func TestWithDNSOverHTTPS(t *testing.T) {
+	t.Parallel()
+
+	opts := &ClientOptions{dnsOverTLS: "dns.google"}
+	WithDNSOverHTTPS("https://dns.google/dns-query")(opts)
+
+	assert.Equal(t, "https://dns.google/dns-query", opts.dnsOverHTTPS)
+	assert.Empty(t, opts.dnsOverTLS)
+}
+
+func TestWithDNSOverTLS(t *testing.T) {
+	t.Parallel()
+
+	opts := &ClientOptions{dnsOverHTTPS: "https://dns.google/dns-query"}
+	WithDNSOverTLS("dns.google")(opts)
+
+	assert.Equal(t, "dns.google", opts.dnsOverTLS)
+	assert.Empty(t, opts.dnsOverHTTPS)
+}
+
+func TestWithDNSTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	config := &tls.Config{MinVersion: tls.VersionTLS13}
+	opts := &ClientOptions{}
+	WithDNSTLSConfig(config)(opts)
+
+	assert.Equal(t, config, opts.dnsTLSConfig)
+}
+
+func TestWithDNSSECTrustAnchors(t *testing.T) {
+	t.Parallel()
+
+	anchors := DefaultDNSSECTrustAnchors()
+	opts := &ClientOptions{}
+	WithDNSSECTrustAnchors(anchors...)(opts)
+
+	assert.Equal(t, anchors, opts.dnssecAnchors)
+}
+
+func TestWithTLSRootCAs(t *testing.T) {
+	t.Parallel()
+
+	roots := x509.NewCertPool()
+	opts := &ClientOptions{}
+	WithTLSRootCAs(roots)(opts)
+
+	assert.Equal(t, roots, opts.tlsRootCAs)
+}
+
+func TestWithResolutionVerification(t *testing.T) {
+	t.Parallel()
+
+	opts := &ClientOptions{}
+	WithResolutionVerification()(opts)
+
+	assert.True(t, opts.verifyResolutions)
+}
+
 func TestWithBRFCSpecs(t *testing.T) {
 	t.Parallel()
 
@@ -142,6 +207,106 @@ func TestWithNetwork(t *testing.T) {
 	})
 }
 
+func TestWithCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default memory cache", func(t *testing.T) {
+		opts := &ClientOptions{}
+		WithCache(nil)(opts)
+
+		assert.IsType(t, &MemoryCache{}, opts.cache)
+	})
+
+	t.Run("custom cache", func(t *testing.T) {
+		cache := NewMemoryCache(10)
+		opts := &ClientOptions{}
+		WithCache(cache)(opts)
+
+		assert.Equal(t, cache, opts.cache)
+	})
+}
+
+func TestWithCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	opts := &ClientOptions{}
+	WithCacheTTL(time.Minute)(opts)
+
+	assert.Equal(t, time.Minute, opts.cacheTTL)
+}
+
+func TestWithSRVCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	opts := &ClientOptions{}
+	WithSRVCacheTTL(time.Hour)(opts)
+
+	assert.Equal(t, time.Hour, opts.srvCacheTTL)
+}
+
+func TestWithInterceptors(t *testing.T) {
+	t.Parallel()
+
+	interceptor := func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+		return next(ctx, req)
+	}
+
+	opts := &ClientOptions{}
+	WithInterceptors(interceptor, nil)(opts)
+	WithInterceptors(interceptor)(opts)
+
+	assert.Len(t, opts.interceptors, 2)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	opts := &ClientOptions{}
+	WithCircuitBreaker(DefaultCircuitBreakerPolicy())(opts)
+
+	require.NotNil(t, opts.circuitBreaker)
+	assert.Equal(t, DefaultCircuitBreakerPolicy(), *opts.circuitBreaker)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid policy", func(t *testing.T) {
+		opts := &ClientOptions{}
+		WithRateLimit(RateLimitPolicy{Burst: 5, RequestsPerSecond: 10})(opts)
+
+		require.NotNil(t, opts.rateLimit)
+		assert.Equal(t, 5, opts.rateLimit.Burst)
+	})
+
+	t.Run("no requests per second", func(t *testing.T) {
+		opts := &ClientOptions{}
+		WithRateLimit(RateLimitPolicy{Burst: 5})(opts)
+
+		assert.Nil(t, opts.rateLimit)
+	})
+}
+
+func TestWithTracerProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := sdktrace.NewTracerProvider()
+	opts := &ClientOptions{}
+	WithTracerProvider(provider)(opts)
+
+	assert.Equal(t, provider, opts.tracerProvider)
+}
+
+func TestWithMeterProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := sdkmetric.NewMeterProvider()
+	opts := &ClientOptions{}
+	WithMeterProvider(provider)(opts)
+
+	assert.Equal(t, provider, opts.meterProvider)
+}
+
 func TestClientOptions_ChainedOptions(t *testing.T) {
 	t.Parallel()
 
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -8,17 +9,26 @@ import (
 
 // Defaults for paymail functions
 const (
+	defaultCacheSize         = 1000                     // Default number of entries in the in-memory cache
+	defaultCacheTTL          = 5 * time.Minute          // Default freshness of cached responses (without cache headers)
 	defaultDNSPort           = "53"                     // Default port for DNS / NameServer checks
 	defaultDNSTimeout        = 5 * time.Second          // In seconds
 	defaultHTTPTimeout       = 20 * time.Second         // Default timeout for all GET requests in seconds
 	defaultNameServer        = "8.8.8.8"                // Default DNS NameServer
 	defaultNameServerNetwork = "udp"                    // Default for NS dialer
+	defaultPayerFeeRate      = 100                      // Default fee rate for payments (satoshis per kilobyte)
 	defaultRetryCount        = 2                        // Default retry count for HTTP requests
 	defaultSSLDeadline       = 10 * time.Second         // Default deadline in seconds
+	defaultSRVCacheTTL       = 5 * time.Minute          // Default time to keep SRV records in the cache
 	defaultSSLTimeout        = 10 * time.Second         // Default timeout in seconds
 	defaultUserAgent         = "go-paymail: " + version // Default user agent
 	defaultNetwork           = byte(Mainnet)            // Default network
 	version                  = "v0.9.3"                 // Go-Paymail version
+
+	defaultCircuitFailureThreshold = 5                // Default consecutive failures before the circuit opens
+	defaultCircuitOpenTimeout      = 30 * time.Second // Default time the circuit stays open
+
+	p2pkhUnlockingScriptLength = 106 // Length of a P2PKH unlocking script (signature + compressed public key)
 )
 
 // Public defaults for paymail specs
@@ -50,6 +60,7 @@ const (
 // StandardResponse is the standard fields returned on all responses
 type StandardResponse struct {
 	Body       []byte          `json:"-"` // Body of the response request
+	Header     http.Header     `json:"-"` // Headers returned on the request
 	StatusCode int             `json:"-"` // Status code returned on the request
 	Tracing    resty.TraceInfo `json:"-"` // Trace information if enabled on the request
 }
@@ -0,0 +1,260 @@
+package paymail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// ErrDNSBadResponse is returned when the DoH or DoT server returns an invalid response
+var ErrDNSBadResponse = errors.New("bad response from dns server")
+
+const (
+	defaultDoTPort        = "853"                     // Default port for DNS-over-TLS (RFC 7858)
+	dnsMessageContentType = "application/dns-message" // Content type of DNS-over-HTTPS messages (RFC 8484)
+	maxDNSMessageSize     = 65535                     // Maximum size of a DNS message
+)
+
+// dnsExchange sends a DNS query and returns the response
+type dnsExchange func(ctx context.Context, query *dns.Msg) (*dns.Msg, error)
+
+// DoHResolver is a DNS resolver using DNS-over-HTTPS (RFC 8484 wire format)
+//
+// It implements interfaces.DNSResolver (use it with WithCustomResolver or WithDNSOverHTTPS)
+type DoHResolver struct {
+	endpoint   string       // The DoH endpoint (IE: https://dns.google/dns-query)
+	httpClient *http.Client // HTTP client for the queries
+}
+
+// NewDoHResolver will return a DNS-over-HTTPS resolver for the endpoint
+//
+// If tlsConfig is nil, the system roots are used
+func NewDoHResolver(endpoint string, tlsConfig *tls.Config, timeout time.Duration) *DoHResolver {
+	return &DoHResolver{
+		endpoint: endpoint,
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				ForceAttemptHTTP2: true,
+				Proxy:             http.ProxyFromEnvironment,
+				TLSClientConfig:   tlsConfig,
+			},
+		},
+	}
+}
+
+// LookupHost will return the addresses (A and AAAA records) of the host
+func (r *DoHResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return lookupHost(ctx, r.exchange, host)
+}
+
+// LookupIPAddr will return the ip addresses (A and AAAA records) of the host
+func (r *DoHResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return lookupIPAddr(ctx, r.exchange, host)
+}
+
+// LookupSRV will return the SRV records of the service, protocol and name
+func (r *DoHResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return lookupSRV(ctx, r.exchange, service, proto, name)
+}
+
+// exchange will POST the query to the DoH endpoint
+func (r *DoHResolver) exchange(ctx context.Context, query *dns.Msg) (*dns.Msg, error) {
+	// The ID should be zero for HTTP caching (RFC 8484, section 4.1)
+	query.Id = 0
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(packed)); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", dnsMessageContentType)
+	req.Header.Set("Content-Type", dnsMessageContentType)
+
+	var resp *http.Response
+	if resp, err = r.httpClient.Do(req); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status code %d", ErrDNSBadResponse, resp.StatusCode)
+	} else if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, dnsMessageContentType) {
+		return nil, fmt.Errorf("%w: content type %q", ErrDNSBadResponse, contentType)
+	}
+
+	var body []byte
+	if body, err = io.ReadAll(io.LimitReader(resp.Body, maxDNSMessageSize)); err != nil {
+		return nil, err
+	}
+
+	response := new(dns.Msg)
+	if err = response.Unpack(body); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDNSBadResponse, err)
+	}
+	return response, nil
+}
+
+// DoTResolver is a DNS resolver using DNS-over-TLS (RFC 7858)
+//
+// It implements interfaces.DNSResolver (use it with WithCustomResolver or WithDNSOverTLS)
+type DoTResolver struct {
+	address string      // The DoT server (host:port)
+	client  *dns.Client // DNS client for the queries
+}
+
+// NewDoTResolver will return a DNS-over-TLS resolver for the server address (host or host:port)
+//
+// If the port is missing, 853 is used. If tlsConfig is nil, the system roots are used
+func NewDoTResolver(address string, tlsConfig *tls.Config, timeout time.Duration) *DoTResolver {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultDoTPort)
+	}
+	return &DoTResolver{
+		address: address,
+		client: &dns.Client{
+			Net:       "tcp-tls",
+			Timeout:   timeout,
+			TLSConfig: tlsConfig,
+		},
+	}
+}
+
+// LookupHost will return the addresses (A and AAAA records) of the host
+func (r *DoTResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return lookupHost(ctx, r.exchange, host)
+}
+
+// LookupIPAddr will return the ip addresses (A and AAAA records) of the host
+func (r *DoTResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return lookupIPAddr(ctx, r.exchange, host)
+}
+
+// LookupSRV will return the SRV records of the service, protocol and name
+func (r *DoTResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	return lookupSRV(ctx, r.exchange, service, proto, name)
+}
+
+// exchange will send the query to the DoT server
+func (r *DoTResolver) exchange(ctx context.Context, query *dns.Msg) (*dns.Msg, error) {
+	response, _, err := r.client.ExchangeContext(ctx, query, r.address)
+	return response, err
+}
+
+// dnsQuery will send a question and return the answers (a not found error for NXDOMAIN)
+//
+// Errors are *net.DNSError (same as net.Resolver), wrapping the exchange error or ErrDNSBadResponse
+func dnsQuery(ctx context.Context, exchange dnsExchange, name string, qType uint16) ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qType)
+
+	response, err := exchange(ctx, msg)
+	if err != nil {
+		return nil, &net.DNSError{Err: err.Error(), Name: name, IsTemporary: true, UnwrapErr: err}
+	}
+
+	switch response.Rcode {
+	case dns.RcodeSuccess:
+		return response.Answer, nil
+	case dns.RcodeNameError:
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return nil, &net.DNSError{
+		Err:         fmt.Sprintf("%s: %s", ErrDNSBadResponse.Error(), dns.RcodeToString[response.Rcode]),
+		IsTemporary: response.Rcode == dns.RcodeServerFailure,
+		Name:        name,
+		UnwrapErr:   ErrDNSBadResponse,
+	}
+}
+
+// lookupIPAddr will query the A and AAAA records of the host
+func lookupIPAddr(ctx context.Context, exchange dnsExchange, host string) ([]net.IPAddr, error) {
+	// Literal ip addresses are returned as-is (same as net.Resolver)
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+
+	var addresses []net.IPAddr
+	var lastErr error
+	for _, qType := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		answers, err := dnsQuery(ctx, exchange, host, qType)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for _, rr := range answers {
+			switch record := rr.(type) {
+			case *dns.A:
+				addresses = append(addresses, net.IPAddr{IP: record.A})
+			case *dns.AAAA:
+				addresses = append(addresses, net.IPAddr{IP: record.AAAA})
+			}
+		}
+	}
+
+	if len(addresses) > 0 {
+		return addresses, nil
+	} else if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// lookupHost will query the A and AAAA records of the host
+func lookupHost(ctx context.Context, exchange dnsExchange, host string) ([]string, error) {
+	ipAddresses, err := lookupIPAddr(ctx, exchange, host)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(ipAddresses))
+	for _, ip := range ipAddresses {
+		addresses = append(addresses, ip.IP.String())
+	}
+	return addresses, nil
+}
+
+// lookupSRV will query the SRV records of _service._proto.name (same as net.Resolver)
+func lookupSRV(ctx context.Context, exchange dnsExchange, service, proto, name string) (string, []*net.SRV, error) {
+	target := name
+	if len(service) > 0 || len(proto) > 0 {
+		target = "_" + service + "._" + proto + "." + name
+	}
+
+	answers, err := dnsQuery(ctx, exchange, target, dns.TypeSRV)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var cname string
+	var records []*net.SRV
+	for _, rr := range answers {
+		if record, ok := rr.(*dns.SRV); ok {
+			cname = record.Hdr.Name
+			records = append(records, &net.SRV{
+				Port:     record.Port,
+				Priority: record.Priority,
+				Target:   record.Target,
+				Weight:   record.Weight,
+			})
+		}
+	}
+
+	if len(records) == 0 {
+		return "", nil, &net.DNSError{Err: "no such host", Name: target, IsNotFound: true}
+	}
+	return cname, records, nil
+}
@@ -0,0 +1,340 @@
+package paymail
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/interfaces"
+)
+
+// newTestTLSConfigs will return a server and a client TLS configuration (self-signed certificate for 127.0.0.1)
+func newTestTLSConfigs(tb testing.TB) (serverConfig, clientConfig *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	template := &x509.Certificate{
+		BasicConstraintsValid: true,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		NotAfter:              time.Now().Add(time.Hour),
+		NotBefore:             time.Now().Add(-time.Hour),
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(tb, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(tb, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate)
+	serverConfig = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key, Leaf: certificate}},
+		MinVersion:   tls.VersionTLS12,
+	}
+	clientConfig = &tls.Config{MinVersion: tls.VersionTLS12, RootCAs: roots}
+	return serverConfig, clientConfig
+}
+
+// testDNSResponse will answer the query from the test records (stand-in for a recursive resolver)
+func testDNSResponse(query *dns.Msg) *dns.Msg {
+	response := new(dns.Msg)
+	response.SetReply(query)
+
+	question := query.Question[0]
+	header := dns.RR_Header{Name: question.Name, Rrtype: question.Qtype, Class: dns.ClassINET, Ttl: 300}
+	switch question.Name {
+	case "_" + DefaultServiceName + "._" + DefaultProtocol + "." + testDomain + ".":
+		if question.Qtype == dns.TypeSRV {
+			response.Answer = append(response.Answer, &dns.SRV{
+				Hdr: header, Port: DefaultPort, Priority: DefaultPriority, Target: "www." + testDomain + ".", Weight: DefaultWeight,
+			})
+		}
+	case "www." + testDomain + ".":
+		switch question.Qtype {
+		case dns.TypeA:
+			response.Answer = append(response.Answer, &dns.A{Hdr: header, A: net.ParseIP("44.225.125.175")})
+		case dns.TypeAAAA:
+			response.Answer = append(response.Answer, &dns.AAAA{Hdr: header, AAAA: net.ParseIP("2001:db8::1")})
+		}
+	case "_" + DefaultServiceName + "._" + DefaultProtocol + ".servfail.com.", "servfail.com.":
+		response.Rcode = dns.RcodeServerFailure
+	default:
+		response.Rcode = dns.RcodeNameError
+	}
+	return response
+}
+
+// newTestDoHServer will start a local DNS-over-HTTPS server (/dns-query) and return the client TLS configuration
+func newTestDoHServer(tb testing.TB) (*httptest.Server, *tls.Config) {
+	serverConfig, clientConfig := newTestTLSConfigs(tb)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dns-query", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		query := new(dns.Msg)
+		if err != nil || r.Header.Get("Content-Type") != dnsMessageContentType || query.Unpack(body) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		packed, _ := testDNSResponse(query).Pack()
+		w.Header().Set("Content-Type", dnsMessageContentType)
+		_, _ = w.Write(packed)
+	})
+	mux.HandleFunc("/not-dns", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html></html>"))
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	server.TLS = serverConfig
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+	return server, clientConfig
+}
+
+// newTestDoTServer will start a local DNS-over-TLS server and return its address and the client TLS configuration
+func newTestDoTServer(tb testing.TB) (string, *tls.Config) {
+	serverConfig, clientConfig := newTestTLSConfigs(tb)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(tb, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			_ = w.WriteMsg(testDNSResponse(r))
+		}),
+		Listener:          listener,
+		Net:               "tcp-tls",
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	tb.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+	return listener.Addr().String(), clientConfig
+}
+
+// testEncryptedResolver will test the lookups of a DoH or DoT resolver (against the test records)
+func testEncryptedResolver(t *testing.T, resolver interfaces.DNSResolver) {
+	ctx := context.Background()
+
+	t.Run("lookup srv", func(t *testing.T) {
+		cname, records, err := resolver.LookupSRV(ctx, DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "_"+DefaultServiceName+"._"+DefaultProtocol+"."+testDomain+".", cname)
+		require.Len(t, records, 1)
+		assert.Equal(t, &net.SRV{Port: DefaultPort, Priority: DefaultPriority, Target: "www." + testDomain + ".", Weight: DefaultWeight}, records[0])
+	})
+
+	t.Run("lookup host", func(t *testing.T) {
+		addresses, err := resolver.LookupHost(ctx, "www."+testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"44.225.125.175", "2001:db8::1"}, addresses)
+	})
+
+	t.Run("lookup ip address", func(t *testing.T) {
+		addresses, err := resolver.LookupIPAddr(ctx, "www."+testDomain)
+		require.NoError(t, err)
+		require.Len(t, addresses, 2)
+		assert.True(t, addresses[0].IP.Equal(net.ParseIP("44.225.125.175")))
+
+		addresses, err = resolver.LookupIPAddr(ctx, "127.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, addresses[0].IP.Equal(net.ParseIP("127.0.0.1")))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := resolver.LookupSRV(ctx, DefaultServiceName, DefaultProtocol, "unknown.com")
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+		assert.True(t, dnsErr.IsNotFound)
+
+		// The name exists, but has no SRV records
+		_, _, err = resolver.LookupSRV(ctx, "", "", "www."+testDomain)
+		require.ErrorAs(t, err, &dnsErr)
+		assert.True(t, dnsErr.IsNotFound)
+
+		_, err = resolver.LookupHost(ctx, "unknown.com")
+		require.ErrorAs(t, err, &dnsErr)
+		assert.True(t, dnsErr.IsNotFound)
+	})
+
+	t.Run("server failure", func(t *testing.T) {
+		_, _, err := resolver.LookupSRV(ctx, DefaultServiceName, DefaultProtocol, "servfail.com")
+		require.ErrorIs(t, err, ErrDNSBadResponse)
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+		assert.True(t, dnsErr.IsTemporary)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _, err := resolver.LookupSRV(canceled, DefaultServiceName, DefaultProtocol, testDomain)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestDoHResolver will test the DNS-over-HTTPS resolver
+func TestDoHResolver(t *testing.T) {
+	t.Parallel()
+
+	server, tlsConfig := newTestDoHServer(t)
+	testEncryptedResolver(t, NewDoHResolver(server.URL+"/dns-query", tlsConfig, 5*time.Second))
+
+	t.Run("bad status code", func(t *testing.T) {
+		resolver := NewDoHResolver(server.URL+"/unknown", tlsConfig, 5*time.Second)
+		_, err := resolver.LookupHost(context.Background(), "www."+testDomain)
+		require.ErrorIs(t, err, ErrDNSBadResponse)
+	})
+
+	t.Run("bad content type", func(t *testing.T) {
+		resolver := NewDoHResolver(server.URL+"/not-dns", tlsConfig, 5*time.Second)
+		_, err := resolver.LookupHost(context.Background(), "www."+testDomain)
+		require.ErrorIs(t, err, ErrDNSBadResponse)
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		resolver := NewDoHResolver(server.URL+"/dns-query", nil, 5*time.Second)
+		_, err := resolver.LookupHost(context.Background(), "www."+testDomain)
+		var certErr *tls.CertificateVerificationError
+		require.ErrorAs(t, err, &certErr)
+	})
+}
+
+// TestDoTResolver will test the DNS-over-TLS resolver
+func TestDoTResolver(t *testing.T) {
+	t.Parallel()
+
+	address, tlsConfig := newTestDoTServer(t)
+	testEncryptedResolver(t, NewDoTResolver(address, tlsConfig, 5*time.Second))
+
+	t.Run("default port", func(t *testing.T) {
+		resolver := NewDoTResolver("dns.google", nil, 5*time.Second)
+		assert.Equal(t, "dns.google:853", resolver.address)
+
+		resolver = NewDoTResolver("[2001:4860:4860::8888]:8853", nil, 5*time.Second)
+		assert.Equal(t, "[2001:4860:4860::8888]:8853", resolver.address)
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		resolver := NewDoTResolver(address, nil, 5*time.Second)
+		_, err := resolver.LookupHost(context.Background(), "www."+testDomain)
+		var certErr *tls.CertificateVerificationError
+		require.ErrorAs(t, err, &certErr)
+	})
+}
+
+// TestClient_EncryptedDNS will test the client resolving SRV records with DoH and DoT
+func TestClient_EncryptedDNS(t *testing.T) {
+	t.Parallel()
+
+	server, dohTLSConfig := newTestDoHServer(t)
+	address, dotTLSConfig := newTestDoTServer(t)
+
+	tests := []struct {
+		name     string
+		opts     []ClientOps
+		resolver interface{}
+	}{
+		{"dns over https", []ClientOps{WithDNSOverHTTPS(server.URL + "/dns-query"), WithDNSTLSConfig(dohTLSConfig)}, &DoHResolver{}},
+		{"dns over tls", []ClientOps{WithDNSOverTLS(address), WithDNSTLSConfig(dotTLSConfig)}, &DoTResolver{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := NewClient(test.opts...)
+			require.NoError(t, err)
+			assert.IsType(t, test.resolver, client.GetResolver())
+
+			var srv *net.SRV
+			srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+			require.NoError(t, err)
+			assert.Equal(t, "www."+testDomain, srv.Target)
+
+			require.NoError(t, client.ValidateSRVRecord(context.Background(), srv, DefaultPort, DefaultPriority, DefaultWeight))
+		})
+	}
+
+	t.Run("classic dns by default", func(t *testing.T) {
+		client, err := NewClient()
+		require.NoError(t, err)
+		assert.IsType(t, &net.Resolver{}, client.GetResolver())
+	})
+}
+
+// ExampleWithDNSOverHTTPS example using WithDNSOverHTTPS()
+//
+// See more examples in /examples/
+func ExampleWithDNSOverHTTPS() {
+	// Start a local DoH server (use a public endpoint, IE: https://dns.google/dns-query)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		query := new(dns.Msg)
+		_ = query.Unpack(body)
+		packed, _ := testDNSResponse(query).Pack()
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(packed)
+	}))
+	defer server.Close()
+
+	// Load the client (resolving with DNS-over-HTTPS)
+	client, err := NewClient(
+		WithDNSOverHTTPS(server.URL+"/dns-query"),
+		WithDNSTLSConfig(server.Client().Transport.(*http.Transport).TLSClientConfig),
+	)
+	if err != nil {
+		fmt.Printf("error loading client: %s", err.Error())
+		return
+	}
+
+	// Get the SRV record
+	var srv *net.SRV
+	if srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain); err != nil {
+		fmt.Printf("error getting SRV record: %s", err.Error())
+		return
+	}
+	fmt.Printf("found SRV record: %s:%d", srv.Target, srv.Port)
+	// Output:found SRV record: www.test.com:443
+}
+
+// BenchmarkDoHResolver_LookupSRV benchmarks the method LookupSRV() (local DoH server)
+func BenchmarkDoHResolver_LookupSRV(b *testing.B) {
+	server, tlsConfig := newTestDoHServer(b)
+	resolver := NewDoHResolver(server.URL+"/dns-query", tlsConfig, 5*time.Second)
+	for i := 0; i < b.N; i++ {
+		_, _, _ = resolver.LookupSRV(context.Background(), DefaultServiceName, DefaultProtocol, testDomain)
+	}
+}
+
+// BenchmarkDoTResolver_LookupSRV benchmarks the method LookupSRV() (local DoT server)
+func BenchmarkDoTResolver_LookupSRV(b *testing.B) {
+	address, tlsConfig := newTestDoTServer(b)
+	resolver := NewDoTResolver(address, tlsConfig, 5*time.Second)
+	for i := 0; i < b.N; i++ {
+		_, _, _ = resolver.LookupSRV(context.Background(), DefaultServiceName, DefaultProtocol, testDomain)
+	}
+}
@@ -1,8 +1,12 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestClient_CheckDNSSEC will test the method CheckDNSSEC()
@@ -42,6 +46,23 @@ func TestClient_CheckDNSSEC(t *testing.T) {
 	// todo: test results, test using mock interfaces for DNS resolving
 }
 
+// TestClient_CheckDNSSECCtx will test the method CheckDNSSECCtx()
+func TestClient_CheckDNSSECCtx(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestClient(t)
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		result := client.CheckDNSSECCtx(ctx, "google.com")
+		require.NotNil(t, result)
+		assert.Contains(t, result.ErrorMessage, "canceled")
+		assert.False(t, result.DNSSEC)
+	})
+}
+
 // ExampleClient_CheckDNSSEC example using CheckDNSSEC()
 //
 // See more examples in /examples/
@@ -0,0 +1,191 @@
+package paymail
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// denial is the signed proof that a name (NXDOMAIN) or the RRset of a name (NODATA) does not exist
+type denial struct {
+	nxDomain bool     // The name does not exist
+	optOut   bool     // The DS records are in an NSEC3 opt-out span (an unsigned delegation)
+	types    []uint16 // The types of the name (NODATA)
+}
+
+// proveDenial will return the signed NSEC or NSEC3 proof that the RRset of the name does not exist
+// (RFC 4035 section 5.4, RFC 5155 section 8), or nil if there is no valid proof in the response
+//
+// The records of a delegation (NS without SOA) only prove the DS records of the name, and the records
+// of a zone apex never do. An NSEC3 opt-out span is only a proof for DS records
+func (v *dnssecValidator) proveDenial(ctx context.Context, response *dns.Msg, name string, qType uint16) (*denial, error) {
+	nsecs, nsec3s, err := v.signedDenials(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+
+	name = dns.CanonicalName(name)
+	if response.Rcode == dns.RcodeNameError {
+		if nsecNameError(nsecs, name) || nsec3NameError(nsec3s, name) {
+			return &denial{nxDomain: true}, nil
+		}
+		return nil, nil
+	}
+
+	// The record of the name (it exists without the type)
+	var types []uint16
+	found := false
+	for _, nsec := range nsecs {
+		if dns.CanonicalName(nsec.Hdr.Name) == name {
+			types, found = nsec.TypeBitMap, true
+		}
+	}
+	for _, nsec3 := range nsec3s {
+		if nsec3.Match(name) {
+			types, found = nsec3.TypeBitMap, true
+		}
+	}
+
+	switch {
+	case !found && qType == dns.TypeDS:
+		if _, cover := nsec3ClosestEncloser(nsec3s, name); cover != nil && cover.Flags&1 == 1 {
+			return &denial{optOut: true}, nil
+		}
+		return nil, nil
+	case !found:
+		return nil, nil
+	case qType == dns.TypeDS && slices.Contains(types, dns.TypeSOA):
+		return nil, nil
+	case qType != dns.TypeDS && isDelegation(types):
+		return nil, nil
+	case slices.Contains(types, qType) || slices.Contains(types, dns.TypeCNAME):
+		return nil, bogusError("%s records for %s exist but were not returned", dns.TypeToString[qType], name)
+	}
+	return &denial{types: types}, nil
+}
+
+// signedDenials will return the NSEC and NSEC3 records of the response with a valid signature
+//
+// The records without signatures are ignored, an invalid signature fails the validation
+func (v *dnssecValidator) signedDenials(ctx context.Context, response *dns.Msg) ([]*dns.NSEC, []*dns.NSEC3, error) {
+	var nsecs []*dns.NSEC
+	var nsec3s []*dns.NSEC3
+	for _, rr := range response.Ns {
+		if rr.Header().Rrtype != dns.TypeNSEC && rr.Header().Rrtype != dns.TypeNSEC3 {
+			continue
+		}
+
+		rrset, sigs := rrsetOf(response.Ns, rr.Header().Name, rr.Header().Rrtype)
+		if len(sigs) == 0 {
+			continue
+		} else if err := v.verifySigned(ctx, dns.CanonicalName(rr.Header().Name), rr.Header().Rrtype, rrset, sigs); err != nil {
+			return nil, nil, err
+		}
+
+		switch denial := rr.(type) {
+		case *dns.NSEC:
+			nsecs = append(nsecs, denial)
+		case *dns.NSEC3:
+			nsec3s = append(nsec3s, denial)
+		}
+	}
+	return nsecs, nsec3s, nil
+}
+
+// nsecNameError will return true if the NSEC records prove that the name does not exist
+//
+// A record covers the name, and a record covers the wildcard of the closest encloser (the longest
+// ancestor of the name shared with the covering record)
+func nsecNameError(nsecs []*dns.NSEC, name string) bool {
+	for _, nsec := range nsecs {
+		if !nsecCovers(nsec, name) ||
+			(isDelegation(nsec.TypeBitMap) && dns.IsSubDomain(nsec.Hdr.Name, name)) {
+			continue
+		}
+
+		labels := dns.SplitDomainName(name)
+		shared := max(dns.CompareDomainName(name, nsec.Hdr.Name), dns.CompareDomainName(name, nsec.NextDomain))
+		wildcard := wildcardOf(dns.Fqdn(strings.Join(labels[len(labels)-shared:], ".")))
+		if slices.ContainsFunc(nsecs, func(other *dns.NSEC) bool { return nsecCovers(other, wildcard) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// nsecCovers will return true if the name is between the owner and the next name of the NSEC record
+//
+// The names are in the canonical order (RFC 4034 section 6.1), the next name of the last NSEC record
+// of a zone is its apex (the name must be in the zone)
+func nsecCovers(nsec *dns.NSEC, name string) bool {
+	owner, next := nsec.Hdr.Name, nsec.NextDomain
+	if canonicalCompare(owner, next) < 0 {
+		return canonicalCompare(owner, name) < 0 && canonicalCompare(name, next) < 0
+	}
+	return dns.IsSubDomain(next, name) && (canonicalCompare(owner, name) < 0 || canonicalCompare(name, next) < 0)
+}
+
+// canonicalCompare will compare the names in the canonical order (the lowercase labels from the right)
+func canonicalCompare(a, b string) int {
+	aLabels, bLabels := dns.SplitDomainName(dns.CanonicalName(a)), dns.SplitDomainName(dns.CanonicalName(b))
+	slices.Reverse(aLabels)
+	slices.Reverse(bLabels)
+	return slices.Compare(aLabels, bLabels)
+}
+
+// nsec3NameError will return true if the NSEC3 records prove that the name does not exist
+//
+// The closest encloser proof (RFC 5155 section 8.4), and a record covers the wildcard of the closest encloser
+func nsec3NameError(nsec3s []*dns.NSEC3, name string) bool {
+	encloser, cover := nsec3ClosestEncloser(nsec3s, name)
+	if cover == nil {
+		return false
+	}
+	wildcard := wildcardOf(encloser)
+	return slices.ContainsFunc(nsec3s, func(nsec3 *dns.NSEC3) bool { return nsec3Covers(nsec3, wildcard) })
+}
+
+// nsec3ClosestEncloser will return the closest encloser of the name and the record covering the next closer
+// name (RFC 5155 section 8.3), the record is nil if there is no closest encloser proof
+//
+// The closest encloser is the longest ancestor of the name matching a record (that is not a delegation)
+func nsec3ClosestEncloser(nsec3s []*dns.NSEC3, name string) (string, *dns.NSEC3) {
+	labels := dns.SplitDomainName(name)
+	for i := 1; i <= len(labels); i++ {
+		encloser := dns.Fqdn(strings.Join(labels[i:], "."))
+		if !slices.ContainsFunc(nsec3s, func(nsec3 *dns.NSEC3) bool {
+			return nsec3.Match(encloser) && !isDelegation(nsec3.TypeBitMap)
+		}) {
+			continue
+		}
+
+		nextCloser := dns.Fqdn(strings.Join(labels[i-1:], "."))
+		for _, nsec3 := range nsec3s {
+			if nsec3Covers(nsec3, nextCloser) {
+				return encloser, nsec3
+			}
+		}
+		return "", nil
+	}
+	return "", nil
+}
+
+// nsec3Covers will return true if the hash of the name is between the owner and the next hash of the record
+func nsec3Covers(nsec3 *dns.NSEC3, name string) bool {
+	return !nsec3.Match(name) && nsec3.Cover(name)
+}
+
+// isDelegation will return true if the types are the ones of a delegation (NS records without SOA)
+func isDelegation(types []uint16) bool {
+	return slices.Contains(types, dns.TypeNS) && !slices.Contains(types, dns.TypeSOA)
+}
+
+// wildcardOf will return the wildcard name of the encloser
+func wildcardOf(encloser string) string {
+	if encloser == "." {
+		return "*."
+	}
+	return "*." + encloser
+}
@@ -0,0 +1,156 @@
+package paymail
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNSECs will parse the NSEC records
+func testNSECs(tb testing.TB, records ...string) []*dns.NSEC {
+	nsecs := make([]*dns.NSEC, 0, len(records))
+	for _, record := range records {
+		nsec, ok := testRR(tb, record).(*dns.NSEC)
+		require.True(tb, ok)
+		nsecs = append(nsecs, nsec)
+	}
+	return nsecs
+}
+
+// newTestNSEC3Chain will return the NSEC3 records of the names of the zone (each record links to the next hash)
+func newTestNSEC3Chain(zone string, flags uint8, names map[string][]uint16) []*dns.NSEC3 {
+	hashes := make(map[string]string, len(names))
+	for name := range names {
+		hashes[dns.HashName(name, dns.SHA1, 0, "")] = name
+	}
+	sorted := make([]string, 0, len(hashes))
+	for hash := range hashes {
+		sorted = append(sorted, hash)
+	}
+	slices.Sort(sorted)
+
+	records := make([]*dns.NSEC3, 0, len(sorted))
+	for i, hash := range sorted {
+		records = append(records, &dns.NSEC3{
+			Hdr:        dns.RR_Header{Name: hash + "." + zone, Rrtype: dns.TypeNSEC3, Class: dns.ClassINET, Ttl: 3600},
+			Hash:       dns.SHA1,
+			Flags:      flags,
+			HashLength: 20,
+			NextDomain: sorted[(i+1)%len(sorted)],
+			TypeBitMap: names[hashes[hash]],
+		})
+	}
+	return records
+}
+
+// Test_nsecCovers will test the method nsecCovers()
+func Test_nsecCovers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		nsec   string
+		name   string
+		covers bool
+	}{
+		{"a.com. 3600 IN NSEC c.com. NS", "b.com.", true},
+		{"a.com. 3600 IN NSEC c.com. NS", "B.COM.", true},
+		{"a.com. 3600 IN NSEC c.com. NS", "x.b.com.", true},
+		{"a.com. 3600 IN NSEC c.com. NS", "a.com.", false},
+		{"a.com. 3600 IN NSEC c.com. NS", "c.com.", false},
+		{"a.com. 3600 IN NSEC c.com. NS", "d.com.", false},
+		{"a.com. 3600 IN NSEC c.com. NS", "com.", false},
+		{"com. 3600 IN NSEC a.com. NS", "_tcp.com.", true},
+		{"z.com. 3600 IN NSEC com. NS", "zz.com.", true},
+		{"z.com. 3600 IN NSEC com. NS", "b.com.", false},
+	}
+	for _, test := range tests {
+		t.Run(test.nsec+" "+test.name, func(t *testing.T) {
+			nsec, ok := testRR(t, test.nsec).(*dns.NSEC)
+			require.True(t, ok)
+			assert.Equal(t, test.covers, nsecCovers(nsec, test.name))
+		})
+	}
+}
+
+// Test_nsecNameError will test the method nsecNameError()
+func Test_nsecNameError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		nsecs       []string
+		name        string
+		nxDomain    bool
+	}{
+		{"name and wildcard covered", []string{"com. 3600 IN NSEC insecure.com. NS SOA RRSIG NSEC DNSKEY"}, "ghost.com.", true},
+		{"name not covered", []string{"insecure.com. 3600 IN NSEC j.com. NS RRSIG NSEC"}, "spoofed.com.", false},
+		{"wildcard not covered", []string{"b.com. 3600 IN NSEC d.com. A RRSIG NSEC"}, "c.com.", false},
+		{"wildcard covered by another record", []string{
+			"b.com. 3600 IN NSEC d.com. A RRSIG NSEC",
+			"com. 3600 IN NSEC b.com. NS SOA RRSIG NSEC DNSKEY",
+		}, "c.com.", true},
+		{"name below a delegation", []string{"a.com. 3600 IN NSEC c.com. NS RRSIG NSEC"}, "x.a.com.", false},
+		{"name outside the zone", []string{"z.evil.com. 3600 IN NSEC evil.com. A RRSIG NSEC"}, "zz.com.", false},
+	}
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assert.Equal(t, test.nxDomain, nsecNameError(testNSECs(t, test.nsecs...), test.name))
+		})
+	}
+}
+
+// Test_nsec3NameError will test the method nsec3NameError()
+func Test_nsec3NameError(t *testing.T) {
+	t.Parallel()
+
+	chain := newTestNSEC3Chain("com.", 0, map[string][]uint16{
+		"com.":   {dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeDNSKEY, dns.TypeNSEC3PARAM},
+		"a.com.": {dns.TypeA, dns.TypeRRSIG},
+		"d.com.": {dns.TypeNS},
+	})
+
+	t.Run("closest encloser proof", func(t *testing.T) {
+		assert.True(t, nsec3NameError(chain, "x.com."))
+	})
+
+	t.Run("existing name", func(t *testing.T) {
+		assert.False(t, nsec3NameError(chain, "a.com."))
+	})
+
+	t.Run("no record matching the closest encloser", func(t *testing.T) {
+		covering := slices.DeleteFunc(slices.Clone(chain), func(nsec3 *dns.NSEC3) bool { return nsec3.Match("com.") })
+		assert.False(t, nsec3NameError(covering, "x.com."))
+	})
+
+	t.Run("name below a delegation", func(t *testing.T) {
+		assert.False(t, nsec3NameError(chain, "x.d.com."))
+	})
+
+	t.Run("name outside the zone", func(t *testing.T) {
+		assert.False(t, nsec3NameError(chain, "x.net."))
+	})
+}
+
+// Test_nsec3ClosestEncloser will test the method nsec3ClosestEncloser()
+func Test_nsec3ClosestEncloser(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opt-out span", func(t *testing.T) {
+		chain := newTestNSEC3Chain("com.", 1, map[string][]uint16{
+			"com.": {dns.TypeNS, dns.TypeSOA, dns.TypeRRSIG, dns.TypeDNSKEY, dns.TypeNSEC3PARAM},
+		})
+
+		encloser, cover := nsec3ClosestEncloser(chain, "insecure.com.")
+		assert.Equal(t, "com.", encloser)
+		require.NotNil(t, cover)
+		assert.Equal(t, uint8(1), cover.Flags&1)
+	})
+
+	t.Run("no closest encloser", func(t *testing.T) {
+		_, cover := nsec3ClosestEncloser(newTestNSEC3Chain("com.", 0, map[string][]uint16{"a.com.": {dns.TypeA}}), "x.com.")
+		assert.Nil(t, cover)
+	})
+}
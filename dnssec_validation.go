@@ -0,0 +1,462 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"golang.org/x/net/idna"
+)
+
+// DNSSECVerdict is the result of the DNSSEC chain-of-trust validation (RFC 4035, section 4.3)
+type DNSSECVerdict string
+
+// DNSSEC verdicts
+const (
+	DNSSECSecure        DNSSECVerdict = "secure"        // Every record is signed with a chain of trust to the trust anchor
+	DNSSECInsecure      DNSSECVerdict = "insecure"      // There is a proven unsigned delegation (DNSSEC is not enabled)
+	DNSSECBogus         DNSSECVerdict = "bogus"         // A signature, key or proof is missing, invalid or expired
+	DNSSECIndeterminate DNSSECVerdict = "indeterminate" // The records could not be fetched (IE: name server not reachable)
+)
+
+// DNSSECValidation is the result of validating the SRV and A/AAAA records of a paymail domain
+type DNSSECValidation struct {
+	Chain   []string      `json:"chain,omitempty"`  // Zones validated from the trust anchor (IE: ".", "com.", "domain.com.")
+	Reason  string        `json:"reason,omitempty"` // Reason for a verdict that is not secure
+	Verdict DNSSECVerdict `json:"verdict"`          // The verdict (secure, insecure, bogus or indeterminate)
+}
+
+// rootTrustAnchors are the DS records of the IANA root zone key signing keys (KSK-2017 and KSK-2024)
+//
+// Source: https://data.iana.org/root-anchors/root-anchors.xml
+var rootTrustAnchors = []string{
+	". 172800 IN DS 20326 8 2 E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D",
+	". 172800 IN DS 38696 8 2 683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16",
+}
+
+// DefaultDNSSECTrustAnchors will return the DS records of the root zone (the default trust anchors)
+func DefaultDNSSECTrustAnchors() []*dns.DS {
+	anchors := make([]*dns.DS, 0, len(rootTrustAnchors))
+	for _, anchor := range rootTrustAnchors {
+		if rr, err := dns.NewRR(anchor); err == nil {
+			if ds, ok := rr.(*dns.DS); ok {
+				anchors = append(anchors, ds)
+			}
+		}
+	}
+	return anchors
+}
+
+// maxCNAMEChain is the maximum number of CNAME records followed while validating
+const maxCNAMEChain = 8
+
+// errNotZoneCut is when a name is not the apex of a zone (there is no delegation to validate)
+var errNotZoneCut = errors.New("not a zone cut")
+
+// dnssecError is a validation failure (with the verdict it leads to)
+type dnssecError struct {
+	reason  string
+	verdict DNSSECVerdict
+}
+
+// Error will return the reason
+func (e *dnssecError) Error() string {
+	return e.reason
+}
+
+// bogusError will return a bogus validation error
+func bogusError(format string, args ...interface{}) error {
+	return &dnssecError{reason: fmt.Sprintf(format, args...), verdict: DNSSECBogus}
+}
+
+// dnssecValidator validates RRsets up to the trust anchors (keys are cached per check)
+type dnssecValidator struct {
+	anchors  []*dns.DS
+	chain    []string
+	exchange dnsExchange
+	failures map[string]error         // Zone => validation error
+	keys     map[string][]*dns.DNSKEY // Zone => validated zone keys
+	now      time.Time
+}
+
+// ValidateDNSSEC will validate the DNSSEC chain of trust of a paymail domain
+//
+// The RRSIGs of the SRV record and the A/AAAA records of the SRV target (or the domain if there is no SRV)
+// are verified, and the DS => DNSKEY chain is walked up to the trust anchors (see WithDNSSECTrustAnchors)
+//
+// Records that do not exist must have a signed denial of existence (NSEC or NSEC3), unless they are
+// in an unsigned zone
+func (c *Client) ValidateDNSSEC(domain string) *DNSSECValidation {
+	return c.ValidateDNSSECCtx(context.Background(), domain)
+}
+
+// ValidateDNSSECCtx is the context-aware version of ValidateDNSSEC
+func (c *Client) ValidateDNSSECCtx(ctx context.Context, domain string) (validation *DNSSECValidation) {
+	ctx, end := c.startSpan(ctx, "ValidateDNSSEC", "", domain)
+	defer func() {
+		if validation.Verdict != DNSSECSecure {
+			end(fmt.Errorf("dnssec %s: %s", validation.Verdict, validation.Reason))
+			return
+		}
+		end(nil)
+	}()
+
+	var err error
+	if domain, err = idna.ToASCII(strings.TrimSuffix(strings.ToLower(domain), ".")); err != nil || len(domain) == 0 {
+		return &DNSSECValidation{Reason: ErrSRVInvalidDomainName.Error(), Verdict: DNSSECBogus}
+	}
+
+	v := &dnssecValidator{
+		anchors:  c.options.dnssecAnchors,
+		exchange: c.dnssecExchange(),
+		failures: make(map[string]error),
+		keys:     make(map[string][]*dns.DNSKEY),
+		now:      time.Now(),
+	}
+	if len(v.anchors) == 0 {
+		v.anchors = DefaultDNSSECTrustAnchors()
+	}
+
+	return v.validateDomain(ctx, domain)
+}
+
+// dnssecExchange will return the exchange used for the validation
+//
+// The DoH or DoT resolver is used if set (see WithDNSOverHTTPS), otherwise the name server
+func (c *Client) dnssecExchange() dnsExchange {
+	if resolver, ok := c.resolver.(interface {
+		exchange(ctx context.Context, query *dns.Msg) (*dns.Msg, error)
+	}); ok {
+		return resolver.exchange
+	}
+
+	address := net.JoinHostPort(c.options.nameServer, c.options.dnsPort)
+	return func(ctx context.Context, query *dns.Msg) (*dns.Msg, error) {
+		client := &dns.Client{Net: c.options.nameServerNetwork, Timeout: c.options.dnsTimeout}
+		response, _, err := client.ExchangeContext(ctx, query, address)
+		if err == nil && response.Truncated { // Retry over TCP (DNSKEY responses can be large)
+			client.Net = "tcp"
+			response, _, err = client.ExchangeContext(ctx, query, address)
+		}
+		return response, err
+	}
+}
+
+// validateDomain will validate the SRV record and the A/AAAA records of the domain
+func (v *dnssecValidator) validateDomain(ctx context.Context, domain string) *DNSSECValidation {
+	var errs []error
+	validated := 0
+
+	// The SRV record (hosts are the SRV targets, or the domain itself)
+	hosts := []string{domain}
+	srvName := "_" + DefaultServiceName + "._" + DefaultProtocol + "." + domain
+	records, err := v.validateRRset(ctx, srvName, dns.TypeSRV)
+	if err != nil {
+		errs = append(errs, err)
+	} else if len(records) > 0 {
+		validated++
+		hosts = hosts[:0]
+		for _, rr := range records {
+			if srv, ok := rr.(*dns.SRV); ok && srv.Target != "." {
+				hosts = append(hosts, srv.Target)
+			}
+		}
+	}
+
+	// The address records of the hosts
+	for _, host := range hosts {
+		for _, qType := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			if records, err = v.validateRRset(ctx, host, qType); err != nil {
+				errs = append(errs, err)
+			} else if len(records) > 0 {
+				validated++
+			}
+		}
+	}
+
+	// Nothing to validate, check the keys of the domain
+	if validated == 0 && len(errs) == 0 {
+		if _, err = v.zoneKeys(ctx, domain); errors.Is(err, errNotZoneCut) {
+			errs = append(errs, bogusError("no records to validate for %s", domain))
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	validation := &DNSSECValidation{Chain: v.chain, Verdict: DNSSECSecure}
+	for _, verdict := range []DNSSECVerdict{DNSSECBogus, DNSSECIndeterminate, DNSSECInsecure} {
+		for _, err = range errs {
+			var validationErr *dnssecError
+			if errors.As(err, &validationErr) && validationErr.verdict == verdict {
+				validation.Reason = validationErr.reason
+				validation.Verdict = verdict
+				return validation
+			}
+		}
+	}
+	return validation
+}
+
+// validateRRset will fetch and validate an RRset (following CNAME records)
+//
+// Returns no records (and no error) if the RRset does not exist (see verifyMissing)
+func (v *dnssecValidator) validateRRset(ctx context.Context, name string, qType uint16) ([]dns.RR, error) {
+	response, err := v.query(ctx, name, qType)
+	if err != nil {
+		return nil, err
+	}
+
+	name = dns.CanonicalName(name)
+	for i := 0; i <= maxCNAMEChain; i++ {
+		if rrset, sigs := rrsetOf(response.Answer, name, qType); len(rrset) > 0 {
+			return rrset, v.verifySigned(ctx, name, qType, rrset, sigs)
+		}
+
+		// Follow the CNAME (the alias is validated too)
+		cnames, sigs := rrsetOf(response.Answer, name, dns.TypeCNAME)
+		if len(cnames) == 0 {
+			return nil, v.verifyMissing(ctx, response, name, qType)
+		} else if err = v.verifySigned(ctx, name, dns.TypeCNAME, cnames, sigs); err != nil {
+			return nil, err
+		}
+		name = dns.CanonicalName(cnames[0].(*dns.CNAME).Target) //nolint:forcetypeassert // rrsetOf filters the type
+	}
+	return nil, bogusError("too many CNAME records for %s", name)
+}
+
+// verifySigned will verify the RRset with one of its signatures (and the keys of the signer zone)
+func (v *dnssecValidator) verifySigned(ctx context.Context, name string, qType uint16, rrset []dns.RR, sigs []*dns.RRSIG) error {
+	if len(sigs) == 0 {
+		return v.verifyUnsigned(ctx, name, qType)
+	}
+
+	var lastErr error
+	for _, sig := range sigs {
+		signer := dns.CanonicalName(sig.SignerName)
+		if !dns.IsSubDomain(signer, name) || (qType == dns.TypeDS && signer == name) {
+			lastErr = bogusError("invalid signer %s for %s %s", signer, name, dns.TypeToString[qType])
+			continue
+		}
+
+		keys, err := v.zoneKeys(ctx, signer)
+		if errors.Is(err, errNotZoneCut) {
+			return bogusError("signer %s of %s %s is not a zone", signer, name, dns.TypeToString[qType])
+		} else if err != nil {
+			return err
+		}
+		if lastErr = v.verify(rrset, sig, keys); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// verifyUnsigned will check if an RRset without signatures is in an unsigned zone
+//
+// The RRset is insecure if there is a proven unsigned delegation, and bogus if all the zones above it are signed
+func (v *dnssecValidator) verifyUnsigned(ctx context.Context, name string, qType uint16) error {
+	if err := v.unsignedDelegation(ctx, name); err != nil {
+		return err
+	}
+	return bogusError("missing signature for %s %s in a signed zone", name, dns.TypeToString[qType])
+}
+
+// verifyMissing will check the signed denial of existence of an RRset that is not in the response
+//
+// Without a proof, the RRset is insecure if there is a proven unsigned delegation, and bogus otherwise
+// (IE: the signed RRset was removed from the response)
+func (v *dnssecValidator) verifyMissing(ctx context.Context, response *dns.Msg, name string, qType uint16) error {
+	proof, err := v.proveDenial(ctx, response, name, qType)
+	if err != nil || proof != nil {
+		return err
+	}
+
+	var validationErr *dnssecError
+	if err = v.unsignedDelegation(ctx, name); errors.As(err, &validationErr) && validationErr.verdict != DNSSECBogus {
+		return err
+	}
+	return bogusError("missing proof that %s has no %s records", name, dns.TypeToString[qType])
+}
+
+// unsignedDelegation will check the delegations of the name from the top
+//
+// Returns the error of the first zone that is not validated (IE: a proven unsigned delegation), or nil
+// if all the zones above the name are signed
+func (v *dnssecValidator) unsignedDelegation(ctx context.Context, name string) error {
+	labels := dns.SplitDomainName(name)
+	for i := len(labels) - 1; i >= 0; i-- {
+		if _, err := v.zoneKeys(ctx, dns.Fqdn(strings.Join(labels[i:], "."))); err != nil && !errors.Is(err, errNotZoneCut) {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify will verify the signature of the RRset with the keys (and check the validity period)
+func (v *dnssecValidator) verify(rrset []dns.RR, sig *dns.RRSIG, keys []*dns.DNSKEY) error {
+	name, qType := rrset[0].Header().Name, dns.TypeToString[sig.TypeCovered]
+	if !sig.ValidityPeriod(v.now) {
+		if v.now.Unix() > int64(sig.Expiration) {
+			return bogusError("signature for %s %s expired on %s", name, qType, time.Unix(int64(sig.Expiration), 0).UTC().Format(time.RFC3339))
+		}
+		return bogusError("signature for %s %s is not valid before %s", name, qType, time.Unix(int64(sig.Inception), 0).UTC().Format(time.RFC3339))
+	}
+
+	for _, key := range keys {
+		if key.KeyTag() == sig.KeyTag && key.Algorithm == sig.Algorithm && sig.Verify(key, rrset) == nil {
+			return nil
+		}
+	}
+	return bogusError("invalid signature for %s %s (key tag %d)", name, qType, sig.KeyTag)
+}
+
+// zoneKeys will return the validated keys of the zone (DS => DNSKEY, up to the trust anchors)
+func (v *dnssecValidator) zoneKeys(ctx context.Context, zone string) ([]*dns.DNSKEY, error) {
+	zone = dns.CanonicalName(zone)
+	if keys, ok := v.keys[zone]; ok {
+		return keys, nil
+	} else if err, failed := v.failures[zone]; failed {
+		return nil, err
+	}
+
+	// Guard against loops (a zone signing its own delegation)
+	v.failures[zone] = bogusError("chain of trust loop at %s", zone)
+	keys, err := v.validateZoneKeys(ctx, zone)
+	if err != nil {
+		v.failures[zone] = err
+		return nil, err
+	}
+	delete(v.failures, zone)
+	v.keys[zone] = keys
+	v.chain = append(v.chain, zone)
+	return keys, nil
+}
+
+// validateZoneKeys will validate the DNSKEY RRset of the zone with the DS records (or trust anchors for the root)
+func (v *dnssecValidator) validateZoneKeys(ctx context.Context, zone string) ([]*dns.DNSKEY, error) {
+	// The DS records (from the parent zone)
+	dsRecords := v.anchors
+	if zone != "." {
+		var err error
+		if dsRecords, err = v.delegation(ctx, zone); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := v.query(ctx, zone, dns.TypeDNSKEY)
+	if err != nil {
+		return nil, err
+	}
+	rrset, sigs := rrsetOf(response.Answer, zone, dns.TypeDNSKEY)
+	if len(rrset) == 0 {
+		return nil, bogusError("no DNSKEY records for %s", zone)
+	}
+
+	// The key signing keys are the keys matching the DS records
+	var keys, entryKeys []*dns.DNSKEY
+	for _, rr := range rrset {
+		key := rr.(*dns.DNSKEY) //nolint:forcetypeassert // rrsetOf filters the type
+		if key.Flags&dns.ZONE != 0 {
+			keys = append(keys, key)
+		}
+		for _, ds := range dsRecords {
+			if computed := key.ToDS(ds.DigestType); computed != nil && ds.KeyTag == computed.KeyTag &&
+				ds.Algorithm == computed.Algorithm && strings.EqualFold(ds.Digest, computed.Digest) {
+				entryKeys = append(entryKeys, key)
+				break
+			}
+		}
+	}
+	if len(entryKeys) == 0 {
+		return nil, bogusError("no DNSKEY for %s matches the DS records", zone)
+	} else if len(sigs) == 0 {
+		return nil, bogusError("missing signature for %s DNSKEY", zone)
+	}
+
+	// The DNSKEY RRset must be signed by a key signing key
+	for _, sig := range sigs {
+		if err = v.verify(rrset, sig, entryKeys); err == nil {
+			return keys, nil
+		}
+	}
+	return nil, err
+}
+
+// delegation will return the validated DS records of the zone
+//
+// If there are no DS records, the denial (NSEC or NSEC3) proves an unsigned delegation (insecure)
+// or that the name is not a zone cut (errNotZoneCut)
+func (v *dnssecValidator) delegation(ctx context.Context, zone string) ([]*dns.DS, error) {
+	response, err := v.query(ctx, zone, dns.TypeDS)
+	if err != nil {
+		return nil, err
+	}
+
+	if rrset, sigs := rrsetOf(response.Answer, zone, dns.TypeDS); len(rrset) > 0 {
+		if err = v.verifySigned(ctx, zone, dns.TypeDS, rrset, sigs); err != nil {
+			return nil, err
+		}
+		dsRecords := make([]*dns.DS, 0, len(rrset))
+		for _, rr := range rrset {
+			dsRecords = append(dsRecords, rr.(*dns.DS)) //nolint:forcetypeassert // rrsetOf filters the type
+		}
+		return dsRecords, nil
+	}
+
+	// No DS records, check the signed denial
+	proof, err := v.proveDenial(ctx, response, zone, dns.TypeDS)
+	switch {
+	case err != nil:
+		return nil, err
+	case proof == nil:
+		return nil, bogusError("missing proof that %s has no DS records", zone)
+	case proof.optOut:
+		return nil, &dnssecError{reason: fmt.Sprintf("unsigned delegation to %s (NSEC3 opt-out)", zone), verdict: DNSSECInsecure}
+	case slices.Contains(proof.types, dns.TypeNS):
+		return nil, &dnssecError{reason: fmt.Sprintf("unsigned delegation to %s", zone), verdict: DNSSECInsecure}
+	}
+	return nil, errNotZoneCut
+}
+
+// query will fetch the records with the DNSSEC OK bit (and checking disabled, the validation is done here)
+func (v *dnssecValidator) query(ctx context.Context, name string, qType uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qType)
+	msg.SetEdns0(4096, true)
+	msg.CheckingDisabled = true
+
+	response, err := v.exchange(ctx, msg)
+	if err != nil {
+		return nil, &dnssecError{
+			reason:  fmt.Sprintf("failed to query %s %s: %s", name, dns.TypeToString[qType], err.Error()),
+			verdict: DNSSECIndeterminate,
+		}
+	} else if response.Rcode != dns.RcodeSuccess && response.Rcode != dns.RcodeNameError {
+		return nil, &dnssecError{
+			reason:  fmt.Sprintf("failed to query %s %s: %s", name, dns.TypeToString[qType], dns.RcodeToString[response.Rcode]),
+			verdict: DNSSECIndeterminate,
+		}
+	}
+	return response, nil
+}
+
+// rrsetOf will return the records of the name and type (and the signatures covering them)
+func rrsetOf(records []dns.RR, name string, qType uint16) (rrset []dns.RR, sigs []*dns.RRSIG) {
+	name = dns.CanonicalName(name)
+	for _, rr := range records {
+		if dns.CanonicalName(rr.Header().Name) != name {
+			continue
+		}
+		if sig, ok := rr.(*dns.RRSIG); ok && sig.TypeCovered == qType {
+			sigs = append(sigs, sig)
+		} else if rr.Header().Rrtype == qType {
+			rrset = append(rrset, rr)
+		}
+	}
+	return rrset, sigs
+}
@@ -0,0 +1,291 @@
+package paymail
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testZone is a signed zone (with a single key signing all the records)
+type testZone struct {
+	key    *dns.DNSKEY
+	name   string
+	signer crypto.Signer
+}
+
+// newTestZone will create a zone with a new ECDSA P-256 key
+func newTestZone(tb testing.TB, name string) *testZone {
+	key := &dns.DNSKEY{
+		Algorithm: dns.ECDSAP256SHA256,
+		Flags:     dns.ZONE | dns.SEP,
+		Hdr:       dns.RR_Header{Name: name, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Protocol:  3,
+	}
+	privateKey, err := key.Generate(256)
+	require.NoError(tb, err)
+	signer, ok := privateKey.(crypto.Signer)
+	require.True(tb, ok)
+	return &testZone{key: key, name: name, signer: signer}
+}
+
+// ds will return the DS record of the zone (for the parent zone or the trust anchors)
+func (z *testZone) ds() *dns.DS {
+	return z.key.ToDS(dns.SHA256)
+}
+
+// sign will return the RRset and its signature (valid for an hour)
+func (z *testZone) sign(tb testing.TB, rrset ...dns.RR) []dns.RR {
+	return z.signValid(tb, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), rrset...)
+}
+
+// signValid will return the RRset and its signature (valid between inception and expiration)
+func (z *testZone) signValid(tb testing.TB, inception, expiration time.Time, rrset ...dns.RR) []dns.RR {
+	sig := &dns.RRSIG{
+		Algorithm:  z.key.Algorithm,
+		Expiration: uint32(expiration.Unix()), //nolint:gosec // G115: test dates fit
+		Inception:  uint32(inception.Unix()),  //nolint:gosec // G115: test dates fit
+		KeyTag:     z.key.KeyTag(),
+		SignerName: z.name,
+	}
+	require.NoError(tb, sig.Sign(z.signer, rrset))
+	return append(rrset, sig)
+}
+
+// testRR will parse a record
+func testRR(tb testing.TB, record string) dns.RR {
+	rr, err := dns.NewRR(record)
+	require.NoError(tb, err)
+	return rr
+}
+
+// testDNSSECResponse is the response of the test name server for a name and type
+type testDNSSECResponse struct {
+	answer []dns.RR
+	ns     []dns.RR
+	rcode  int
+}
+
+// newTestDNSSECServer will start a local name server with pre-signed zones and return the client options to use it
+//
+//	.            signed (trust anchor)
+//	com.         signed
+//	test.com.    signed (SRV and A records)
+//	insecure.com unsigned delegation (NSEC proof in com.)
+//	bogus.com.   signed, the SRV signature is forged
+//	expired.com. signed, the SRV signature is expired
+//	nosig.com.   signed, the A record is not signed
+//	ghost.com.   does not exist (NSEC proof in com.)
+//	spoofed.com. does not exist, the NSEC proofs do not cover the names
+//	stripped.com signed, the SRV record is removed from the answer (without a proof)
+func newTestDNSSECServer(tb testing.TB) []ClientOps {
+	root, com := newTestZone(tb, "."), newTestZone(tb, "com.")
+	test, bogus := newTestZone(tb, "test.com."), newTestZone(tb, "bogus.com.")
+	expired, noSig, forged := newTestZone(tb, "expired.com."), newTestZone(tb, "nosig.com."), newTestZone(tb, "bogus.com.")
+	stripped := newTestZone(tb, "stripped.com.")
+
+	srv := func(domain string) dns.RR {
+		return testRR(tb, "_bsvalias._tcp."+domain+" 3600 IN SRV 10 10 443 www."+domain)
+	}
+	a := func(host string) dns.RR {
+		return testRR(tb, host+" 3600 IN A 44.225.125.175")
+	}
+
+	ghost := &testDNSSECResponse{rcode: dns.RcodeNameError, ns: com.sign(tb, testRR(tb, "com. 3600 IN NSEC insecure.com. NS SOA RRSIG NSEC DNSKEY"))}
+	spoofed := &testDNSSECResponse{rcode: dns.RcodeNameError, ns: com.sign(tb, testRR(tb, "insecure.com. 3600 IN NSEC j.com. NS RRSIG NSEC"))}
+	responses := map[string]*testDNSSECResponse{
+		"./DNSKEY":    {answer: root.sign(tb, root.key)},
+		"com./DS":     {answer: root.sign(tb, com.ds())},
+		"com./DNSKEY": {answer: com.sign(tb, com.key)},
+
+		"test.com./DS":                     {answer: com.sign(tb, test.ds())},
+		"test.com./DNSKEY":                 {answer: test.sign(tb, test.key)},
+		"_bsvalias._tcp.test.com./SRV":     {answer: test.sign(tb, srv("test.com."))},
+		"www.test.com./A":                  {answer: test.sign(tb, a("www.test.com."))},
+		"www.test.com./AAAA":               {ns: test.sign(tb, testRR(tb, "www.test.com. 3600 IN NSEC test.com. A RRSIG NSEC"))},
+		"insecure.com./DS":                 {ns: com.sign(tb, testRR(tb, "insecure.com. 3600 IN NSEC j.com. NS RRSIG NSEC"))},
+		"_bsvalias._tcp.insecure.com./SRV": {answer: []dns.RR{srv("insecure.com.")}},
+		"www.insecure.com./A":              {answer: []dns.RR{a("www.insecure.com.")}},
+
+		"bogus.com./DS":                 {answer: com.sign(tb, bogus.ds())},
+		"bogus.com./DNSKEY":             {answer: bogus.sign(tb, bogus.key)},
+		"_bsvalias._tcp.bogus.com./SRV": {answer: forged.sign(tb, srv("bogus.com."))},
+
+		"expired.com./DS":     {answer: com.sign(tb, expired.ds())},
+		"expired.com./DNSKEY": {answer: expired.sign(tb, expired.key)},
+		"_bsvalias._tcp.expired.com./SRV": {answer: expired.signValid(tb,
+			time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), srv("expired.com."))},
+
+		"nosig.com./DS":     {answer: com.sign(tb, noSig.ds())},
+		"nosig.com./DNSKEY": {answer: noSig.sign(tb, noSig.key)},
+		"nosig.com./A":      {answer: []dns.RR{a("nosig.com.")}},
+		"nosig.com./AAAA":   {ns: noSig.sign(tb, testRR(tb, "nosig.com. 3600 IN NSEC nosig.com. A NS SOA RRSIG NSEC DNSKEY"))},
+		"_bsvalias._tcp.nosig.com./SRV": {rcode: dns.RcodeNameError,
+			ns: noSig.sign(tb, testRR(tb, "nosig.com. 3600 IN NSEC nosig.com. A NS SOA RRSIG NSEC DNSKEY"))},
+
+		"ghost.com./DS":                   ghost,
+		"_bsvalias._tcp.ghost.com./SRV":   ghost,
+		"ghost.com./A":                    ghost,
+		"ghost.com./AAAA":                 ghost,
+		"spoofed.com./DS":                 spoofed,
+		"_bsvalias._tcp.spoofed.com./SRV": spoofed,
+
+		"stripped.com./DS":     {answer: com.sign(tb, stripped.ds())},
+		"stripped.com./DNSKEY": {answer: stripped.sign(tb, stripped.key)},
+		"stripped.com./A":      {answer: stripped.sign(tb, a("stripped.com."))},
+	}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(tb, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			reply := new(dns.Msg)
+			reply.SetReply(r)
+			question := r.Question[0]
+			if response, ok := responses[question.Name+"/"+dns.TypeToString[question.Qtype]]; ok {
+				reply.Answer, reply.Ns, reply.Rcode = response.answer, response.ns, response.rcode
+			} else if question.Qtype != dns.TypeAAAA {
+				reply.Rcode = dns.RcodeNameError
+			}
+			_ = w.WriteMsg(reply)
+		}),
+		NotifyStartedFunc: func() { close(started) },
+		PacketConn:        conn,
+	}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	<-started
+	tb.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	_, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	return []ClientOps{
+		WithDNSPort(port),
+		WithDNSSECTrustAnchors(root.ds()),
+		WithDNSTimeout(time.Second),
+		WithNameServer("127.0.0.1"),
+	}
+}
+
+// TestClient_ValidateDNSSEC will test the method ValidateDNSSEC()
+func TestClient_ValidateDNSSEC(t *testing.T) {
+	t.Parallel()
+
+	opts := newTestDNSSECServer(t)
+	client, err := NewClient(opts...)
+	require.NoError(t, err)
+
+	t.Run("secure", func(t *testing.T) {
+		validation := client.ValidateDNSSEC("test.com")
+		assert.Equal(t, DNSSECSecure, validation.Verdict)
+		assert.Empty(t, validation.Reason)
+		assert.Equal(t, []string{".", "com.", "test.com."}, validation.Chain)
+	})
+
+	tests := []struct {
+		domain  string
+		verdict DNSSECVerdict
+		reason  string
+	}{
+		{"insecure.com", DNSSECInsecure, "unsigned delegation to insecure.com."},
+		{"bogus.com", DNSSECBogus, "invalid signature for _bsvalias._tcp.bogus.com. SRV"},
+		{"expired.com", DNSSECBogus, "signature for _bsvalias._tcp.expired.com. SRV expired on"},
+		{"nosig.com", DNSSECBogus, "missing signature for nosig.com. A in a signed zone"},
+		{"unknown.com", DNSSECBogus, "missing proof that _bsvalias._tcp.unknown.com. has no SRV records"},
+		{"ghost.com", DNSSECBogus, "no records to validate for ghost.com"},
+		{"spoofed.com", DNSSECBogus, "missing proof that _bsvalias._tcp.spoofed.com. has no SRV records"},
+		{"stripped.com", DNSSECBogus, "missing proof that _bsvalias._tcp.stripped.com. has no SRV records"},
+		{"", DNSSECBogus, ErrSRVInvalidDomainName.Error()},
+	}
+	for _, test := range tests {
+		t.Run(test.domain, func(t *testing.T) {
+			validation := client.ValidateDNSSEC(test.domain)
+			assert.Equal(t, test.verdict, validation.Verdict)
+			assert.Contains(t, validation.Reason, test.reason)
+		})
+	}
+
+	t.Run("wrong trust anchor", func(t *testing.T) {
+		anchor := newTestZone(t, ".").ds()
+		client, err = NewClient(append(opts, WithDNSSECTrustAnchors(anchor))...)
+		require.NoError(t, err)
+
+		validation := client.ValidateDNSSEC("test.com")
+		assert.Equal(t, DNSSECBogus, validation.Verdict)
+		assert.Equal(t, "no DNSKEY for . matches the DS records", validation.Reason)
+	})
+
+	t.Run("name server not reachable", func(t *testing.T) {
+		client, err = NewClient(append(opts, WithDNSPort("1"), WithDNSTimeout(100*time.Millisecond))...)
+		require.NoError(t, err)
+
+		validation := client.ValidateDNSSEC("test.com")
+		assert.Equal(t, DNSSECIndeterminate, validation.Verdict)
+		assert.Contains(t, validation.Reason, "failed to query")
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		validation := client.ValidateDNSSECCtx(ctx, "test.com")
+		assert.Equal(t, DNSSECIndeterminate, validation.Verdict)
+	})
+}
+
+// TestClient_CheckDNSSEC_Validation will test the validation set by CheckDNSSEC()
+func TestClient_CheckDNSSEC_Validation(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(newTestDNSSECServer(t)...)
+	require.NoError(t, err)
+
+	result := client.CheckDNSSEC("test.com")
+	require.NotNil(t, result.Validation)
+	assert.Equal(t, DNSSECSecure, result.Validation.Verdict)
+}
+
+// TestDefaultDNSSECTrustAnchors will test the method DefaultDNSSECTrustAnchors()
+func TestDefaultDNSSECTrustAnchors(t *testing.T) {
+	t.Parallel()
+
+	anchors := DefaultDNSSECTrustAnchors()
+	require.Len(t, anchors, 2)
+	assert.Equal(t, uint16(20326), anchors[0].KeyTag)
+	assert.Equal(t, uint16(38696), anchors[1].KeyTag)
+	for _, anchor := range anchors {
+		assert.Equal(t, ".", anchor.Hdr.Name)
+		assert.Equal(t, dns.RSASHA256, anchor.Algorithm)
+		assert.Equal(t, dns.SHA256, anchor.DigestType)
+	}
+}
+
+// ExampleClient_ValidateDNSSEC example using ValidateDNSSEC()
+//
+// See more examples in /examples/
+func ExampleClient_ValidateDNSSEC() {
+	// Load the client (the name server must return the DNSSEC records)
+	client, _ := NewClient(WithNameServer("127.0.0.1"), WithDNSPort("1"), WithDNSTimeout(100*time.Millisecond))
+
+	validation := client.ValidateDNSSEC("test.com")
+	fmt.Printf("dnssec for test.com is %s", validation.Verdict)
+	// Output:dnssec for test.com is indeterminate
+}
+
+// BenchmarkClient_ValidateDNSSEC benchmarks the method ValidateDNSSEC()
+func BenchmarkClient_ValidateDNSSEC(b *testing.B) {
+	client, _ := NewClient(newTestDNSSECServer(b)...)
+	for i := 0; i < b.N; i++ {
+		_ = client.ValidateDNSSEC("test.com")
+	}
+}
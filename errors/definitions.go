@@ -49,6 +49,9 @@ var (
 
 	// ErrProcessingBEEF is when error occurred during processing beef
 	ErrProcessingBEEF = SPVError{Message: "cannot process beef", StatusCode: 400, Code: "error-processing-beef"}
+
+	// ErrRequestBodyTooLarge is when the request body is larger than the limit
+	ErrRequestBodyTooLarge = SPVError{Message: "request body is too large", StatusCode: 413, Code: "error-body-too-large"}
 )
 
 // PAYMAIL ERRORS
@@ -126,6 +129,21 @@ var (
 	// ErrOutputValueTooHigh is when the satoshis output is too high on a transaction
 	ErrOutputValueTooHigh = SPVError{Message: "invalid input and output sum, outputs can not be larger than inputs", StatusCode: 417, Code: "error-spv-output-value-too-high"}
 
+	// ErrFeeTooLow is when the fee of an unmined transaction (with its unmined ancestors and descendants) is below the fee policy
+	ErrFeeTooLow = SPVError{Message: "fee is too low, the fee rate is below the fee policy", StatusCode: 417, Code: "error-spv-fee-too-low"}
+
+	// ErrInvalidBUMPIndex is when a mined transaction points to a BUMP that is not in the BEEF
+	ErrInvalidBUMPIndex = SPVError{Message: "invalid BEEF - transaction BUMP index out of range", StatusCode: 417, Code: "error-spv-bump-index-invalid"}
+
+	// ErrDuplicateTransaction is when a transaction is in the BEEF more than once
+	ErrDuplicateTransaction = SPVError{Message: "invalid BEEF - duplicate transaction", StatusCode: 417, Code: "error-spv-duplicate-transaction"}
+
+	// ErrInvalidTransactionOrder is when a transaction is before its parent, or the processed transaction is not a raw transaction
+	ErrInvalidTransactionOrder = SPVError{Message: "invalid BEEF - transactions are not in topological order", StatusCode: 417, Code: "error-spv-transaction-order-invalid"}
+
+	// ErrDoubleSpend is when an outpoint is spent by two transactions of the BEEF
+	ErrDoubleSpend = SPVError{Message: "invalid BEEF - outpoint spent by two transactions", StatusCode: 417, Code: "error-spv-double-spend"}
+
 	// ErrBUMPAncestorNotPresent is when the input mined ancestor is not present in BUMPs
 	ErrBUMPAncestorNotPresent = SPVError{Message: "invalid BUMP - input mined ancestor is not present in BUMPs", StatusCode: 417, Code: "error-spv-bump-ancestor-not-present"}
 
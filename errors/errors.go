@@ -17,6 +17,7 @@ type SPVError struct {
 // ResponseError is an error which will be returned in HTTP response
 type ResponseError struct {
 	Code    string `json:"code"`
+	Details any    `json:"details,omitempty"` // Optional details (e.g. the SPV verification report)
 	Message string `json:"message"`
 }
 
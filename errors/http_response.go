@@ -11,6 +11,19 @@ import (
 // ErrorResponse is a standard way to return errors to the client
 func ErrorResponse(c *gin.Context, err error, log *zerolog.Logger) {
 	response, statusCode := mapAndLog(err, log)
+	if err != nil {
+		_ = c.Error(err) // Keep the error on the context (used by the server telemetry)
+	}
+	c.JSON(statusCode, response)
+}
+
+// ErrorResponseWithDetails is ErrorResponse with the details of the error (e.g. a verification report)
+func ErrorResponseWithDetails(c *gin.Context, err error, details any, log *zerolog.Logger) {
+	response, statusCode := mapAndLog(err, log)
+	response.Details = details
+	if err != nil {
+		_ = c.Error(err) // Keep the error on the context (used by the server telemetry)
+	}
 	c.JSON(statusCode, response)
 }
 
@@ -3,8 +3,10 @@ package errors
 import (
 	"errors"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -153,3 +155,21 @@ func TestMapAndLog_StatusCodeBoundaries(t *testing.T) {
 		assert.Equal(t, "gateway-error", response.Code)
 	})
 }
+
+func TestErrorResponseWithDetails(t *testing.T) {
+	t.Parallel()
+
+	spvErr := SPVError{
+		Code:       "error-spv",
+		Message:    "spv failed",
+		StatusCode: http.StatusExpectationFailed,
+	}
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+
+	ErrorResponseWithDetails(c, spvErr, map[string]bool{"valid": false}, nil)
+
+	assert.Equal(t, http.StatusExpectationFailed, recorder.Code)
+	assert.JSONEq(t, `{"code":"error-spv","message":"spv failed","details":{"valid":false}}`, recorder.Body.String())
+	assert.Len(t, c.Errors, 1)
+}
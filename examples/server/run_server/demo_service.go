@@ -132,7 +132,7 @@ func DemoCreateAddressResolutionResponse(_ context.Context, alias, domain string
 
 	// Create a signature of output if senderValidation is enabled
 	if senderValidation {
-		sigBytes, err := bsm.SignMessage(privateKeyFromHex, ls.Bytes())
+		sigBytes, err := bsm.SignMessage(privateKeyFromHex, []byte(response.Output))
 		if err != nil {
 			return nil, fmt.Errorf("invalid signature: %w", err)
 		}
@@ -0,0 +1,98 @@
+package paymail
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Request is an outgoing paymail HTTP request
+//
+// Interceptors can modify the request before calling the next handler
+type Request struct {
+	Body   []byte      // The JSON encoded body (POST requests only)
+	Header http.Header // The request headers (the User-Agent is set by default)
+	Method string      // The HTTP method (GET or POST)
+	URL    string      // The full request url
+}
+
+// RequestHandler will send the request and return the response
+type RequestHandler func(ctx context.Context, req *Request) (StandardResponse, error)
+
+// Interceptor runs around every paymail HTTP request made by the client
+//
+// An interceptor can modify the request (auth headers, idempotency keys), call next and
+// inspect or modify the response (logging, metrics), or return a response without calling
+// next at all (short-circuit). Interceptors run in the order they are given.
+type Interceptor func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error)
+
+// chainInterceptors will wrap the handler with the interceptors (the first interceptor runs first)
+func chainInterceptors(handler RequestHandler, interceptors []Interceptor) RequestHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, req *Request) (StandardResponse, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return handler
+}
+
+// doRequest will run the request through the interceptors and send it
+//
+// The circuit breaker and rate limiter run after the interceptors, and the HTTP span is created
+// last (around the actual request)
+func (c *Client) doRequest(ctx context.Context, req *Request) (StandardResponse, error) {
+	interceptors := slices.Clip(c.options.interceptors)
+	if c.breaker != nil {
+		interceptors = append(interceptors, c.breaker.intercept)
+	}
+	if c.limiter != nil {
+		interceptors = append(interceptors, c.limiter.intercept)
+	}
+	return chainInterceptors(c.sendRequest, append(interceptors, c.traceRequest))(ctx, req)
+}
+
+// sendRequest will send the request using the HTTP client (the last handler in the chain)
+func (c *Client) sendRequest(ctx context.Context, req *Request) (response StandardResponse, err error) {
+	// Set the context and headers
+	r := c.httpClient.R().SetContext(ctx)
+	for key, values := range req.Header {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	if req.Body != nil {
+		r.SetBody(req.Body)
+	}
+
+	// Enable tracing
+	if c.options.requestTracing {
+		r.EnableTrace()
+	}
+
+	// Do not fire the request if the context is already done
+	if err = ctx.Err(); err != nil {
+		return response, err
+	}
+
+	// Fire the request
+	var resp *resty.Response
+	if resp, err = r.Execute(req.Method, req.URL); err != nil {
+		return response, err
+	}
+
+	// Tracing enabled?
+	if c.options.requestTracing {
+		response.Tracing = resp.Request.TraceInfo()
+	}
+
+	// Set the status code and headers
+	response.StatusCode = resp.StatusCode()
+	response.Header = resp.Header()
+
+	// Set the body
+	response.Body = resp.Body()
+	return response, err
+}
@@ -0,0 +1,210 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_Interceptors will test the interceptors set with WithInterceptors()
+func TestClient_Interceptors(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	pkiURL := testServerURL + "id/" + testAlias + "@" + testDomain
+
+	t.Run("default user agent", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, pkiURL,
+			func(req *http.Request) (*http.Response, error) {
+				assert.Equal(t, defaultUserAgent, req.Header.Get("User-Agent"))
+				return httpmock.NewStringResponse(http.StatusOK, `{"bsvalias": "1.0","handle": "`+testAlias+`@`+testDomain+`","pubkey": "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"}`), nil
+			},
+		)
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.NoError(t, err)
+	})
+
+	t.Run("interceptors run in order around the request", func(t *testing.T) {
+		var calls []string
+		record := func(name string) Interceptor {
+			return func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+				calls = append(calls, name+":before")
+				response, err := next(ctx, req)
+				calls = append(calls, name+":after")
+				return response, err
+			}
+		}
+		client := newTestClient(t, WithInterceptors(record("first"), record("second")))
+
+		mockGetPKI(http.StatusOK)
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first:before", "second:before", "second:after", "first:after"}, calls)
+	})
+
+	t.Run("add headers", func(t *testing.T) {
+		client := newTestClient(t, WithInterceptors(
+			func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+				req.Header.Set("Authorization", "Bearer token")
+				req.Header.Set("User-Agent", "custom-agent")
+				return next(ctx, req)
+			},
+		))
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, pkiURL,
+			func(req *http.Request) (*http.Response, error) {
+				if req.Header.Get("Authorization") != "Bearer token" || req.Header.Get("User-Agent") != "custom-agent" {
+					return httpmock.NewStringResponse(http.StatusUnauthorized, `{"message": "unauthorized"}`), nil
+				}
+				return httpmock.NewStringResponse(http.StatusOK, `{"bsvalias": "1.0","handle": "`+testAlias+`@`+testDomain+`","pubkey": "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"}`), nil
+			},
+		)
+
+		pki, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, pki.StatusCode)
+	})
+
+	t.Run("post body is available and can be replaced", func(t *testing.T) {
+		var method, body string
+		client := newTestClient(t, WithInterceptors(
+			func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+				method, body = req.Method, string(req.Body)
+				req.Body = []byte(strings.ReplaceAll(body, "1000", "2000"))
+				req.Header.Set("Idempotency-Key", "key-1")
+				return next(ctx, req)
+			},
+		))
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"p2p-payment-destination/"+testAlias+"@"+testDomain,
+			func(req *http.Request) (*http.Response, error) {
+				data, _ := io.ReadAll(req.Body)
+				assert.JSONEq(t, `{"satoshis": 2000}`, string(data))
+				assert.Equal(t, "key-1", req.Header.Get("Idempotency-Key"))
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+				return httpmock.NewStringResponse(http.StatusOK, `{"outputs": [{"script": "`+testPayerScript+`","satoshis": 2000}],"reference": "`+testPayerReference+`"}`), nil
+			},
+		)
+
+		destination, err := client.GetP2PPaymentDestination(testServerURL+"p2p-payment-destination/{alias}@{domain.tld}",
+			testAlias, testDomain, &PaymentRequest{Satoshis: 1000})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, method)
+		assert.JSONEq(t, `{"satoshis": 1000}`, body)
+		assert.Equal(t, testPayerReference, destination.Reference)
+	})
+
+	t.Run("short-circuit the response", func(t *testing.T) {
+		client := newTestClient(t, WithInterceptors(
+			func(_ context.Context, _ *Request, _ RequestHandler) (StandardResponse, error) {
+				return StandardResponse{
+					Body:       []byte(`{"bsvalias": "1.0","handle": "` + testAlias + `@` + testDomain + `","pubkey": "02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"}`),
+					StatusCode: http.StatusOK,
+				}, nil
+			},
+		))
+
+		httpmock.Reset()
+
+		pki, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, testAlias+"@"+testDomain, pki.Handle)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("modify the response", func(t *testing.T) {
+		client := newTestClient(t, WithInterceptors(
+			func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+				response, err := next(ctx, req)
+				if err == nil && response.StatusCode == http.StatusServiceUnavailable {
+					response.StatusCode = http.StatusBadGateway
+				}
+				return response, err
+			},
+		))
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, pkiURL,
+			httpmock.NewStringResponder(http.StatusServiceUnavailable, `{"message": "unavailable"}`))
+
+		pki, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, ErrPKIBadResponse)
+		assert.Equal(t, http.StatusBadGateway, pki.StatusCode)
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		client := newTestClient(t, WithInterceptors(
+			func(_ context.Context, _ *Request, _ RequestHandler) (StandardResponse, error) {
+				return StandardResponse{}, assert.AnError
+			},
+		))
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		var called bool
+		client := newTestClient(t, WithInterceptors(
+			func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+				called = true
+				return next(ctx, req)
+			},
+		))
+
+		mockGetPKI(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.True(t, called)
+		assert.Equal(t, 0, httpmock.GetTotalCallCount())
+	})
+}
+
+// ExampleWithInterceptors example using WithInterceptors()
+//
+// See more examples in /examples/
+func ExampleWithInterceptors() {
+	// Log every request made by the client
+	logger := func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+		response, err := next(ctx, req)
+		fmt.Printf("%s %s: %d\n", req.Method, req.URL, response.StatusCode)
+		return response, err
+	}
+
+	// Load the client
+	client := newTestClient(nil, WithInterceptors(logger))
+
+	mockGetPKI(http.StatusOK)
+
+	// Get the PKI
+	_, _ = client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	// Output:GET https://test.com/api/v1/bsvalias/id/mrz@test.com: 200
+}
+
+// BenchmarkClient_Interceptors benchmarks a request with interceptors
+func BenchmarkClient_Interceptors(b *testing.B) {
+	passThrough := func(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+		return next(ctx, req)
+	}
+	client := newTestClient(nil, WithInterceptors(passThrough, passThrough, passThrough))
+	mockGetPKI(http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	}
+}
@@ -12,23 +12,44 @@ import (
 // ClientInterface is the Paymail client interface
 type ClientInterface interface {
 	CheckDNSSEC(domain string) (result *DNSCheckResult)
+	CheckDNSSECCtx(ctx context.Context, domain string) (result *DNSCheckResult)
 	CheckSSL(host string) (valid bool, err error)
+	CheckPaymailTLS(ctx context.Context, domain string) (report *TLSReport, err error)
+	CheckSSLCtx(ctx context.Context, host string) (valid bool, err error)
+	CheckTLS(ctx context.Context, host string, port int) (report *TLSReport, err error)
+	CircuitStates() map[string]CircuitState
 	GetBRFCs() []*BRFCSpec
 	GetCapabilities(target string, port int) (response *CapabilitiesResponse, err error)
+	GetCapabilitiesCtx(ctx context.Context, target string, port int) (response *CapabilitiesResponse, err error)
 	GetOptions() *ClientOptions
 	GetP2PPaymentDestination(p2pURL, alias, domain string, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PPaymentDestinationCtx(ctx context.Context, p2pURL, alias, domain string, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
 	GetPKI(pkiURL, alias, domain string) (response *PKIResponse, err error)
+	GetPKICtx(ctx context.Context, pkiURL, alias, domain string) (response *PKIResponse, err error)
 	GetPublicProfile(publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error)
+	GetPublicProfileCtx(ctx context.Context, publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error)
 	GetResolver() interfaces.DNSResolver
 	GetSRVRecord(service, protocol, domainName string) (srv *net.SRV, err error)
+	GetSRVRecordCtx(ctx context.Context, service, protocol, domainName string) (srv *net.SRV, err error)
+	GetSRVRecords(service, protocol, domainName string) (records []*net.SRV, err error)
+	GetSRVRecordsCtx(ctx context.Context, service, protocol, domainName string) (records []*net.SRV, err error)
 	GetUserAgent() string
 	ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
+	ResolveAddressCtx(ctx context.Context, resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
 	SendP2PTransaction(p2pURL, alias, domain string, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
+	SendP2PTransactionCtx(ctx context.Context, p2pURL, alias, domain string, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
+	ValidateDNSSEC(domain string) *DNSSECValidation
+	ValidateDNSSECCtx(ctx context.Context, domain string) *DNSSECValidation
 	ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, priority, weight uint16) error
+	ValidateSRVRecordWithPolicy(ctx context.Context, srv *net.SRV, policy *SRVPolicy) error
 	VerifyPubKey(verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error)
+	VerifyPubKeyCtx(ctx context.Context, verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error)
 	WithCustomHTTPClient(client *resty.Client) ClientInterface
 	WithCustomResolver(resolver interfaces.DNSResolver) ClientInterface
 	AddContactRequest(url, alias, domain string, request *PikeContactRequestPayload) (response *PikeContactRequestResponse, err error)
+	AddContactRequestCtx(ctx context.Context, url, alias, domain string, request *PikeContactRequestPayload) (response *PikeContactRequestResponse, err error)
 	AddInviteRequest(inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error)
+	AddInviteRequestCtx(ctx context.Context, inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error)
 	GetOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error)
+	GetOutputsTemplateCtx(ctx context.Context, pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error)
 }
@@ -4,6 +4,7 @@ package interfaces
 import (
 	"context"
 	"net"
+	"time"
 )
 
 // DNSResolver is a custom resolver interface for testing
@@ -12,3 +13,12 @@ type DNSResolver interface {
 	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
 	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
 }
+
+// Cache is a custom cache interface for paymail responses (capabilities, PKI, SRV records)
+//
+// A ttl of zero or less means the value does not expire
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -79,6 +80,16 @@ type PaymentOutput struct {
 func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 	paymentRequest *PaymentRequest,
 ) (response *PaymentDestinationResponse, err error) {
+	return c.GetP2PPaymentDestinationCtx(context.Background(), p2pURL, alias, domain, paymentRequest)
+}
+
+// GetP2PPaymentDestinationCtx is the context-aware version of GetP2PPaymentDestination
+func (c *Client) GetP2PPaymentDestinationCtx(ctx context.Context, p2pURL, alias, domain string,
+	paymentRequest *PaymentRequest,
+) (response *PaymentDestinationResponse, err error) {
+	ctx, end := c.startSpan(ctx, "GetP2PPaymentDestination", BRFCP2PPaymentDestination, domain)
+	defer func() { end(err) }()
+
 	// Require a valid url
 	if len(p2pURL) == 0 || !strings.Contains(p2pURL, "https://") {
 		err = fmt.Errorf("%s: %s: %w", "invalid url", p2pURL, ErrP2PInvalidURL)
@@ -107,7 +118,7 @@ func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, paymentRequest); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, paymentRequest); err != nil {
 		return response, err
 	}
 
@@ -120,16 +131,9 @@ func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 
 		// Paymail address not found?
 		if response.StatusCode == http.StatusNotFound {
-			err = ErrP2PAddressNotFound
+			err = newResponseError(&resp, reqURL, BRFCP2PPaymentDestination, ErrP2PAddressNotFound)
 		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return response, err
-			}
-			err = fmt.Errorf(
-				"code %d, message: %s: %w",
-				response.StatusCode, serverError.Message, ErrP2PBadResponse,
-			)
+			err = newResponseError(&resp, reqURL, BRFCP2PPaymentDestination, ErrP2PBadResponse)
 		}
 
 		return response, err
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -377,6 +378,25 @@ func TestClient_GetP2PPaymentDestination(t *testing.T) {
 		assert.Empty(t, destination.Outputs[0].Address)
 		assert.Len(t, destination.Outputs[0].Script, 1)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockP2PPaymentDestination(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		destination, err := client.GetP2PPaymentDestinationCtx(
+			ctx,
+			testServerURL+"p2p-payment-destination/{alias}@{domain.tld}",
+			testAlias,
+			testDomain,
+			&PaymentRequest{Satoshis: 100},
+		)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, destination)
+	})
 }
 
 // mockP2PPaymentDestination is used for mocking the response
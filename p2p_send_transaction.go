@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -80,6 +81,16 @@ type P2PTransactionPayload struct {
 func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 	transaction *P2PTransaction,
 ) (response *P2PTransactionResponse, err error) {
+	return c.SendP2PTransactionCtx(context.Background(), p2pURL, alias, domain, transaction)
+}
+
+// SendP2PTransactionCtx is the context-aware version of SendP2PTransaction
+func (c *Client) SendP2PTransactionCtx(ctx context.Context, p2pURL, alias, domain string,
+	transaction *P2PTransaction,
+) (response *P2PTransactionResponse, err error) {
+	ctx, end := c.startSpan(ctx, "SendP2PTransaction", BRFCP2PTransactions, domain)
+	defer func() { end(err) }()
+
 	// Require a valid url
 	if len(p2pURL) == 0 || !strings.Contains(p2pURL, "https://") {
 		err = fmt.Errorf("%s: %s: %w", "invalid url", p2pURL, ErrSendP2PInvalidURL)
@@ -111,7 +122,7 @@ func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, transaction); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, transaction); err != nil {
 		return response, err
 	}
 
@@ -122,18 +133,14 @@ func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
 
 		// Paymail address not found?
+		brfc := BRFCP2PTransactions
+		if len(transaction.Beef) > 0 {
+			brfc = BRFCBeefTransaction
+		}
 		if response.StatusCode == http.StatusNotFound {
-			err = ErrSendP2PAddressNotFound
+			err = newResponseError(&resp, reqURL, brfc, ErrSendP2PAddressNotFound)
 		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return response, err
-			}
-			if len(serverError.Message) == 0 {
-				err = fmt.Errorf("code %d, body: %s: %w", response.StatusCode, string(resp.Body), ErrSendP2PBadResponse)
-			} else {
-				err = fmt.Errorf("code %d, message: %s: %w", response.StatusCode, serverError.Message, ErrSendP2PBadResponse)
-			}
+			err = newResponseError(&resp, reqURL, brfc, ErrSendP2PBadResponse)
 		}
 
 		return response, err
@@ -0,0 +1,501 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	primitives "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/bsv-blockchain/go-sdk/util"
+)
+
+var (
+	// ErrPayerMissingClient is returned when the payer is created without a client
+	ErrPayerMissingClient = errors.New("paymail client is required for the payer")
+	// ErrPayerMissingUTXOSource is returned when the payer is created without a utxo source
+	ErrPayerMissingUTXOSource = errors.New("utxo source is required for the payer")
+	// ErrPayerMissingChangeProvider is returned when the payer is created without a change provider
+	ErrPayerMissingChangeProvider = errors.New("change provider is required for the payer")
+	// ErrPayerMissingSigner is returned when the payer is created without a signer
+	ErrPayerMissingSigner = errors.New("signer is required for the payer")
+	// ErrPayerMissingPayment is returned when the payment is nil
+	ErrPayerMissingPayment = errors.New("payment cannot be nil")
+	// ErrPayerMissingSatoshis is returned when the payment amount is zero
+	ErrPayerMissingSatoshis = errors.New("satoshis is required")
+	// ErrPayerMissingSenderHandle is returned when the provider requires a sender handle (basic address resolution)
+	ErrPayerMissingSenderHandle = errors.New("sender handle is required by the paymail provider")
+	// ErrPayerMissingSenderKey is returned when the provider requires sender validation without a sender key
+	ErrPayerMissingSenderKey = errors.New("sender key is required by the paymail provider (sender validation)")
+	// ErrPayerMissingBroadcaster is returned when the provider has no P2P capabilities and no broadcaster is set
+	ErrPayerMissingBroadcaster = errors.New("broadcaster is required for providers without P2P transactions")
+	// ErrPayerUnsupportedProvider is returned when the provider has no payment capabilities
+	ErrPayerUnsupportedProvider = errors.New("paymail provider does not support payments")
+	// ErrPayerMissingOutputs is returned when the provider returned no outputs
+	ErrPayerMissingOutputs = errors.New("paymail provider returned no outputs")
+	// ErrPayerMissingUTXOs is returned when the utxo source returned no utxos
+	ErrPayerMissingUTXOs = errors.New("utxo source returned no utxos")
+	// ErrPayerInsufficientFunds is returned when the utxos do not cover the outputs and the fee
+	ErrPayerInsufficientFunds = errors.New("insufficient funds to cover the outputs and fee")
+	// ErrPayerBroadcastFailed is returned when the broadcaster rejected the transaction
+	ErrPayerBroadcastFailed = errors.New("failed to broadcast the transaction")
+	// ErrPayerTxIDMismatch is returned when the returned txid does not match the signed transaction
+	ErrPayerTxIDMismatch = errors.New("returned txid does not match the transaction")
+)
+
+// PaymentMethod is the method used to deliver the payment to the provider
+type PaymentMethod string
+
+// Payment methods
+const (
+	PaymentMethodBasic PaymentMethod = "basic" // Basic address resolution, the transaction is broadcast by the sender
+	PaymentMethodBEEF  PaymentMethod = "beef"  // P2P payment destination, the transaction is sent in BEEF format
+	PaymentMethodP2P   PaymentMethod = "p2p"   // P2P payment destination, the transaction is sent in hex format
+)
+
+// UTXOSource will supply the outputs used to fund a payment
+type UTXOSource interface {
+	// UTXOs will return spendable outputs covering at least the given amount of satoshis
+	// (the amount includes an estimated fee, more can be returned)
+	UTXOs(ctx context.Context, satoshis uint64) ([]*PayerUTXO, error)
+}
+
+// ChangeProvider will supply the locking script for the change output
+type ChangeProvider interface {
+	// ChangeScript will return the locking script that receives the change
+	ChangeScript(ctx context.Context) (*script.Script, error)
+}
+
+// Signer will sign the inputs of the payment transaction
+type Signer interface {
+	// EstimateLength will return the estimated unlocking script length of an input (used for the fee)
+	//
+	// The input index is always an input of the transaction (tx.Inputs[inputIndex] exists), the inputs
+	// that are not added yet are estimated as P2PKH inputs
+	EstimateLength(tx *transaction.Transaction, inputIndex uint32) uint32
+	// Sign will set the unlocking script on every input of the transaction
+	Sign(ctx context.Context, tx *transaction.Transaction) error
+}
+
+// PayerUTXO is a spendable output used to fund a payment
+type PayerUTXO struct {
+	LockingScript     *script.Script           // The locking script of the output
+	Satoshis          uint64                   // The value of the output
+	SourceTransaction *transaction.Transaction // (optional) The transaction of the output, with its ancestry (required for BEEF)
+	TxID              string                   // The txid of the output
+	Vout              uint32                   // The index of the output
+}
+
+// Payment is a payment to a paymail address
+type Payment struct {
+	Note         string                 // (optional) Human-readable note sent to the provider
+	Satoshis     uint64                 // (required) The amount to pay
+	SenderHandle string                 // (optional) The paymail of the sender (required for basic address resolution)
+	SenderKey    *primitives.PrivateKey // (optional) Signs the sender request and the txid (required for sender validation)
+	SenderName   string                 // (optional) Human-readable name of the sender
+	To           string                 // (required) The paymail address to pay
+}
+
+// PaymentResult is the result of a payment
+type PaymentResult struct {
+	Endpoint    *PaymailEndpoint         // The discovered paymail provider
+	Fee         uint64                   // The fee paid in satoshis
+	Method      PaymentMethod            // The method used to deliver the payment
+	Note        string                   // The note returned by the provider (P2P only)
+	Reference   string                   // The payment reference (P2P only)
+	Transaction *transaction.Transaction // The signed transaction
+	TxID        string                   // The txid of the transaction
+}
+
+// PayerOps allow functional options to be supplied
+// that overwrite default payer options.
+type PayerOps func(p *PayerOptions)
+
+// PayerOptions holds the configuration for the payer
+type PayerOptions struct {
+	broadcaster transaction.Broadcaster // Broadcaster used for basic address resolution
+	feeRate     uint64                  // Fee rate in satoshis per kilobyte
+}
+
+// WithPayerBroadcaster will set the broadcaster used for providers without P2P transactions
+func WithPayerBroadcaster(broadcaster transaction.Broadcaster) PayerOps {
+	return func(p *PayerOptions) {
+		p.broadcaster = broadcaster
+	}
+}
+
+// WithPayerFeeRate will set the fee rate in satoshis per kilobyte.
+// Default is 100 satoshis per kilobyte.
+func WithPayerFeeRate(satoshisPerKB uint64) PayerOps {
+	return func(p *PayerOptions) {
+		p.feeRate = satoshisPerKB
+	}
+}
+
+// Payer will pay a paymail address using the given utxo source, change provider and signer
+//
+// The P2P payment destination (BEEF or hex) is preferred, basic address resolution is used
+// as a fallback (the transaction is broadcast using the broadcaster)
+type Payer struct {
+	changeProvider ChangeProvider
+	client         ClientInterface
+	options        *PayerOptions
+	resolver       *Resolver
+	signer         Signer
+	utxoSource     UTXOSource
+}
+
+// NewPayer will create a new payer
+func NewPayer(client ClientInterface, utxoSource UTXOSource, changeProvider ChangeProvider,
+	signer Signer, opts ...PayerOps,
+) (*Payer, error) {
+	// Basic requirements for the payer
+	if client == nil {
+		return nil, ErrPayerMissingClient
+	} else if utxoSource == nil {
+		return nil, ErrPayerMissingUTXOSource
+	} else if changeProvider == nil {
+		return nil, ErrPayerMissingChangeProvider
+	} else if signer == nil {
+		return nil, ErrPayerMissingSigner
+	}
+
+	resolver, err := NewResolver(client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Overwrite the default options
+	options := &PayerOptions{feeRate: defaultPayerFeeRate}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &Payer{
+		changeProvider: changeProvider,
+		client:         client,
+		options:        options,
+		resolver:       resolver,
+		signer:         signer,
+		utxoSource:     utxoSource,
+	}, nil
+}
+
+// Pay will pay the given paymail address
+//
+// The provider is discovered, the outputs are requested, the transaction is built and signed
+// and then sent to the provider (or broadcast). The txid returned is checked against the transaction.
+func (p *Payer) Pay(ctx context.Context, payment *Payment) (*PaymentResult, error) {
+	// Basic requirements for the payment
+	if payment == nil {
+		return nil, ErrPayerMissingPayment
+	} else if payment.Satoshis == 0 {
+		return nil, ErrPayerMissingSatoshis
+	}
+
+	// Discover the provider
+	endpoint, err := p.resolver.Resolve(ctx, payment.To)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the outputs for the payment
+	result := &PaymentResult{Endpoint: endpoint}
+	var outputs []*PaymentOutput
+	if outputs, err = p.getOutputs(ctx, endpoint, payment, result); err != nil {
+		return nil, err
+	}
+
+	// Build and sign the transaction
+	if result.Transaction, result.Fee, err = p.buildTransaction(ctx, outputs); err != nil {
+		return nil, err
+	}
+	if err = p.signer.Sign(ctx, result.Transaction); err != nil {
+		return nil, err
+	}
+	result.TxID = result.Transaction.TxID().String()
+
+	// Deliver the transaction
+	var txID string
+	if result.Method == PaymentMethodBasic {
+		txID, err = p.broadcast(ctx, result.Transaction)
+	} else {
+		txID, err = p.sendTransaction(ctx, endpoint, payment, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the provider accepted the same transaction
+	if txID != result.TxID {
+		return nil, fmt.Errorf("expected %s, got %s: %w", result.TxID, txID, ErrPayerTxIDMismatch)
+	}
+	return result, nil
+}
+
+// getOutputs will get the outputs from the provider (P2P payment destination or basic address resolution)
+//
+// The payment method is set on the result
+func (p *Payer) getOutputs(ctx context.Context, endpoint *PaymailEndpoint, payment *Payment,
+	result *PaymentResult,
+) ([]*PaymentOutput, error) {
+	// P2P payment destination (requires a way to send the transaction)
+	if p2pURL := endpoint.P2PPaymentDestinationURL(); len(p2pURL) > 0 &&
+		(len(endpoint.BeefTransactionURL()) > 0 || len(endpoint.P2PTransactionsURL()) > 0) {
+		destination, err := p.client.GetP2PPaymentDestinationCtx(
+			ctx, p2pURL, endpoint.Alias, endpoint.Domain, &PaymentRequest{Satoshis: payment.Satoshis},
+		)
+		if err != nil {
+			return nil, err
+		}
+		result.Method = PaymentMethodP2P
+		if len(endpoint.BeefTransactionURL()) > 0 {
+			result.Method = PaymentMethodBEEF
+		}
+		result.Reference = destination.Reference
+		return destination.Outputs, nil
+	}
+
+	// Basic address resolution
+	resolutionURL := endpoint.PaymentDestinationURL()
+	if len(resolutionURL) == 0 {
+		return nil, fmt.Errorf("%s: %w", endpoint.Address, ErrPayerUnsupportedProvider)
+	} else if p.options.broadcaster == nil {
+		return nil, ErrPayerMissingBroadcaster
+	}
+
+	senderRequest, err := newPayerSenderRequest(endpoint, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolution *ResolutionResponse
+	if resolution, err = p.client.ResolveAddressCtx(
+		ctx, resolutionURL, endpoint.Alias, endpoint.Domain, senderRequest,
+	); err != nil {
+		return nil, err
+	}
+	result.Method = PaymentMethodBasic
+	return []*PaymentOutput{{
+		Address:  resolution.Address,
+		Satoshis: payment.Satoshis,
+		Script:   resolution.Output,
+	}}, nil
+}
+
+// newPayerSenderRequest will create the (signed) sender request for basic address resolution
+func newPayerSenderRequest(endpoint *PaymailEndpoint, payment *Payment) (*SenderRequest, error) {
+	if len(payment.SenderHandle) == 0 {
+		return nil, ErrPayerMissingSenderHandle
+	} else if endpoint.SenderValidation() && payment.SenderKey == nil {
+		return nil, ErrPayerMissingSenderKey
+	}
+
+	senderRequest := &SenderRequest{
+		Amount:       payment.Satoshis,
+		Dt:           time.Now().UTC().Format(time.RFC3339),
+		Purpose:      payment.Note,
+		SenderHandle: payment.SenderHandle,
+		SenderName:   payment.SenderName,
+	}
+
+	// Sign the request (if we have a key)
+	if payment.SenderKey != nil {
+		signature, err := bsm.SignMessage(payment.SenderKey, prepareMessage(senderRequest))
+		if err != nil {
+			return nil, err
+		}
+		senderRequest.Signature = EncodeSignature(signature)
+	}
+	return senderRequest, nil
+}
+
+// buildTransaction will build the (unsigned) transaction with the outputs, inputs and change
+func (p *Payer) buildTransaction(ctx context.Context, outputs []*PaymentOutput) (*transaction.Transaction, uint64, error) {
+	if len(outputs) == 0 {
+		return nil, 0, ErrPayerMissingOutputs
+	}
+
+	// Add the outputs from the provider
+	tx := transaction.NewTransaction()
+	var total uint64
+	for _, output := range outputs {
+		lockingScript, err := script.NewFromHex(output.Script)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid output script %s: %w", output.Script, err)
+		} else if len(*lockingScript) == 0 {
+			return nil, 0, ErrP2PMissingScript
+		}
+		tx.AddOutput(&transaction.TransactionOutput{LockingScript: lockingScript, Satoshis: output.Satoshis})
+		total += output.Satoshis
+	}
+
+	// Add the change output
+	changeScript, err := p.changeProvider.ChangeScript(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	tx.AddOutput(&transaction.TransactionOutput{Change: true, LockingScript: changeScript})
+
+	// Get the utxos (estimating the fee with one input)
+	var utxos []*PayerUTXO
+	if utxos, err = p.utxoSource.UTXOs(ctx, total+p.estimateFee(tx, 1)); err != nil {
+		return nil, 0, err
+	} else if len(utxos) == 0 {
+		return nil, 0, ErrPayerMissingUTXOs
+	}
+
+	// Add the inputs
+	var totalIn uint64
+	for _, utxo := range utxos {
+		var input *transaction.TransactionInput
+		if input, err = newPayerInput(utxo); err != nil {
+			return nil, 0, err
+		}
+		tx.AddInput(input)
+		totalIn += utxo.Satoshis
+	}
+
+	// Set the change (dropped if it does not cover its own fee)
+	fee := p.estimateFee(tx, 0)
+	if totalIn < total+fee {
+		tx.Outputs = tx.Outputs[:len(tx.Outputs)-1]
+		if fee = p.estimateFee(tx, 0); totalIn < total+fee {
+			return nil, 0, fmt.Errorf("need %d, have %d: %w", total+fee, totalIn, ErrPayerInsufficientFunds)
+		}
+		return tx, totalIn - total, nil
+	} else if change := totalIn - total - fee; change > 0 {
+		tx.Outputs[len(tx.Outputs)-1].Satoshis = change
+		return tx, fee, nil
+	}
+	tx.Outputs = tx.Outputs[:len(tx.Outputs)-1]
+	return tx, fee, nil
+}
+
+// newPayerInput will create the transaction input for the utxo
+func newPayerInput(utxo *PayerUTXO) (*transaction.TransactionInput, error) {
+	txID, err := chainhash.NewHashFromHex(utxo.TxID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid utxo txid %s: %w", utxo.TxID, err)
+	}
+	input := &transaction.TransactionInput{
+		SequenceNumber:    transaction.DefaultSequenceNumber,
+		SourceTXID:        txID,
+		SourceTxOutIndex:  utxo.Vout,
+		SourceTransaction: utxo.SourceTransaction,
+	}
+	if utxo.SourceTransaction == nil {
+		input.SetSourceTxOutput(&transaction.TransactionOutput{
+			LockingScript: utxo.LockingScript,
+			Satoshis:      utxo.Satoshis,
+		})
+	}
+	return input, nil
+}
+
+// estimateFee will estimate the fee of the transaction using the signer for the unlocking scripts
+//
+// Extra inputs (not yet added) are estimated as P2PKH inputs (the signer only estimates existing inputs)
+func (p *Payer) estimateFee(tx *transaction.Transaction, extraInputs int) uint64 {
+	inputs := len(tx.Inputs) + extraInputs
+	size := 4 + util.VarInt(inputs).Length() + 4
+	for vin := range tx.Inputs {
+		scriptLen := int(p.signer.EstimateLength(tx, uint32(vin))) //nolint:gosec // input count is bounded by the transaction size
+		size += 40 + util.VarInt(scriptLen).Length() + scriptLen
+	}
+	if extraInputs > 0 {
+		scriptLen := p2pkhUnlockingScriptLength
+		size += extraInputs * (40 + util.VarInt(scriptLen).Length() + scriptLen)
+	}
+	size += util.VarInt(len(tx.Outputs)).Length()
+	for _, output := range tx.Outputs {
+		size += 8 + util.VarInt(len(*output.LockingScript)).Length() + len(*output.LockingScript)
+	}
+	return (uint64(size)*p.options.feeRate + 999) / 1000 //nolint:gosec // size is never negative
+}
+
+// sendTransaction will send the transaction to the provider (BEEF if supported, hex otherwise)
+func (p *Payer) sendTransaction(ctx context.Context, endpoint *PaymailEndpoint, payment *Payment,
+	result *PaymentResult,
+) (string, error) {
+	p2pTransaction := &P2PTransaction{
+		MetaData: &P2PMetaData{
+			Note:   payment.Note,
+			Sender: payment.SenderHandle,
+		},
+		Reference: result.Reference,
+	}
+
+	// Sign the txid (if we have a key)
+	if payment.SenderKey != nil {
+		signature, err := bsm.SignMessage(payment.SenderKey, []byte(result.TxID))
+		if err != nil {
+			return "", err
+		}
+		p2pTransaction.MetaData.PublicKey = payment.SenderKey.PubKey().ToDERHex()
+		p2pTransaction.MetaData.Signature = EncodeSignature(signature)
+	}
+
+	// Prefer BEEF, use hex if the ancestry is not available
+	p2pURL := endpoint.BeefTransactionURL()
+	if beefHex, err := result.Transaction.BEEFHex(); err == nil && len(p2pURL) > 0 {
+		p2pTransaction.Beef = beefHex
+	} else if p2pURL = endpoint.P2PTransactionsURL(); len(p2pURL) > 0 {
+		p2pTransaction.Hex = result.Transaction.Hex()
+		result.Method = PaymentMethodP2P
+	} else {
+		return "", fmt.Errorf("failed to build beef: %w", err)
+	}
+
+	response, err := p.client.SendP2PTransactionCtx(ctx, p2pURL, endpoint.Alias, endpoint.Domain, p2pTransaction)
+	if err != nil {
+		return "", err
+	}
+	result.Note = response.Note
+	return response.TxID, nil
+}
+
+// broadcast will broadcast the transaction using the broadcaster
+func (p *Payer) broadcast(ctx context.Context, tx *transaction.Transaction) (string, error) {
+	success, failure := p.options.broadcaster.BroadcastCtx(ctx, tx)
+	if failure != nil {
+		return "", fmt.Errorf("code %s, message: %s: %w", failure.Code, failure.Description, ErrPayerBroadcastFailed)
+	} else if success == nil {
+		return "", ErrPayerBroadcastFailed
+	}
+	return success.Txid, nil
+}
+
+// P2PKHSigner is a Signer for P2PKH inputs locked to a single private key
+type P2PKHSigner struct {
+	privateKey *primitives.PrivateKey
+}
+
+// NewP2PKHSigner will create a new P2PKH signer for the given private key
+func NewP2PKHSigner(privateKey *primitives.PrivateKey) *P2PKHSigner {
+	return &P2PKHSigner{privateKey: privateKey}
+}
+
+// EstimateLength will return the length of a P2PKH unlocking script
+func (s *P2PKHSigner) EstimateLength(_ *transaction.Transaction, _ uint32) uint32 {
+	return p2pkhUnlockingScriptLength
+}
+
+// Sign will sign every input of the transaction
+func (s *P2PKHSigner) Sign(_ context.Context, tx *transaction.Transaction) error {
+	unlocker, err := p2pkh.Unlock(s.privateKey, nil)
+	if err != nil {
+		return err
+	}
+	for vin, input := range tx.Inputs {
+		if input.UnlockingScript, err = unlocker.Sign(tx, uint32(vin)); err != nil { //nolint:gosec // input count is bounded by the transaction size
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,511 @@
+package paymail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	primitives "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPayerSourceTxID = "7f34b7cdd1dc0dfa4c2d1b8fe76b7e0b9d7b8ba1a6d28e9e2c6c0e9b4d4e6f01"
+	testPayerReference  = "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7"
+	testPayerScript     = "76a9143e2d1d795f8acaa7957045cc59376177eb04a3c588ac"
+)
+
+// testUTXOSource is a UTXOSource returning fixed utxos
+type testUTXOSource struct {
+	err       error
+	requested uint64
+	utxos     []*PayerUTXO
+}
+
+// UTXOs will return the fixed utxos
+func (s *testUTXOSource) UTXOs(_ context.Context, satoshis uint64) ([]*PayerUTXO, error) {
+	s.requested = satoshis
+	return s.utxos, s.err
+}
+
+// testChangeProvider is a ChangeProvider returning a fixed script
+type testChangeProvider struct {
+	changeScript *script.Script
+	err          error
+}
+
+// ChangeScript will return the fixed script
+func (p *testChangeProvider) ChangeScript(_ context.Context) (*script.Script, error) {
+	return p.changeScript, p.err
+}
+
+// testBroadcaster is a transaction.Broadcaster returning a fixed result
+type testBroadcaster struct {
+	failure *transaction.BroadcastFailure
+	txID    string
+}
+
+// Broadcast will broadcast the transaction
+func (b *testBroadcaster) Broadcast(tx *transaction.Transaction) (*transaction.BroadcastSuccess, *transaction.BroadcastFailure) {
+	return b.BroadcastCtx(context.Background(), tx)
+}
+
+// BroadcastCtx will return the txid of the transaction (or the fixed txid/failure)
+func (b *testBroadcaster) BroadcastCtx(_ context.Context, tx *transaction.Transaction) (*transaction.BroadcastSuccess, *transaction.BroadcastFailure) {
+	if b.failure != nil {
+		return nil, b.failure
+	} else if len(b.txID) > 0 {
+		return &transaction.BroadcastSuccess{Txid: b.txID}, nil
+	}
+	return &transaction.BroadcastSuccess{Txid: tx.TxID().String()}, nil
+}
+
+// testInputSigner is a P2PKH signer looking up the input it estimates (as a signer choosing a key per input)
+type testInputSigner struct {
+	*P2PKHSigner
+}
+
+// EstimateLength will return the length of a P2PKH unlocking script (the input must exist)
+func (s *testInputSigner) EstimateLength(tx *transaction.Transaction, inputIndex uint32) uint32 {
+	if tx.Inputs[inputIndex].SourceTxOutput() == nil {
+		return 0
+	}
+	return s.P2PKHSigner.EstimateLength(tx, inputIndex)
+}
+
+// newTestPayerKey will return a new private key and its P2PKH locking script
+func newTestPayerKey(t testing.TB) (*primitives.PrivateKey, *script.Script) {
+	key, err := primitives.NewPrivateKey()
+	if t != nil {
+		require.NoError(t, err)
+	}
+	address, _ := script.NewAddressFromPublicKey(key.PubKey(), true)
+	lockingScript, _ := p2pkh.Lock(address)
+	return key, lockingScript
+}
+
+// newTestPayer will return a payer funded with a single utxo
+func newTestPayer(t testing.TB, satoshis uint64, opts ...PayerOps) (*Payer, *testUTXOSource) {
+	_, client := newTestResolver(nil)
+
+	key, lockingScript := newTestPayerKey(t)
+	source := &testUTXOSource{utxos: []*PayerUTXO{{
+		LockingScript: lockingScript,
+		Satoshis:      satoshis,
+		TxID:          testPayerSourceTxID,
+		Vout:          0,
+	}}}
+
+	payer, err := NewPayer(client, source, &testChangeProvider{changeScript: lockingScript}, NewP2PKHSigner(key), opts...)
+	if t != nil {
+		require.NoError(t, err)
+		require.NotNil(t, payer)
+	}
+	return payer, source
+}
+
+// newTestSourceTransaction will return a mined transaction paying the given locking script
+func newTestSourceTransaction(t testing.TB, lockingScript *script.Script, satoshis uint64) *transaction.Transaction {
+	tx := transaction.NewTransaction()
+	require.NoError(t, tx.AddInputFrom(testPayerSourceTxID, 0, testPayerScript, satoshis+1, nil))
+	tx.Inputs[0].UnlockingScript = &script.Script{}
+	tx.AddOutput(&transaction.TransactionOutput{LockingScript: lockingScript, Satoshis: satoshis})
+
+	isTxID := true
+	tx.MerklePath = &transaction.MerklePath{
+		BlockHeight: 800000,
+		Path:        [][]*transaction.PathElement{{{Offset: 0, Hash: tx.TxID(), Txid: &isTxID}}},
+	}
+	return tx
+}
+
+// mockPayerCapabilities is used for mocking the capabilities response (with the given capabilities)
+func mockPayerCapabilities(host, capabilities string) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, "https://"+host+":443/.well-known/"+DefaultServiceName,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {`+capabilities+`}}`,
+		),
+	)
+}
+
+// mockPayerP2P is used for mocking the P2P payment destination and receive transaction responses
+//
+// The receive transaction responders return the txid of the received transaction (or the given txid)
+func mockPayerP2P(txID string) {
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"p2p-payment-destination/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"outputs": [{"script": "`+testPayerScript+`","satoshis": 1000}],"reference": "`+testPayerReference+`"}`,
+		),
+	)
+
+	receive := func(req *http.Request) (*http.Response, error) {
+		p2pTransaction := new(P2PTransaction)
+		if err := json.NewDecoder(req.Body).Decode(p2pTransaction); err != nil {
+			return httpmock.NewStringResponse(http.StatusBadRequest, `{"message": "bad request"}`), nil
+		}
+		var tx *transaction.Transaction
+		var err error
+		if len(p2pTransaction.Beef) > 0 {
+			tx, err = transaction.NewTransactionFromBEEFHex(p2pTransaction.Beef)
+		} else {
+			tx, err = transaction.NewTransactionFromHex(p2pTransaction.Hex)
+		}
+		if err != nil {
+			return httpmock.NewStringResponse(http.StatusBadRequest, `{"message": "invalid transaction"}`), nil
+		}
+		returnedTxID := tx.TxID().String()
+		if len(txID) > 0 {
+			returnedTxID = txID
+		}
+		return httpmock.NewStringResponse(http.StatusOK, `{"txid": "`+returnedTxID+`", "note": "thanks"}`), nil
+	}
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"beef/"+testAlias+"@"+testDomain, receive)
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testAlias+"@"+testDomain, receive)
+}
+
+// mockPayerResolveAddress is used for mocking the basic address resolution response
+func mockPayerResolveAddress() {
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"address/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"output": "`+testPayerScript+`"}`,
+		),
+	)
+}
+
+// TestNewPayer will test the method NewPayer()
+func TestNewPayer(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	key, lockingScript := newTestPayerKey(t)
+	source := &testUTXOSource{}
+	change := &testChangeProvider{changeScript: lockingScript}
+	signer := NewP2PKHSigner(key)
+
+	t.Run("valid payer", func(t *testing.T) {
+		payer, err := NewPayer(client, source, change, signer)
+		require.NoError(t, err)
+		require.NotNil(t, payer)
+		assert.Equal(t, uint64(defaultPayerFeeRate), payer.options.feeRate)
+		assert.Nil(t, payer.options.broadcaster)
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		broadcaster := &testBroadcaster{}
+		payer, err := NewPayer(client, source, change, signer, WithPayerFeeRate(50), WithPayerBroadcaster(broadcaster))
+		require.NoError(t, err)
+		require.NotNil(t, payer)
+		assert.Equal(t, uint64(50), payer.options.feeRate)
+		assert.Equal(t, broadcaster, payer.options.broadcaster)
+	})
+
+	t.Run("missing requirements", func(t *testing.T) {
+		_, err := NewPayer(nil, source, change, signer)
+		require.ErrorIs(t, err, ErrPayerMissingClient)
+		_, err = NewPayer(client, nil, change, signer)
+		require.ErrorIs(t, err, ErrPayerMissingUTXOSource)
+		_, err = NewPayer(client, source, nil, signer)
+		require.ErrorIs(t, err, ErrPayerMissingChangeProvider)
+		_, err = NewPayer(client, source, change, nil)
+		require.ErrorIs(t, err, ErrPayerMissingSigner)
+	})
+}
+
+// TestPayer_Pay will test the method Pay()
+func TestPayer_Pay(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	ctx := context.Background()
+	testPaymail := testAlias + "@" + testDomain
+
+	t.Run("p2p payment with hex (no ancestry)", func(t *testing.T) {
+		payer, source := newTestPayer(t, 10000)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		result, err := payer.Pay(ctx, &Payment{Note: "coffee", Satoshis: 1000, To: testPaymail})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, PaymentMethodP2P, result.Method)
+		assert.Equal(t, testPayerReference, result.Reference)
+		assert.Equal(t, "thanks", result.Note)
+		assert.Equal(t, result.Transaction.TxID().String(), result.TxID)
+		assert.Equal(t, testSRVTarget, result.Endpoint.Host)
+		assert.Greater(t, source.requested, uint64(1000))
+
+		// Payment output and change
+		require.Len(t, result.Transaction.Outputs, 2)
+		assert.Equal(t, testPayerScript, result.Transaction.Outputs[0].LockingScript.String())
+		assert.Equal(t, uint64(1000), result.Transaction.Outputs[0].Satoshis)
+		assert.Equal(t, uint64(10000-1000)-result.Fee, result.Transaction.Outputs[1].Satoshis)
+		assert.Positive(t, result.Fee)
+
+		// Inputs are signed
+		require.Len(t, result.Transaction.Inputs, 1)
+		require.NotNil(t, result.Transaction.Inputs[0].UnlockingScript)
+		assert.InDelta(t, p2pkhUnlockingScriptLength, len(*result.Transaction.Inputs[0].UnlockingScript), 2)
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST "+testServerURL+"receive-transaction/"+testPaymail])
+	})
+
+	t.Run("signer estimating existing inputs only", func(t *testing.T) {
+		_, client := newTestResolver(nil)
+		key, lockingScript := newTestPayerKey(t)
+		source := &testUTXOSource{utxos: []*PayerUTXO{{LockingScript: lockingScript, Satoshis: 10000, TxID: testPayerSourceTxID}}}
+		payer, err := NewPayer(client, source, &testChangeProvider{changeScript: lockingScript}, &testInputSigner{NewP2PKHSigner(key)})
+		require.NoError(t, err)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		result, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.NoError(t, err)
+		assert.Positive(t, result.Fee)
+	})
+
+	t.Run("p2p payment with beef", func(t *testing.T) {
+		payer, source := newTestPayer(t, 10000)
+		source.utxos[0].SourceTransaction = newTestSourceTransaction(t, source.utxos[0].LockingScript, 10000)
+		source.utxos[0].TxID = source.utxos[0].SourceTransaction.TxID().String()
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		result, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, PaymentMethodBEEF, result.Method)
+		assert.Equal(t, result.Transaction.TxID().String(), result.TxID)
+		assert.Equal(t, 1, httpmock.GetCallCountInfo()["POST "+testServerURL+"beef/"+testPaymail])
+	})
+
+	t.Run("p2p payment with sender metadata", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000)
+		senderKey, err := primitives.NewPrivateKey()
+		require.NoError(t, err)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		var metadata *P2PMetaData
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testPaymail,
+			func(req *http.Request) (*http.Response, error) {
+				p2pTransaction := new(P2PTransaction)
+				if err := json.NewDecoder(req.Body).Decode(p2pTransaction); err != nil {
+					return nil, err
+				}
+				metadata = p2pTransaction.MetaData
+				tx, err := transaction.NewTransactionFromHex(p2pTransaction.Hex)
+				if err != nil {
+					return nil, err
+				}
+				return httpmock.NewStringResponse(http.StatusOK, `{"txid": "`+tx.TxID().String()+`"}`), nil
+			},
+		)
+
+		result, err := payer.Pay(ctx, &Payment{
+			Note:         "coffee",
+			Satoshis:     1000,
+			SenderHandle: "sender@" + testDomain,
+			SenderKey:    senderKey,
+			To:           testPaymail,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, metadata)
+		assert.Equal(t, "coffee", metadata.Note)
+		assert.Equal(t, "sender@"+testDomain, metadata.Sender)
+		assert.Equal(t, senderKey.PubKey().ToDERHex(), metadata.PublicKey)
+		assert.NotEmpty(t, metadata.Signature)
+		assert.NotEmpty(t, result.TxID)
+	})
+
+	t.Run("basic address resolution with broadcaster", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000, WithPayerBroadcaster(&testBroadcaster{}))
+		senderKey, err := primitives.NewPrivateKey()
+		require.NoError(t, err)
+
+		mockPayerCapabilities(testSRVTarget, `"6745385c3fc0": true,
+"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}"`)
+		mockPayerResolveAddress()
+
+		result, err := payer.Pay(ctx, &Payment{
+			Satoshis:     1000,
+			SenderHandle: "sender@" + testDomain,
+			SenderKey:    senderKey,
+			To:           testPaymail,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, PaymentMethodBasic, result.Method)
+		assert.Empty(t, result.Reference)
+		assert.Equal(t, testPayerScript, result.Transaction.Outputs[0].LockingScript.String())
+		assert.Equal(t, uint64(1000), result.Transaction.Outputs[0].Satoshis)
+	})
+
+	t.Run("basic address resolution without broadcaster", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000)
+
+		mockPayerCapabilities(testSRVTarget, `"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}"`)
+
+		result, err := payer.Pay(ctx, &Payment{Satoshis: 1000, SenderHandle: "sender@" + testDomain, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerMissingBroadcaster)
+		assert.Nil(t, result)
+	})
+
+	t.Run("basic address resolution requires a sender handle", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000, WithPayerBroadcaster(&testBroadcaster{}))
+
+		mockPayerCapabilities(testSRVTarget, `"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}"`)
+
+		_, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerMissingSenderHandle)
+	})
+
+	t.Run("basic address resolution requires a sender key (sender validation)", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000, WithPayerBroadcaster(&testBroadcaster{}))
+
+		mockPayerCapabilities(testSRVTarget, `"6745385c3fc0": true,
+"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}"`)
+
+		_, err := payer.Pay(ctx, &Payment{Satoshis: 1000, SenderHandle: "sender@" + testDomain, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerMissingSenderKey)
+	})
+
+	t.Run("broadcast failure", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000, WithPayerBroadcaster(&testBroadcaster{
+			failure: &transaction.BroadcastFailure{Code: "500", Description: "rejected"},
+		}))
+
+		mockPayerCapabilities(testSRVTarget, `"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}"`)
+		mockPayerResolveAddress()
+
+		_, err := payer.Pay(ctx, &Payment{Satoshis: 1000, SenderHandle: "sender@" + testDomain, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerBroadcastFailed)
+		assert.Contains(t, err.Error(), "rejected")
+	})
+
+	t.Run("unsupported provider", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000)
+
+		mockPayerCapabilities(testSRVTarget, `"pki": "`+testServerURL+`id/{alias}@{domain.tld}"`)
+
+		_, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerUnsupportedProvider)
+	})
+
+	t.Run("txid mismatch", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P(testPayerSourceTxID)
+
+		result, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerTxIDMismatch)
+		assert.Nil(t, result)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 1000)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		_, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerInsufficientFunds)
+		assert.Equal(t, 0, httpmock.GetCallCountInfo()["POST "+testServerURL+"receive-transaction/"+testPaymail])
+	})
+
+	t.Run("change below the fee is left to miners", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 1021)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		result, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.NoError(t, err)
+		require.Len(t, result.Transaction.Outputs, 1)
+		assert.Equal(t, uint64(21), result.Fee)
+	})
+
+	t.Run("utxo source errors", func(t *testing.T) {
+		payer, source := newTestPayer(t, 10000)
+		source.err = errors.New("no funds")
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		_, err := payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.EqualError(t, err, "no funds")
+
+		source.err = nil
+		source.utxos = nil
+		_, err = payer.Pay(ctx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerMissingUTXOs)
+	})
+
+	t.Run("invalid payments", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000)
+
+		_, err := payer.Pay(ctx, nil)
+		require.ErrorIs(t, err, ErrPayerMissingPayment)
+		_, err = payer.Pay(ctx, &Payment{To: testPaymail})
+		require.ErrorIs(t, err, ErrPayerMissingSatoshis)
+		_, err = payer.Pay(ctx, &Payment{Satoshis: 1000, To: "invalid"})
+		require.Error(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		payer, _ := newTestPayer(t, 10000)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+		mockPayerP2P("")
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := payer.Pay(canceledCtx, &Payment{Satoshis: 1000, To: testPaymail})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// ExamplePayer_Pay example using Pay()
+//
+// See more examples in /examples/
+func ExamplePayer_Pay() {
+	// Load the payer (with a test utxo source, change provider and signer)
+	payer, _ := newTestPayer(nil, 10000)
+
+	mockResolverCapabilities(testSRVTarget, http.StatusOK)
+	mockPayerP2P("")
+
+	// Pay the paymail
+	result, err := payer.Pay(context.Background(), &Payment{Satoshis: 1000, To: testAlias + "@" + testDomain})
+	if err != nil {
+		fmt.Printf("error paying paymail: %s", err.Error())
+		return
+	}
+	fmt.Printf("paid using: %s reference: %s", result.Method, result.Reference)
+	// Output:paid using: p2p reference: z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7
+}
+
+// BenchmarkPayer_Pay benchmarks the method Pay()
+func BenchmarkPayer_Pay(b *testing.B) {
+	payer, _ := newTestPayer(b, 10000)
+	mockResolverCapabilities(testSRVTarget, http.StatusOK)
+	mockPayerP2P("")
+	payment := &Payment{Satoshis: 1000, To: testAlias + "@" + testDomain}
+	for i := 0; i < b.N; i++ {
+		_, _ = payer.Pay(context.Background(), payment)
+	}
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -61,11 +62,19 @@ type OutputTemplate struct {
 }
 
 func (c *Client) AddContactRequest(url, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
-	if err := c.validateUrlWithPaymail(url, alias, domain); err != nil {
+	return c.AddContactRequestCtx(context.Background(), url, alias, domain, request)
+}
+
+// AddContactRequestCtx is the context-aware version of AddContactRequest
+func (c *Client) AddContactRequestCtx(ctx context.Context, url, alias, domain string, request *PikeContactRequestPayload) (_ *PikeContactRequestResponse, err error) {
+	ctx, end := c.startSpan(ctx, "AddContactRequest", BRFCPike, domain)
+	defer func() { end(err) }()
+
+	if err = c.validateUrlWithPaymail(url, alias, domain); err != nil {
 		return nil, err
 	}
 
-	if err := request.validate(); err != nil {
+	if err = request.validate(); err != nil {
 		return nil, err
 	}
 
@@ -73,17 +82,16 @@ func (c *Client) AddContactRequest(url, alias, domain string, request *PikeConta
 	// https://<host-discovery-target>/{alias}@{domain.tld}/id
 	reqURL := replaceAliasDomain(url, alias, domain)
 
-	response, err := c.postRequest(reqURL, request)
+	response, err := c.postRequest(ctx, reqURL, request)
 	if err != nil {
 		return nil, err
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
 		if response.StatusCode == http.StatusNotFound {
-			return nil, ErrPikeAddressNotFound
-		} else {
-			return nil, c.prepareServerErrorResponse(&response)
+			return nil, newResponseError(&response, reqURL, BRFCPike, ErrPikeAddressNotFound)
 		}
+		return nil, newResponseError(&response, reqURL, BRFCPike, ErrPikeBadResponse)
 	}
 
 	return &PikeContactRequestResponse{response}, nil
@@ -100,19 +108,6 @@ func (c *Client) validateUrlWithPaymail(url, alias, domain string) error {
 	return nil
 }
 
-func (c *Client) prepareServerErrorResponse(response *StandardResponse) error {
-	var details string
-
-	serverError := &ServerError{}
-	if err := json.Unmarshal(response.Body, serverError); err != nil || serverError.Message == "" {
-		details = fmt.Sprintf("body: %s", string(response.Body))
-	} else {
-		details = fmt.Sprintf("message: %s", serverError.Message)
-	}
-
-	return fmt.Errorf("code %d, %s: %w", response.StatusCode, details, ErrPikeBadResponse)
-}
-
 func (r *PikeContactRequestPayload) validate() error {
 	if r.FullName == "" {
 		return ErrPikeMissingFullName
@@ -126,6 +121,14 @@ func (r *PikeContactRequestPayload) validate() error {
 
 // GetOutputsTemplate calls the PIKE capability outputs subcapability
 func (c *Client) GetOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error) {
+	return c.GetOutputsTemplateCtx(context.Background(), pikeURL, alias, domain, payload)
+}
+
+// GetOutputsTemplateCtx is the context-aware version of GetOutputsTemplate
+func (c *Client) GetOutputsTemplateCtx(ctx context.Context, pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error) {
+	ctx, end := c.startSpan(ctx, "GetOutputsTemplate", BRFCPike, domain)
+	defer func() { end(err) }()
+
 	// Require a valid URL
 	if len(pikeURL) == 0 || !strings.Contains(pikeURL, "https://") {
 		err = fmt.Errorf("url %s: %w", pikeURL, ErrPikeInvalidURL)
@@ -152,13 +155,13 @@ func (c *Client) GetOutputsTemplate(pikeURL, alias, domain string, payload *Pike
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, payload); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, payload); err != nil {
 		return response, err
 	}
 
 	// Test the status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("code %d: %w", resp.StatusCode, ErrPikeBadOutputsResponse)
+		return nil, newResponseError(&resp, reqURL, BRFCPike, ErrPikeBadOutputsResponse)
 	}
 
 	// Decode the body of the response
@@ -172,5 +175,10 @@ func (c *Client) GetOutputsTemplate(pikeURL, alias, domain string, payload *Pike
 
 // AddInviteRequest sends a contact request using the invite URL from capabilities
 func (c *Client) AddInviteRequest(inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
-	return c.AddContactRequest(inviteURL, alias, domain, request)
+	return c.AddInviteRequestCtx(context.Background(), inviteURL, alias, domain, request)
+}
+
+// AddInviteRequestCtx is the context-aware version of AddInviteRequest
+func (c *Client) AddInviteRequestCtx(ctx context.Context, inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
+	return c.AddContactRequestCtx(ctx, inviteURL, alias, domain, request)
 }
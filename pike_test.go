@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -80,6 +81,22 @@ func TestClient_GetOutputsTemplate(t *testing.T) {
 		require.Error(t, err)
 		require.Nil(t, response)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockPIKEOutputs(http.StatusOK, 1000)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		outputsURL := "https://" + testDomain + "/v1/bsvalias/pike/outputs/{alias}@{domain.tld}"
+		payload := &PikePaymentOutputsPayload{
+			SenderPaymail: "joedoe@example.com",
+			Amount:        1000,
+		}
+		response, err := client.GetOutputsTemplateCtx(ctx, outputsURL, "alias", "domain.tld", payload)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, response)
+	})
 }
 
 // mockPIKEOutputs is used for mocking the PIKE outputs response
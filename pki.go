@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -49,6 +50,14 @@ type PKIPayload struct {
 //
 // Specs: http://bsvalias.org/03-public-key-infrastructure.html
 func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, err error) {
+	return c.GetPKICtx(context.Background(), pkiURL, alias, domain)
+}
+
+// GetPKICtx is the context-aware version of GetPKI
+func (c *Client) GetPKICtx(ctx context.Context, pkiURL, alias, domain string) (response *PKIResponse, err error) {
+	ctx, end := c.startSpan(ctx, "GetPKI", BRFCPki, domain)
+	defer func() { end(err) }()
+
 	// Require a valid url
 	if len(pkiURL) == 0 || !strings.Contains(pkiURL, "https://") {
 		err = fmt.Errorf("url %s: %w", pkiURL, ErrPKIInvalidURL)
@@ -68,9 +77,9 @@ func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, er
 	// https://<host-discovery-target>/{alias}@{domain.tld}/id
 	reqURL := replaceAliasDomain(pkiURL, alias, domain)
 
-	// Fire the GET request
+	// Fire the GET request (or use the cached response)
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getCachedRequest(ctx, reqURL); err != nil {
 		return response, err
 	}
 
@@ -79,11 +88,7 @@ func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, er
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return response, err
-		}
-		err = fmt.Errorf("code %d, message: %s: %w", response.StatusCode, serverError.Message, ErrPKIBadResponse)
+		err = newResponseError(&resp, reqURL, BRFCPki, ErrPKIBadResponse)
 		return response, err
 	}
 
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -212,6 +213,19 @@ func TestClient_GetPKI(t *testing.T) {
 		require.Error(t, err)
 		require.Nil(t, pki)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPKI(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		pki, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, pki)
+	})
 }
 
 // mockGetPKI is used for mocking the response
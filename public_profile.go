@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -43,6 +44,14 @@ type PublicProfilePayload struct {
 //
 // Specs: https://github.com/bitcoin-sv-specs/brfc-paymail/pull/7/files
 func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error) {
+	return c.GetPublicProfileCtx(context.Background(), publicProfileURL, alias, domain)
+}
+
+// GetPublicProfileCtx is the context-aware version of GetPublicProfile
+func (c *Client) GetPublicProfileCtx(ctx context.Context, publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error) {
+	ctx, end := c.startSpan(ctx, "GetPublicProfile", BRFCPublicProfile, domain)
+	defer func() { end(err) }()
+
 	// Require a valid url
 	if len(publicProfileURL) == 0 || !strings.Contains(publicProfileURL, "https://") {
 		err = fmt.Errorf("url %s: %w", publicProfileURL, ErrPublicProfileInvalidURL)
@@ -64,7 +73,7 @@ func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (respo
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
 		return response, err
 	}
 
@@ -73,11 +82,7 @@ func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (respo
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return response, err
-		}
-		err = fmt.Errorf("code %d, message: %s: %w", response.StatusCode, serverError.Message, ErrPublicProfileBadResponse)
+		err = newResponseError(&resp, reqURL, BRFCPublicProfile, ErrPublicProfileBadResponse)
 		return response, err
 	}
 
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -135,6 +136,19 @@ func TestClient_GetPublicProfile(t *testing.T) {
 		assert.Empty(t, profile.Name)
 		assert.Empty(t, profile.Avatar)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPublicProfile(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		profile, err := client.GetPublicProfileCtx(ctx, testServerURL+"public-profile/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, profile)
+	})
 }
 
 // mockGetPublicProfile is used for mocking the response
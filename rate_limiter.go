@@ -0,0 +1,132 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is when the request would exceed the rate limit for the host
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitPolicy is the policy used by the per-host rate limiter (token bucket)
+type RateLimitPolicy struct {
+	Burst             int     // Maximum number of requests sent at once (the bucket size)
+	RequestsPerSecond float64 // Sustained number of requests per second (the refill rate)
+}
+
+// RateLimitError is returned when a request cannot be sent before the context deadline
+//
+// It wraps ErrRateLimited, use errors.Is(err, ErrRateLimited) or errors.As(err, &rateErr)
+type RateLimitError struct {
+	Host       string        // The host of the request
+	RetryAfter time.Duration // Time until the request could be sent
+}
+
+// Error will return the error message
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %s (retry after %s)", ErrRateLimited.Error(), e.Host, e.RetryAfter)
+}
+
+// Unwrap will return ErrRateLimited
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// tokenBucket is the rate limit state for a single host
+type tokenBucket struct {
+	tokens  float64   // Available tokens (negative if requests are waiting)
+	updated time.Time // Last time the tokens were refilled
+}
+
+// rateLimiter holds the token buckets for all hosts
+type rateLimiter struct {
+	buckets map[string]*tokenBucket
+	mu      sync.Mutex
+	now     func() time.Time
+	policy  RateLimitPolicy
+}
+
+// newRateLimiter will create a rate limiter (the burst is at least one request)
+func newRateLimiter(policy RateLimitPolicy) *rateLimiter {
+	policy.Burst = max(policy.Burst, 1)
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+		policy:  policy,
+	}
+}
+
+// intercept is the interceptor that waits for a token before sending the request
+//
+// If the token is not available before the context deadline, it fails without waiting.
+// The token is returned if the context is done while waiting (the request is not sent)
+func (l *rateLimiter) intercept(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+	host := requestHost(req.URL)
+
+	maxWait := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxWait = time.Until(deadline)
+	}
+
+	wait, err := l.reserve(host, maxWait)
+	if err != nil {
+		return StandardResponse{}, err
+	}
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			l.release(host)
+			return StandardResponse{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return next(ctx, req)
+}
+
+// reserve will take a token for the host and return the time to wait until it is available
+func (l *rateLimiter) reserve(host string, maxWait time.Duration) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(host)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.policy.RequestsPerSecond * float64(time.Second))
+	if wait > maxWait {
+		return 0, &RateLimitError{Host: host, RetryAfter: wait}
+	}
+	b.tokens--
+	return wait, nil
+}
+
+// release will return a reserved token for the host (the request was not sent)
+func (l *rateLimiter) release(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(host)
+	b.tokens = math.Min(float64(l.policy.Burst), b.tokens+1)
+}
+
+// refill will return the bucket of the host with the tokens refilled (the limiter must be locked)
+func (l *rateLimiter) refill(host string) *tokenBucket {
+	now := l.now()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.policy.Burst), updated: now}
+		l.buckets[host] = b
+	}
+
+	b.tokens = math.Min(float64(l.policy.Burst), b.tokens+now.Sub(b.updated).Seconds()*l.policy.RequestsPerSecond)
+	b.updated = now
+	return b
+}
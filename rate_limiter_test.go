@@ -0,0 +1,198 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRateLimiter_Reserve will test the method reserve()
+func TestRateLimiter_Reserve(t *testing.T) {
+	t.Parallel()
+
+	newTestLimiter := func() (*rateLimiter, *testClock) {
+		limiter := newRateLimiter(RateLimitPolicy{Burst: 2, RequestsPerSecond: 1})
+		clock := &testClock{now: time.Now()}
+		limiter.now = clock.Now
+		return limiter, clock
+	}
+
+	t.Run("burst is sent without waiting", func(t *testing.T) {
+		limiter, _ := newTestLimiter()
+		for i := 0; i < 2; i++ {
+			wait, err := limiter.reserve("test.com", time.Minute)
+			require.NoError(t, err)
+			assert.Zero(t, wait)
+		}
+
+		wait, err := limiter.reserve("test.com", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, wait)
+
+		wait, err = limiter.reserve("test.com", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, wait)
+	})
+
+	t.Run("tokens are refilled", func(t *testing.T) {
+		limiter, clock := newTestLimiter()
+		for i := 0; i < 2; i++ {
+			_, err := limiter.reserve("test.com", 0)
+			require.NoError(t, err)
+		}
+
+		clock.Advance(time.Second)
+		wait, err := limiter.reserve("test.com", 0)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+
+		// The bucket never holds more than the burst
+		clock.Advance(time.Hour)
+		for i := 0; i < 2; i++ {
+			_, err = limiter.reserve("test.com", 0)
+			require.NoError(t, err)
+		}
+		_, err = limiter.reserve("test.com", 0)
+		require.ErrorIs(t, err, ErrRateLimited)
+	})
+
+	t.Run("wait exceeds the max wait", func(t *testing.T) {
+		limiter, _ := newTestLimiter()
+		for i := 0; i < 2; i++ {
+			_, err := limiter.reserve("test.com", 0)
+			require.NoError(t, err)
+		}
+
+		_, err := limiter.reserve("test.com", 500*time.Millisecond)
+		var rateErr *RateLimitError
+		require.ErrorAs(t, err, &rateErr)
+		assert.Equal(t, "test.com", rateErr.Host)
+		assert.Equal(t, time.Second, rateErr.RetryAfter)
+
+		// The token was not taken
+		wait, err := limiter.reserve("test.com", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, wait)
+	})
+
+	t.Run("buckets are per host", func(t *testing.T) {
+		limiter, _ := newTestLimiter()
+		for i := 0; i < 2; i++ {
+			_, err := limiter.reserve("test.com", 0)
+			require.NoError(t, err)
+		}
+
+		wait, err := limiter.reserve("other.com", 0)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("canceled waits return the token", func(t *testing.T) {
+		limiter, _ := newTestLimiter()
+		for i := 0; i < 2; i++ {
+			_, err := limiter.reserve("test.com", 0)
+			require.NoError(t, err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := limiter.intercept(ctx, &Request{URL: "https://test.com/id"}, func(context.Context, *Request) (StandardResponse, error) {
+			return StandardResponse{}, errors.New("request sent")
+		})
+		require.ErrorIs(t, err, context.Canceled)
+
+		// The next request waits for the first token (and not for the one of the canceled request)
+		wait, err := limiter.reserve("test.com", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, wait)
+	})
+}
+
+// TestClient_RateLimit will test the rate limiter set with WithRateLimit()
+func TestClient_RateLimit(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("requests wait for a token", func(t *testing.T) {
+		client := newTestClient(t, WithRateLimit(RateLimitPolicy{Burst: 1, RequestsPerSecond: 50}))
+
+		mockGetPKI(http.StatusOK)
+
+		start := time.Now()
+		require.NoError(t, getTestPKI(client))
+		require.NoError(t, getTestPKI(client))
+		assert.GreaterOrEqual(t, time.Since(start), 15*time.Millisecond)
+		assert.Equal(t, 2, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("fails before the context deadline", func(t *testing.T) {
+		client := newTestClient(t, WithRateLimit(RateLimitPolicy{Burst: 1, RequestsPerSecond: 0.1}))
+
+		mockGetPKI(http.StatusOK)
+		require.NoError(t, getTestPKI(client))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	})
+
+	t.Run("rate limited requests are not circuit failures", func(t *testing.T) {
+		client := newTestClient(t,
+			WithRateLimit(RateLimitPolicy{Burst: 1, RequestsPerSecond: 0.1}),
+			WithCircuitBreaker(CircuitBreakerPolicy{FailureThreshold: 1}),
+		)
+
+		mockGetPKI(http.StatusOK)
+		require.NoError(t, getTestPKI(client))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, ErrRateLimited)
+		assert.Equal(t, CircuitClosed, client.CircuitStates()["test.com"])
+	})
+
+	t.Run("invalid policy is ignored", func(t *testing.T) {
+		client := newTestClient(t, WithRateLimit(RateLimitPolicy{Burst: 1}))
+		c, ok := client.(*Client)
+		require.True(t, ok)
+		assert.Nil(t, c.limiter)
+	})
+}
+
+// ExampleWithRateLimit example using WithRateLimit()
+//
+// See more examples in /examples/
+func ExampleWithRateLimit() {
+	// Load the client (one request every 10 seconds per host)
+	client := newTestClient(nil, WithRateLimit(RateLimitPolicy{Burst: 1, RequestsPerSecond: 0.1}))
+
+	mockGetPKI(http.StatusOK)
+
+	// The second request cannot be sent before the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 2; i++ {
+		_, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		fmt.Println(errors.Is(err, ErrRateLimited))
+	}
+	// Output:false
+	// true
+}
+
+// BenchmarkClient_RateLimit benchmarks a request with the rate limiter enabled
+func BenchmarkClient_RateLimit(b *testing.B) {
+	client := newTestClient(nil, WithRateLimit(RateLimitPolicy{Burst: 1, RequestsPerSecond: 1e9}))
+	mockGetPKI(http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_ = getTestPKI(client)
+	}
+}
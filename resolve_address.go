@@ -1,12 +1,14 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
 	"github.com/bsv-blockchain/go-sdk/script"
 )
 
@@ -31,6 +33,12 @@ var (
 	ErrResolveAddressMissingOutput = errors.New("missing an output value")
 	// ErrResolveAddressInvalidScript is returned when output script is invalid
 	ErrResolveAddressInvalidScript = errors.New("invalid output script, missing an address")
+	// ErrResolveAddressMissingSignature is returned when the output signature is missing (and verification is enabled)
+	ErrResolveAddressMissingSignature = errors.New("missing an output signature")
+	// ErrResolveAddressInvalidSignature is returned when the output signature does not match the receiver's PubKey
+	ErrResolveAddressInvalidSignature = errors.New("invalid output signature")
+	// ErrResolveAddressMissingPKI is returned when the receiver's provider has no PKI capability
+	ErrResolveAddressMissingPKI = errors.New("missing the pki capability to verify the output signature")
 )
 
 // ResolutionResponse is the response from the ResolveAddress() request
@@ -48,8 +56,18 @@ type ResolutionPayload struct {
 
 // ResolveAddress will return a hex-encoded Bitcoin script if successful
 //
+// If WithResolutionVerification is set, the output signature is verified (see ResolutionPayload.Verify).
+//
 // Specs: http://bsvalias.org/04-01-basic-address-resolution.html
 func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
+	return c.ResolveAddressCtx(context.Background(), resolutionURL, alias, domain, senderRequest)
+}
+
+// ResolveAddressCtx is the context-aware version of ResolveAddress
+func (c *Client) ResolveAddressCtx(ctx context.Context, resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
+	ctx, end := c.startSpan(ctx, "ResolveAddress", BRFCPaymentDestination, domain)
+	defer func() { end(err) }()
+
 	// Require a valid url
 	if len(resolutionURL) == 0 || !strings.Contains(resolutionURL, "https://") {
 		err = fmt.Errorf("%s: %s: %w", "invalid url", resolutionURL, ErrResolveAddressInvalidURL)
@@ -83,7 +101,7 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, senderRequest); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, senderRequest); err != nil {
 		return response, err
 	}
 
@@ -95,13 +113,9 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 		// Paymail address not found?
 		if response.StatusCode == http.StatusNotFound {
-			err = ErrResolveAddressNotFound
+			err = newResponseError(&resp, reqURL, BRFCPaymentDestination, ErrResolveAddressNotFound)
 		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return response, err
-			}
-			err = fmt.Errorf("code %d, message: %s: %w", response.StatusCode, serverError.Message, ErrResolveAddressBadResponse)
+			err = newResponseError(&resp, reqURL, BRFCPaymentDestination, ErrResolveAddressBadResponse)
 		}
 
 		return response, err
@@ -131,5 +145,69 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 	response.Address = addresses[0]
 
+	// Verify the output signature (if enabled)
+	if c.options.verifyResolutions {
+		err = c.verifyResolution(ctx, alias, domain, response)
+	}
+
 	return response, err
 }
+
+// Verify will verify the signature of the output using the receiver's PubKey (from the PKI request)
+//
+// The signature is a compact Bitcoin message signature (base64) of the output, as the hex string of the script
+// Specs: http://bsvalias.org/04-02-sender-validation.html
+func (r *ResolutionPayload) Verify(pubKey string) error {
+	if len(r.Signature) == 0 {
+		return ErrResolveAddressMissingSignature
+	}
+
+	// Get the address from the PubKey
+	rawAddress, err := script.NewAddressFromPublicKeyString(pubKey, true)
+	if err != nil {
+		return fmt.Errorf("pubkey %s: %w", pubKey, err)
+	}
+
+	if _, err = script.NewFromHex(r.Output); err != nil {
+		return err
+	}
+
+	var sigBytes []byte
+	if sigBytes, err = DecodeSignature(r.Signature); err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), ErrResolveAddressInvalidSignature)
+	}
+
+	// Verify the signature of the output (the hex string)
+	if err = bsm.VerifyMessage(rawAddress.AddressString, sigBytes, []byte(r.Output)); err != nil {
+		return fmt.Errorf("%s: %w", err.Error(), ErrResolveAddressInvalidSignature)
+	}
+	return nil
+}
+
+// verifyResolution will get the receiver's PubKey (capabilities and PKI) and verify the output signature
+func (c *Client) verifyResolution(ctx context.Context, alias, domain string, response *ResolutionResponse) error {
+	if len(response.Signature) == 0 {
+		return ErrResolveAddressMissingSignature
+	}
+
+	// Discover the receiver's provider (the PKI url)
+	resolver, err := NewResolver(c)
+	if err != nil {
+		return err
+	}
+	var endpoint *PaymailEndpoint
+	if endpoint, err = resolver.Resolve(ctx, alias+"@"+domain); err != nil {
+		return err
+	}
+	pkiURL := endpoint.PKIURL()
+	if len(pkiURL) == 0 {
+		return fmt.Errorf("%s: %w", endpoint.Address, ErrResolveAddressMissingPKI)
+	}
+
+	// Get the receiver's PubKey
+	var pki *PKIResponse
+	if pki, err = c.GetPKICtx(ctx, pkiURL, alias, domain); err != nil {
+		return err
+	}
+	return response.Verify(pki.PubKey)
+}
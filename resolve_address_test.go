@@ -1,11 +1,16 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	primitives "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -384,6 +389,27 @@ func TestClient_ResolveAddress(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resolution.StatusCode)
 		assert.Equal(t, "0", resolution.Output)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockResolveAddress(http.StatusOK)
+
+		senderRequest := &SenderRequest{
+			Dt:           time.Now().UTC().Format(time.RFC3339),
+			SenderHandle: testAlias + "@" + testDomain,
+			SenderName:   testName,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		resolution, err := client.ResolveAddressCtx(
+			ctx, testServerURL+"address/{alias}@{domain.tld}", testAlias, testDomain, senderRequest,
+		)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, resolution)
+	})
 }
 
 // mockResolveAddress is used for mocking the response
@@ -397,6 +423,163 @@ func mockResolveAddress(statusCode int) {
 	)
 }
 
+// mockSignedResolveAddress is used for mocking the capabilities, PKI and the (signed) resolution response
+func mockSignedResolveAddress(pubKey, signature string) {
+	mockResolverCapabilities(testSRVTarget, http.StatusOK)
+	httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","handle": "`+testAlias+`@`+testDomain+`","pubkey": "`+pubKey+`"}`,
+		),
+	)
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"address/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"output": "`+testOutput+`","signature": "`+signature+`"}`,
+		),
+	)
+}
+
+// newTestOutputSignature will sign the test output (the hex string) with a new key (returns the PubKey and the signature)
+func newTestOutputSignature(t *testing.T) (string, string) {
+	key, err := primitives.NewPrivateKey()
+	require.NoError(t, err)
+	sigBytes, err := bsm.SignMessage(key, []byte(testOutput))
+	require.NoError(t, err)
+	return key.PubKey().ToDERHex(), EncodeSignature(sigBytes)
+}
+
+// TestClient_ResolveAddressVerification will test the output signature verification of ResolveAddress()
+func TestClient_ResolveAddressVerification(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	senderRequest := &SenderRequest{
+		Dt:           time.Now().UTC().Format(time.RFC3339),
+		SenderHandle: testAlias + "@" + testDomain,
+		SenderName:   testName,
+	}
+	resolutionURL := testServerURL + "address/{alias}@{domain.tld}"
+
+	t.Run("valid signature", func(t *testing.T) {
+		_, client := newTestResolver(t, WithResolutionVerification())
+
+		pubKey, signature := newTestOutputSignature(t)
+		mockSignedResolveAddress(pubKey, signature)
+
+		resolution, err := client.ResolveAddress(resolutionURL, testAlias, testDomain, senderRequest)
+		require.NoError(t, err)
+		require.NotNil(t, resolution)
+		assert.Equal(t, testAddress, resolution.Address)
+		assert.Equal(t, signature, resolution.Signature)
+	})
+
+	t.Run("signature from another key", func(t *testing.T) {
+		_, client := newTestResolver(t, WithResolutionVerification())
+
+		_, signature := newTestOutputSignature(t)
+		mockSignedResolveAddress(testPubKey, signature)
+
+		_, err := client.ResolveAddress(resolutionURL, testAlias, testDomain, senderRequest)
+		require.ErrorIs(t, err, ErrResolveAddressInvalidSignature)
+	})
+
+	t.Run("invalid signature encoding", func(t *testing.T) {
+		_, client := newTestResolver(t, WithResolutionVerification())
+
+		mockSignedResolveAddress(testPubKey, "not-base64!")
+
+		_, err := client.ResolveAddress(resolutionURL, testAlias, testDomain, senderRequest)
+		require.ErrorIs(t, err, ErrResolveAddressInvalidSignature)
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		_, client := newTestResolver(t, WithResolutionVerification())
+
+		mockResolveAddress(http.StatusOK)
+
+		_, err := client.ResolveAddress(resolutionURL, testAlias, testDomain, senderRequest)
+		require.ErrorIs(t, err, ErrResolveAddressMissingSignature)
+	})
+
+	t.Run("missing pki capability", func(t *testing.T) {
+		_, client := newTestResolver(t, WithResolutionVerification())
+
+		pubKey, signature := newTestOutputSignature(t)
+		mockSignedResolveAddress(pubKey, signature)
+		httpmock.RegisterResponder(http.MethodGet, "https://"+testSRVTarget+":443/.well-known/"+DefaultServiceName,
+			httpmock.NewStringResponder(
+				http.StatusOK,
+				`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {"paymentDestination": "`+resolutionURL+`"}}`,
+			),
+		)
+
+		_, err := client.ResolveAddress(resolutionURL, testAlias, testDomain, senderRequest)
+		require.ErrorIs(t, err, ErrResolveAddressMissingPKI)
+	})
+
+	t.Run("verification disabled", func(t *testing.T) {
+		_, client := newTestResolver(t)
+
+		_, signature := newTestOutputSignature(t)
+		mockSignedResolveAddress(testPubKey, signature)
+
+		resolution, err := client.ResolveAddress(resolutionURL, testAlias, testDomain, senderRequest)
+		require.NoError(t, err)
+		assert.Equal(t, signature, resolution.Signature)
+	})
+}
+
+// TestResolutionPayload_Verify will test the method Verify()
+func TestResolutionPayload_Verify(t *testing.T) {
+	t.Parallel()
+
+	pubKey, signature := newTestOutputSignature(t)
+
+	tests := []struct {
+		name        string
+		payload     *ResolutionPayload
+		pubKey      string
+		expectedErr error
+	}{
+		{"valid", &ResolutionPayload{Output: testOutput, Signature: signature}, pubKey, nil},
+		{"wrong pubkey", &ResolutionPayload{Output: testOutput, Signature: signature}, testPubKey, ErrResolveAddressInvalidSignature},
+		{"other output", &ResolutionPayload{Output: "76a914" + strings.Repeat("00", 20) + "88ac", Signature: signature}, pubKey, ErrResolveAddressInvalidSignature},
+		{"missing signature", &ResolutionPayload{Output: testOutput}, pubKey, ErrResolveAddressMissingSignature},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.payload.Verify(test.pubKey)
+			if test.expectedErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, test.expectedErr)
+			}
+		})
+	}
+
+	t.Run("invalid pubkey", func(t *testing.T) {
+		payload := &ResolutionPayload{Output: testOutput, Signature: signature}
+		require.Error(t, payload.Verify("invalid"))
+	})
+
+	t.Run("signature of the script bytes", func(t *testing.T) {
+		key, err := primitives.NewPrivateKey()
+		require.NoError(t, err)
+		outputScript, err := script.NewFromHex(testOutput)
+		require.NoError(t, err)
+		sigBytes, err := bsm.SignMessage(key, outputScript.Bytes())
+		require.NoError(t, err)
+
+		payload := &ResolutionPayload{Output: testOutput, Signature: EncodeSignature(sigBytes)}
+		require.ErrorIs(t, payload.Verify(key.PubKey().ToDERHex()), ErrResolveAddressInvalidSignature)
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		payload := &ResolutionPayload{Output: "zz", Signature: signature}
+		require.Error(t, payload.Verify(pubKey))
+	})
+}
+
 // ExampleClient_ResolveAddress example using ResolveAddress()
 //
 // See more examples in /examples/
@@ -0,0 +1,213 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+var (
+	// ErrResolverMissingClient is returned when the resolver is created without a client
+	ErrResolverMissingClient = errors.New("paymail client is required for the resolver")
+	// ErrResolverMissingCapabilities is returned when the provider did not return any capabilities
+	ErrResolverMissingCapabilities = errors.New("paymail provider returned no capabilities")
+)
+
+// Resolver will discover the paymail provider for a given paymail address
+// by chaining host discovery (SRV), SRV validation and capability discovery
+//
+// Specs: http://bsvalias.org/02-01-host-discovery.html
+type Resolver struct {
+	client ClientInterface
+}
+
+// PaymailEndpoint is the discovered paymail provider for a given alias@domain.tld
+type PaymailEndpoint struct {
+	Address      string                // The sanitized paymail address (alias@domain.tld)
+	Alias        string                // The alias (alias@)
+	Capabilities *CapabilitiesResponse // The capabilities from the provider
+	Domain       string                // The domain (@domain.tld)
+	Host         string                // The host serving the paymail (SRV target or domain)
+	Port         int                   // The port serving the paymail (SRV port or 443)
+	SRV          *net.SRV              // The SRV record used for discovery (default record if none was found)
+	SRVRecords   []*net.SRV            // All SRV records in the order they were tried
+}
+
+// NewResolver will create a new paymail resolver using the given client
+func NewResolver(client ClientInterface) (*Resolver, error) {
+	if client == nil {
+		return nil, ErrResolverMissingClient
+	}
+	return &Resolver{client: client}, nil
+}
+
+// Resolve will discover the paymail endpoint for a given paymail address
+//
+// All SRV targets are tried in RFC 2782 order, moving to the next target if the SRV record
+// fails validation (see WithSRVPolicy) or if the HTTP/TLS request or the provider (5xx) fails.
+// If no SRV record exists, the domain and port 443 are used (per the spec)
+func (r *Resolver) Resolve(ctx context.Context, paymailAddress string) (*PaymailEndpoint, error) {
+	// Sanitize and validate the paymail address
+	alias, domain, address := SanitizePaymail(paymailAddress)
+	if err := ValidatePaymail(address); err != nil {
+		return nil, err
+	}
+
+	// Host discovery (falls back to the domain on port 443)
+	records, err := r.client.GetSRVRecordsCtx(ctx, DefaultServiceName, DefaultProtocol, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try each target until one answers
+	var lastErr error
+	for _, srv := range records {
+		var capabilities *CapabilitiesResponse
+		if capabilities, err = r.discoverCapabilities(ctx, srv); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			} else if !canFailover(capabilities, err) {
+				return nil, err
+			}
+			lastErr = fmt.Errorf("target %s:%d: %w", srv.Target, srv.Port, err)
+			continue
+		}
+
+		return &PaymailEndpoint{
+			Address:      address,
+			Alias:        alias,
+			Capabilities: capabilities,
+			Domain:       domain,
+			Host:         srv.Target,
+			Port:         int(srv.Port),
+			SRV:          srv,
+			SRVRecords:   records,
+		}, nil
+	}
+
+	return nil, lastErr
+}
+
+// discoverCapabilities will validate the SRV record and get the capabilities from the target
+func (r *Resolver) discoverCapabilities(ctx context.Context, srv *net.SRV) (*CapabilitiesResponse, error) {
+	// Make sure the target is usable
+	if err := r.client.ValidateSRVRecordWithPolicy(ctx, srv, nil); err != nil {
+		return nil, &srvValidationError{err: err}
+	}
+
+	// Capability discovery on the discovered host
+	capabilities, err := r.client.GetCapabilitiesCtx(ctx, srv.Target, int(srv.Port))
+	if err != nil {
+		return capabilities, err
+	}
+	if len(capabilities.Capabilities) == 0 {
+		return capabilities, fmt.Errorf("%s: %w", srv.Target, ErrResolverMissingCapabilities)
+	}
+	return capabilities, nil
+}
+
+// srvValidationError is returned when an SRV record failed validation (the next target can be tried)
+type srvValidationError struct {
+	err error
+}
+
+// Error will return the error message
+func (e *srvValidationError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap will return the validation error
+func (e *srvValidationError) Unwrap() error {
+	return e.err
+}
+
+// canFailover will return true if the next SRV target should be tried after the error
+//
+// Invalid SRV records, transport (HTTP/TLS) failures and server errors (5xx) are retried on
+// the next target; any other answer from the provider is final
+func canFailover(capabilities *CapabilitiesResponse, err error) bool {
+	var validationErr *srvValidationError
+	if errors.As(err, &validationErr) {
+		return true
+	}
+	return capabilities == nil || capabilities.StatusCode == 0 || capabilities.StatusCode >= http.StatusInternalServerError
+}
+
+// capabilityURL will return the capability value if it is a string (url)
+//
+// Unlike GetString(), this does not panic on providers returning unexpected types
+func (e *PaymailEndpoint) capabilityURL(brfcID, alternateID string) string {
+	if ok, val := e.Capabilities.getValue(brfcID, alternateID); ok {
+		if url, isString := val.(string); isString {
+			return url
+		}
+	}
+	return ""
+}
+
+// Has will return true if the provider supports the given capability
+func (e *PaymailEndpoint) Has(brfcID, alternateID string) bool {
+	return e.Capabilities.Has(brfcID, alternateID)
+}
+
+// PKIURL will return the PKI url (if found)
+func (e *PaymailEndpoint) PKIURL() string {
+	return e.capabilityURL(BRFCPki, BRFCPkiAlternate)
+}
+
+// PaymentDestinationURL will return the basic address resolution url (if found)
+func (e *PaymailEndpoint) PaymentDestinationURL() string {
+	return e.capabilityURL(BRFCPaymentDestination, BRFCBasicAddressResolution)
+}
+
+// P2PPaymentDestinationURL will return the P2P payment destination url (if found)
+func (e *PaymailEndpoint) P2PPaymentDestinationURL() string {
+	return e.capabilityURL(BRFCP2PPaymentDestination, "")
+}
+
+// P2PTransactionsURL will return the P2P receive transaction url (if found)
+func (e *PaymailEndpoint) P2PTransactionsURL() string {
+	return e.capabilityURL(BRFCP2PTransactions, "")
+}
+
+// BeefTransactionURL will return the BEEF receive transaction url (if found)
+func (e *PaymailEndpoint) BeefTransactionURL() string {
+	return e.capabilityURL(BRFCBeefTransaction, "")
+}
+
+// VerifyPubKeyURL will return the verify public key owner url (if found)
+func (e *PaymailEndpoint) VerifyPubKeyURL() string {
+	return e.capabilityURL(BRFCVerifyPublicKeyOwner, "")
+}
+
+// PublicProfileURL will return the public profile url (if found)
+func (e *PaymailEndpoint) PublicProfileURL() string {
+	return e.capabilityURL(BRFCPublicProfile, "")
+}
+
+// PikeInviteURL will return the PIKE invite url (if found)
+func (e *PaymailEndpoint) PikeInviteURL() string {
+	if e.Capabilities.Pike == nil || e.Capabilities.Pike.Invite == nil {
+		return ""
+	}
+	return *e.Capabilities.Pike.Invite
+}
+
+// PikeOutputsURL will return the PIKE outputs url (if found)
+func (e *PaymailEndpoint) PikeOutputsURL() string {
+	if e.Capabilities.Pike == nil || e.Capabilities.Pike.Outputs == nil {
+		return ""
+	}
+	return *e.Capabilities.Pike.Outputs
+}
+
+// SenderValidation will return true if the provider requires sender validation
+func (e *PaymailEndpoint) SenderValidation() bool {
+	if ok, val := e.Capabilities.getValue(BRFCSenderValidation, ""); ok {
+		enabled, isBool := val.(bool)
+		return isBool && enabled
+	}
+	return false
+}
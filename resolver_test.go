@@ -0,0 +1,319 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/tester"
+)
+
+const (
+	testBackupTarget   = "backup." + testMultiDomain
+	testFallbackDomain = "fallback.com"
+	testMultiDomain    = "multi.com"
+	testPrimaryTarget  = "primary." + testMultiDomain
+	testSRVTarget      = "www." + testDomain
+)
+
+// newTestResolver will return a resolver (and its client) with known DNS records
+func newTestResolver(t *testing.T, opts ...ClientOps) (*Resolver, ClientInterface) {
+	client := newTestClient(t, opts...)
+
+	_ = client.WithCustomResolver(tester.NewCustomResolver(
+		client.GetResolver(),
+		map[string][]string{
+			testSRVTarget:      {"44.225.125.175"},
+			testFallbackDomain: {"44.225.125.176"},
+			testPrimaryTarget:  {"44.225.125.177"},
+			testBackupTarget:   {"44.225.125.178"},
+			"norecords.com":    {},
+		},
+		map[string][]*net.SRV{
+			DefaultServiceName + DefaultProtocol + testDomain:         {{Target: testSRVTarget + ".", Port: 443, Priority: 10, Weight: 10}},
+			DefaultServiceName + DefaultProtocol + testFallbackDomain: {},
+			DefaultServiceName + DefaultProtocol + "norecords.com":    {},
+			DefaultServiceName + DefaultProtocol + "badtarget.com":    {{Target: "bad target", Port: 443, Priority: 10, Weight: 10}},
+			DefaultServiceName + DefaultProtocol + testMultiDomain: {
+				{Target: testBackupTarget + ".", Port: 443, Priority: 20, Weight: 10},
+				{Target: testPrimaryTarget + ".", Port: 8443, Priority: 10, Weight: 10},
+			},
+		},
+		nil,
+	))
+
+	resolver, err := NewResolver(client)
+	if t != nil {
+		require.NoError(t, err)
+		require.NotNil(t, resolver)
+	}
+	return resolver, client
+}
+
+// mockResolverCapabilities is used for mocking the capabilities response on a given host
+func mockResolverCapabilities(host string, statusCode int) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, "https://"+host+":443/.well-known/"+DefaultServiceName,
+		httpmock.NewStringResponder(
+			statusCode,
+			`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {
+"6745385c3fc0": true,
+"pki": "`+testServerURL+`id/{alias}@{domain.tld}",
+"paymentDestination": "`+testServerURL+`address/{alias}@{domain.tld}",
+"2a40af698840": "`+testServerURL+`p2p-payment-destination/{alias}@{domain.tld}",
+"5f1323cddf31": "`+testServerURL+`receive-transaction/{alias}@{domain.tld}",
+"5c55a7fdb7bb": "`+testServerURL+`beef/{alias}@{domain.tld}",
+"a9f510c16bde": "`+testServerURL+`verifypubkey/{alias}@{domain.tld}/{pubkey}",
+"f12f968c92d6": "`+testServerURL+`public-profile/{alias}@{domain.tld}",
+"8c4ed5ef8ace": {
+	"invite": "`+testServerURL+`contact/invite/{alias}@{domain.tld}",
+	"outputs": "`+testServerURL+`pike/outputs/{alias}@{domain.tld}"
+}}}`,
+		),
+	)
+}
+
+// TestNewResolver will test the method NewResolver()
+func TestNewResolver(t *testing.T) {
+	t.Run("missing client", func(t *testing.T) {
+		resolver, err := NewResolver(nil)
+		require.ErrorIs(t, err, ErrResolverMissingClient)
+		require.Nil(t, resolver)
+	})
+
+	t.Run("valid client", func(t *testing.T) {
+		resolver, err := NewResolver(newTestClient(t))
+		require.NoError(t, err)
+		require.NotNil(t, resolver)
+	})
+}
+
+// TestResolver_Resolve will test the method Resolve()
+func TestResolver_Resolve(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("successful SRV discovery", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+
+		endpoint, err := resolver.Resolve(context.Background(), " MrZ@"+testDomain)
+		require.NoError(t, err)
+		require.NotNil(t, endpoint)
+		assert.Equal(t, testAlias, endpoint.Alias)
+		assert.Equal(t, testDomain, endpoint.Domain)
+		assert.Equal(t, testAlias+"@"+testDomain, endpoint.Address)
+		assert.Equal(t, testSRVTarget, endpoint.Host)
+		assert.Equal(t, DefaultPort, endpoint.Port)
+		require.NotNil(t, endpoint.SRV)
+		assert.Equal(t, testSRVTarget, endpoint.SRV.Target)
+
+		assert.Equal(t, testServerURL+"id/{alias}@{domain.tld}", endpoint.PKIURL())
+		assert.Equal(t, testServerURL+"address/{alias}@{domain.tld}", endpoint.PaymentDestinationURL())
+		assert.Equal(t, testServerURL+"p2p-payment-destination/{alias}@{domain.tld}", endpoint.P2PPaymentDestinationURL())
+		assert.Equal(t, testServerURL+"receive-transaction/{alias}@{domain.tld}", endpoint.P2PTransactionsURL())
+		assert.Equal(t, testServerURL+"beef/{alias}@{domain.tld}", endpoint.BeefTransactionURL())
+		assert.Equal(t, testServerURL+"verifypubkey/{alias}@{domain.tld}/{pubkey}", endpoint.VerifyPubKeyURL())
+		assert.Equal(t, testServerURL+"public-profile/{alias}@{domain.tld}", endpoint.PublicProfileURL())
+		assert.Equal(t, testServerURL+"contact/invite/{alias}@{domain.tld}", endpoint.PikeInviteURL())
+		assert.Equal(t, testServerURL+"pike/outputs/{alias}@{domain.tld}", endpoint.PikeOutputsURL())
+		assert.True(t, endpoint.SenderValidation())
+		assert.True(t, endpoint.Has(BRFCP2PTransactions, ""))
+	})
+
+	t.Run("fallback to domain without SRV record", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		mockResolverCapabilities(testFallbackDomain, http.StatusOK)
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testFallbackDomain)
+		require.NoError(t, err)
+		require.NotNil(t, endpoint)
+		assert.Equal(t, testFallbackDomain, endpoint.Host)
+		assert.Equal(t, DefaultPort, endpoint.Port)
+		assert.Equal(t, uint16(DefaultPriority), endpoint.SRV.Priority)
+		assert.Equal(t, uint16(DefaultWeight), endpoint.SRV.Weight)
+	})
+
+	t.Run("invalid paymail address", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		endpoint, err := resolver.Resolve(context.Background(), "not-a-paymail")
+		require.ErrorIs(t, err, ErrPaymailFormatInvalid)
+		require.Nil(t, endpoint)
+	})
+
+	t.Run("target does not resolve", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@norecords.com")
+		require.ErrorIs(t, err, ErrSRVTargetNoHost)
+		require.Nil(t, endpoint)
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@badtarget.com")
+		require.ErrorIs(t, err, ErrSRVTargetInvalid)
+		require.Nil(t, endpoint)
+	})
+
+	t.Run("bad capabilities response", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusBadRequest)
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testDomain)
+		require.ErrorIs(t, err, ErrCapabilitiesBadResponse)
+		require.Nil(t, endpoint)
+	})
+
+	t.Run("missing capabilities", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, "https://"+testSRVTarget+":443/.well-known/"+DefaultServiceName,
+			httpmock.NewStringResponder(
+				http.StatusOK,
+				`{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {}}`,
+			),
+		)
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testDomain)
+		require.ErrorIs(t, err, ErrResolverMissingCapabilities)
+		require.Nil(t, endpoint)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		mockResolverCapabilities(testSRVTarget, http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		endpoint, err := resolver.Resolve(ctx, testAlias+"@"+testDomain)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, endpoint)
+	})
+}
+
+// TestResolver_ResolveFailover will test the failover between SRV targets in Resolve()
+func TestResolver_ResolveFailover(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	primaryURL := "https://" + testPrimaryTarget + ":8443/.well-known/" + DefaultServiceName
+	backupURL := "https://" + testBackupTarget + ":443/.well-known/" + DefaultServiceName
+	capabilities := `{"` + DefaultServiceName + `": "` + DefaultBsvAliasVersion + `","capabilities": {"pki": "` + testServerURL + `id/{alias}@{domain.tld}"}}`
+
+	t.Run("primary target is used", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, primaryURL, httpmock.NewStringResponder(http.StatusOK, capabilities))
+		httpmock.RegisterResponder(http.MethodGet, backupURL, httpmock.NewStringResponder(http.StatusOK, capabilities))
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testMultiDomain)
+		require.NoError(t, err)
+		assert.Equal(t, testPrimaryTarget, endpoint.Host)
+		assert.Equal(t, 8443, endpoint.Port)
+		require.Len(t, endpoint.SRVRecords, 2)
+		assert.Equal(t, testBackupTarget, endpoint.SRVRecords[1].Target)
+	})
+
+	t.Run("server error fails over", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, primaryURL, httpmock.NewStringResponder(http.StatusServiceUnavailable, `{"message": "down"}`))
+		httpmock.RegisterResponder(http.MethodGet, backupURL, httpmock.NewStringResponder(http.StatusOK, capabilities))
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testMultiDomain)
+		require.NoError(t, err)
+		assert.Equal(t, testBackupTarget, endpoint.Host)
+		assert.Equal(t, DefaultPort, endpoint.Port)
+	})
+
+	t.Run("transport error fails over", func(t *testing.T) {
+		resolver, _ := newTestResolver(t, WithRetryCount(0))
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, primaryURL, httpmock.NewErrorResponder(ErrTestRequestFailed))
+		httpmock.RegisterResponder(http.MethodGet, backupURL, httpmock.NewStringResponder(http.StatusOK, capabilities))
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testMultiDomain)
+		require.NoError(t, err)
+		assert.Equal(t, testBackupTarget, endpoint.Host)
+	})
+
+	t.Run("srv policy fails over", func(t *testing.T) {
+		resolver, _ := newTestResolver(t, WithSRVPolicy(SRVPolicy{AllowedPorts: []uint16{DefaultPort}}))
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, backupURL, httpmock.NewStringResponder(http.StatusOK, capabilities))
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testMultiDomain)
+		require.NoError(t, err)
+		assert.Equal(t, testBackupTarget, endpoint.Host)
+	})
+
+	t.Run("client error does not fail over", func(t *testing.T) {
+		resolver, _ := newTestResolver(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, primaryURL, httpmock.NewStringResponder(http.StatusBadRequest, `{"message": "bad request"}`))
+		httpmock.RegisterResponder(http.MethodGet, backupURL, httpmock.NewStringResponder(http.StatusOK, capabilities))
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testMultiDomain)
+		require.ErrorIs(t, err, ErrCapabilitiesBadResponse)
+		require.Nil(t, endpoint)
+	})
+
+	t.Run("all targets fail", func(t *testing.T) {
+		resolver, _ := newTestResolver(t, WithRetryCount(0))
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, primaryURL, httpmock.NewErrorResponder(ErrTestRequestFailed))
+		httpmock.RegisterResponder(http.MethodGet, backupURL, httpmock.NewStringResponder(http.StatusBadGateway, `{"message": "bad gateway"}`))
+
+		endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testMultiDomain)
+		require.ErrorIs(t, err, ErrCapabilitiesBadResponse)
+		assert.Contains(t, err.Error(), testBackupTarget)
+		require.Nil(t, endpoint)
+	})
+}
+
+// ExampleResolver_Resolve example using Resolve()
+//
+// See more examples in /examples/
+func ExampleResolver_Resolve() {
+	// Load the resolver
+	resolver, _ := newTestResolver(nil)
+
+	mockResolverCapabilities(testSRVTarget, http.StatusOK)
+
+	// Discover the paymail endpoint
+	endpoint, err := resolver.Resolve(context.Background(), testAlias+"@"+testDomain)
+	if err != nil {
+		fmt.Printf("error resolving paymail: %s", err.Error())
+		return
+	}
+	fmt.Printf("found host: %s:%d pki: %s", endpoint.Host, endpoint.Port, endpoint.PKIURL())
+	// Output:found host: www.test.com:443 pki: https://test.com/api/v1/bsvalias/id/{alias}@{domain.tld}
+}
+
+// BenchmarkResolver_Resolve benchmarks the method Resolve()
+func BenchmarkResolver_Resolve(b *testing.B) {
+	resolver, _ := newTestResolver(nil)
+	mockResolverCapabilities(testSRVTarget, http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_, _ = resolver.Resolve(context.Background(), testAlias+"@"+testDomain)
+	}
+}
@@ -0,0 +1,53 @@
+package paymail
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ResponseError is returned when a paymail provider responds with an error status code
+//
+// The error wraps the sentinel error of the request (ErrPKIBadResponse, ErrP2PAddressNotFound, etc.),
+// so errors.Is() can be used as before, and errors.As() gives access to the server error code
+type ResponseError struct {
+	BRFC       string // The BRFC id of the capability (empty for capability discovery)
+	Body       string // The raw response body
+	Code       string // The error code returned by the server (see errors/definitions.go)
+	Endpoint   string // The url of the request
+	Err        error  // The sentinel error for the request
+	Message    string // The error message returned by the server
+	StatusCode int    // The HTTP status code
+}
+
+// newResponseError will create a ResponseError from the provider response
+//
+// The body is decoded as a ServerError, if that fails the raw body is kept for the error message
+func newResponseError(response *StandardResponse, endpoint, brfc string, sentinel error) *ResponseError {
+	responseError := &ResponseError{
+		BRFC:       brfc,
+		Body:       string(response.Body),
+		Endpoint:   endpoint,
+		Err:        sentinel,
+		StatusCode: response.StatusCode,
+	}
+
+	serverError := &ServerError{}
+	if err := json.Unmarshal(response.Body, serverError); err == nil {
+		responseError.Code = serverError.Code
+		responseError.Message = serverError.Message
+	}
+	return responseError
+}
+
+// Error will return the error message
+func (e *ResponseError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("code %d, body: %s: %s", e.StatusCode, e.Body, e.Err)
+	}
+	return fmt.Sprintf("code %d, message: %s: %s", e.StatusCode, e.Message, e.Err)
+}
+
+// Unwrap will return the sentinel error
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,167 @@
+package paymail
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResponseError will test the methods of the ResponseError
+func TestResponseError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("server error", func(t *testing.T) {
+		err := newResponseError(&StandardResponse{
+			Body:       []byte(`{"code": "error-paymail-not-found", "message": "paymail not found"}`),
+			StatusCode: http.StatusNotFound,
+		}, testServerURL+"id/"+testAlias+"@"+testDomain, BRFCPki, ErrPKIBadResponse)
+
+		require.NotNil(t, err)
+		assert.Equal(t, BRFCPki, err.BRFC)
+		assert.Equal(t, "error-paymail-not-found", err.Code)
+		assert.Equal(t, testServerURL+"id/"+testAlias+"@"+testDomain, err.Endpoint)
+		assert.Equal(t, "paymail not found", err.Message)
+		assert.Equal(t, http.StatusNotFound, err.StatusCode)
+		assert.Equal(t, "code 404, message: paymail not found: bad response from paymail provider", err.Error())
+		require.ErrorIs(t, err, ErrPKIBadResponse)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		err := newResponseError(&StandardResponse{
+			Body:       []byte(`bad gateway`),
+			StatusCode: http.StatusBadGateway,
+		}, testServerURL, "", ErrCapabilitiesBadResponse)
+
+		require.NotNil(t, err)
+		assert.Empty(t, err.Code)
+		assert.Empty(t, err.Message)
+		assert.Equal(t, "bad gateway", err.Body)
+		assert.Equal(t, "code 502, body: bad gateway: bad response from paymail provider", err.Error())
+	})
+
+	t.Run("errors.As through wrapping", func(t *testing.T) {
+		wrapped := fmt.Errorf("target: %w", newResponseError(&StandardResponse{
+			Body:       []byte(`{"code": "error-not-found"}`),
+			StatusCode: http.StatusNotFound,
+		}, testServerURL, BRFCP2PPaymentDestination, ErrP2PAddressNotFound))
+
+		var responseErr *ResponseError
+		require.ErrorAs(t, wrapped, &responseErr)
+		assert.Equal(t, "error-not-found", responseErr.Code)
+		require.ErrorIs(t, wrapped, ErrP2PAddressNotFound)
+	})
+}
+
+// TestClient_ResponseErrors will test the ResponseError returned by the client methods
+func TestClient_ResponseErrors(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	serverError := `{"code": "error-invalid-pubkey", "message": "invalid pubkey"}`
+
+	t.Run("pki", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusBadRequest, serverError))
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, ErrPKIBadResponse)
+
+		var responseErr *ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		assert.Equal(t, "error-invalid-pubkey", responseErr.Code)
+		assert.Equal(t, BRFCPki, responseErr.BRFC)
+		assert.Equal(t, testServerURL+"id/"+testAlias+"@"+testDomain, responseErr.Endpoint)
+		assert.Equal(t, http.StatusBadRequest, responseErr.StatusCode)
+	})
+
+	t.Run("resolve address not found", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"address/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code": "error-paymail-not-found", "message": "not found"}`))
+
+		_, err := client.ResolveAddress(testServerURL+"address/{alias}@{domain.tld}", testAlias, testDomain, &SenderRequest{
+			Dt:           "2020-04-09T16:08:06.419Z",
+			SenderHandle: testAlias + "@" + testDomain,
+		})
+		require.ErrorIs(t, err, ErrResolveAddressNotFound)
+
+		var responseErr *ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		assert.Equal(t, "error-paymail-not-found", responseErr.Code)
+		assert.Equal(t, BRFCPaymentDestination, responseErr.BRFC)
+	})
+
+	t.Run("send p2p transaction with beef", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"beef/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusBadRequest, serverError))
+
+		_, err := client.SendP2PTransaction(testServerURL+"beef/{alias}@{domain.tld}", testAlias, testDomain,
+			&P2PTransaction{Beef: "0100beef", Reference: testPayerReference})
+		require.ErrorIs(t, err, ErrSendP2PBadResponse)
+
+		var responseErr *ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		assert.Equal(t, BRFCBeefTransaction, responseErr.BRFC)
+		assert.Equal(t, "invalid pubkey", responseErr.Message)
+	})
+
+	t.Run("pike outputs", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+"pike/outputs/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusInternalServerError, `internal error`))
+
+		_, err := client.GetOutputsTemplate(testServerURL+"pike/outputs/{alias}@{domain.tld}", testAlias, testDomain,
+			&PikePaymentOutputsPayload{Amount: 1000})
+		require.ErrorIs(t, err, ErrPikeBadOutputsResponse)
+
+		var responseErr *ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		assert.Equal(t, BRFCPike, responseErr.BRFC)
+		assert.Equal(t, "internal error", responseErr.Body)
+	})
+}
+
+// ExampleResponseError example using ResponseError with errors.As()
+//
+// See more examples in /examples/
+func ExampleResponseError() {
+	// Load the client
+	client := newTestClient(nil)
+
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(http.StatusNotFound, `{"code": "error-paymail-not-found", "message": "paymail not found"}`))
+
+	// Get the PKI and branch on the server error code
+	_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	var responseErr *ResponseError
+	if errors.As(err, &responseErr) {
+		fmt.Printf("status: %d code: %s", responseErr.StatusCode, responseErr.Code)
+	}
+	// Output:status: 404 code: error-paymail-not-found
+}
+
+// BenchmarkResponseError benchmarks the method newResponseError()
+func BenchmarkResponseError(b *testing.B) {
+	response := &StandardResponse{
+		Body:       []byte(`{"code": "error-paymail-not-found", "message": "paymail not found"}`),
+		StatusCode: http.StatusNotFound,
+	}
+	for i := 0; i < b.N; i++ {
+		_ = newResponseError(response, testServerURL, BRFCPki, ErrPKIBadResponse).Error()
+	}
+}
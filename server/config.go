@@ -6,16 +6,22 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/beef"
 	"github.com/bsv-blockchain/go-paymail/errors"
+	"github.com/bsv-blockchain/go-paymail/spv"
 )
 
 // Configuration paymail server configuration object
 type Configuration struct {
 	APIVersion                       string          `json:"api_version"`
 	BasicRoutes                      *basicRoutes    `json:"basic_routes"`
+	BeefLimits                       beef.Limits     `json:"beef_limits"`
 	BSVAliasVersion                  string          `json:"bsv_alias_version"`
+	FeePolicy                        spv.FeePolicy   `json:"fee_policy"`
 	PaymailDomains                   []*Domain       `json:"paymail_domains"`
 	PaymailDomainsValidationDisabled bool            `json:"paymail_domains_validation_disabled"`
 	Port                             int             `json:"port"`
@@ -36,7 +42,14 @@ type Configuration struct {
 	pikePaymentActions   PikePaymentServiceProvider
 	nestedCapabilities   NestedCapabilitiesMap
 	callableCapabilities CallableCapabilitiesMap
+	merkleRootCache      []spv.CachedVerifierOps // Options of the merkle root cache (nil if disabled)
+	merkleRootVerifier   spv.MerkleRootVerifier
+	spvVerifierOptions   []spv.VerifierOps
 	staticCapabilities   StaticCapabilitiesMap
+	paymailClient        paymail.ClientInterface
+	meterProvider        metric.MeterProvider
+	telemetry            *telemetry
+	tracerProvider       trace.TracerProvider
 }
 
 // Domain is the Paymail Domain information
@@ -44,6 +57,22 @@ type Domain struct {
 	Name string `json:"name"`
 }
 
+// newPaymailClient will create the default paymail client for outgoing requests
+func newPaymailClient() (paymail.ClientInterface, error) {
+	return paymail.NewClient(
+		paymail.WithHTTPTimeout(DefaultPaymailClientTimeout),
+		paymail.WithCache(nil),
+	)
+}
+
+// getPaymailClient will return the shared paymail client (or create one if not set)
+func (c *Configuration) getPaymailClient() (paymail.ClientInterface, error) {
+	if c.paymailClient != nil {
+		return c.paymailClient, nil
+	}
+	return newPaymailClient()
+}
+
 // NewConfig will make a new server configuration
 // The serviceProvider must have registered necessary services before calling them (e.g., PikeServiceProvider has to be registered if Pike capabilities are supported)
 func NewConfig(serviceProvider *PaymailServiceLocator, opts ...ConfigOps) (*Configuration, error) {
@@ -88,6 +117,25 @@ func NewConfig(serviceProvider *PaymailServiceLocator, opts ...ConfigOps) (*Conf
 	// Set the service provider
 	config.actions = serviceProvider.GetPaymailService()
 
+	// Set the merkle root verifier (the service provider, cached if enabled)
+	config.merkleRootVerifier = config.actions
+	if config.merkleRootCache != nil {
+		config.merkleRootVerifier = spv.NewCachedVerifier(config.actions, config.merkleRootCache...)
+	}
+
+	// Set the shared paymail client (reused for all outgoing requests)
+	var err error
+	if config.paymailClient == nil {
+		if config.paymailClient, err = newPaymailClient(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the telemetry (spans and metrics for every route)
+	if config.telemetry, err = newTelemetry(config.tracerProvider, config.meterProvider); err != nil {
+		return nil, err
+	}
+
 	config.Logger.Debug().Msg("New config loaded")
 	return config, nil
 }
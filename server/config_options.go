@@ -4,9 +4,13 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/beef"
 	"github.com/bsv-blockchain/go-paymail/logging"
+	"github.com/bsv-blockchain/go-paymail/spv"
 )
 
 // ConfigOps allow functional options to be supplied
@@ -20,7 +24,9 @@ func defaultConfigOptions() *Configuration {
 	return &Configuration{
 		APIVersion:                       DefaultAPIVersion,
 		BasicRoutes:                      &basicRoutes{},
+		BeefLimits:                       beef.DefaultLimits(),
 		BSVAliasVersion:                  paymail.DefaultBsvAliasVersion,
+		FeePolicy:                        spv.DefaultFeePolicy(),
 		PaymailDomainsValidationDisabled: false,
 		Port:                             DefaultServerPort,
 		Prefix:                           DefaultPrefix,
@@ -126,6 +132,43 @@ func WithSenderValidation() ConfigOps {
 	}
 }
 
+// WithBeefLimits will set the resource limits for decoding incoming BEEF transactions
+//
+// Zero values use the defaults (see beef.DefaultLimits), the request body is limited to twice the BEEF size (hex)
+func WithBeefLimits(limits beef.Limits) ConfigOps {
+	return func(c *Configuration) {
+		c.BeefLimits = limits
+	}
+}
+
+// WithFeePolicy will set the minimum fee rate of the unmined transactions of incoming BEEF transactions
+//
+// Default is spv.DefaultFeePolicy() (1 sat/KB): transactions paying less are rejected with
+// the error-spv-fee-too-low code. Zero rates (spv.FeePolicy{}) accept any fee
+func WithFeePolicy(policy spv.FeePolicy) ConfigOps {
+	return func(c *Configuration) {
+		c.FeePolicy = policy
+	}
+}
+
+// WithMerkleRootCache will cache, coalesce and batch the merkle root verifications of the service provider
+//
+// Incoming BEEF transactions mostly refer to the same recent blocks (see spv.NewCachedVerifier for the options)
+func WithMerkleRootCache(opts ...spv.CachedVerifierOps) ConfigOps {
+	return func(c *Configuration) {
+		c.merkleRootCache = append([]spv.CachedVerifierOps{}, opts...)
+	}
+}
+
+// WithSPVVerifierOptions will set the options of the SPV verifier for incoming BEEF transactions
+//
+// Policy rules can be added (spv.WithRule) and built-in rules disabled (spv.WithoutRules)
+func WithSPVVerifierOptions(opts ...spv.VerifierOps) ConfigOps {
+	return func(c *Configuration) {
+		c.spvVerifierOptions = append(c.spvVerifierOptions, opts...)
+	}
+}
+
 // WithDomain will add the domain if not found
 func WithDomain(domain string) ConfigOps {
 	return func(c *Configuration) {
@@ -158,3 +201,30 @@ func WithLogger(logger *zerolog.Logger) ConfigOps {
 		c.Logger = logger
 	}
 }
+
+// WithPaymailClient will set the paymail client used for outgoing requests (IE: sender PKI lookups)
+//
+// By default, a shared client with an in-memory response cache is used
+func WithPaymailClient(client paymail.ClientInterface) ConfigOps {
+	return func(c *Configuration) {
+		c.paymailClient = client
+	}
+}
+
+// WithTracerProvider will set the OpenTelemetry tracer provider used for server spans
+//
+// Default is the global tracer provider (otel.GetTracerProvider())
+func WithTracerProvider(provider trace.TracerProvider) ConfigOps {
+	return func(c *Configuration) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider will set the OpenTelemetry meter provider used for server metrics
+//
+// Default is the global meter provider (otel.GetMeterProvider())
+func WithMeterProvider(provider metric.MeterProvider) ConfigOps {
+	return func(c *Configuration) {
+		c.meterProvider = provider
+	}
+}
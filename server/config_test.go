@@ -10,7 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/beef"
 	"github.com/bsv-blockchain/go-paymail/errors"
+	"github.com/bsv-blockchain/go-paymail/spv"
 )
 
 // testLogger creates a race-free logger for testing (without Caller() hook)
@@ -407,6 +409,40 @@ func TestNewConfig(t *testing.T) {
 		assert.True(t, c.SenderValidationEnabled)
 	})
 
+	t.Run("default shared paymail client", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithLogger(testLogger()),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.NotNil(t, c.paymailClient)
+
+		client, err := c.getPaymailClient()
+		require.NoError(t, err)
+		assert.Equal(t, c.paymailClient, client)
+	})
+
+	t.Run("with paymail client", func(t *testing.T) {
+		client, err := paymail.NewClient()
+		require.NoError(t, err)
+
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithPaymailClient(client),
+			WithLogger(testLogger()),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, client, c.paymailClient)
+	})
+
 	t.Run("with p2p capabilities", func(t *testing.T) {
 		sl := &PaymailServiceLocator{}
 		sl.RegisterPaymailService(new(mockServiceProvider))
@@ -462,6 +498,82 @@ func TestNewConfig(t *testing.T) {
 		assert.Len(t, c.callableCapabilities, 7)
 	})
 
+	t.Run("with beef limits", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithBeefLimits(beef.Limits{MaxSize: 1024, MaxTransactions: 10}),
+			WithLogger(testLogger()),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, beef.Limits{MaxSize: 1024, MaxTransactions: 10}, c.BeefLimits)
+	})
+
+	t.Run("default beef limits", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		assert.Equal(t, beef.DefaultLimits(), c.BeefLimits)
+	})
+
+	t.Run("with fee policy", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithFeePolicy(spv.FeePolicy{DataRate: 5, StandardRate: 50}),
+			WithLogger(testLogger()),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, spv.FeePolicy{DataRate: 5, StandardRate: 50}, c.FeePolicy)
+	})
+
+	t.Run("default fee policy", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		assert.Equal(t, spv.DefaultFeePolicy(), c.FeePolicy)
+	})
+
+	t.Run("with merkle root cache", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithMerkleRootCache(spv.WithBatchWindow(0)),
+			WithLogger(testLogger()),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.IsType(t, &spv.CachedVerifier{}, c.merkleRootVerifier)
+	})
+
+	t.Run("without merkle root cache", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		assert.Equal(t, c.actions, c.merkleRootVerifier)
+	})
+
+	t.Run("with spv verifier options", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithSPVVerifierOptions(spv.WithoutRules(spv.RuleNameScripts)),
+			WithLogger(testLogger()),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		verifier := spv.NewVerifier(c.actions, c.spvVerifierOptions...)
+		assert.NotContains(t, verifier.Rules(), spv.RuleNameScripts)
+	})
+
 	t.Run("with basic routes", func(t *testing.T) {
 		sl := &PaymailServiceLocator{}
 		sl.RegisterPaymailService(new(mockServiceProvider))
@@ -8,11 +8,14 @@ import (
 
 // Server default values
 const (
-	DefaultAPIVersion       = "v1"             // Version of API
-	DefaultPrefix           = "https://"       // Paymail specs require SSL
-	DefaultSenderValidation = false            // If true, it requires extra sender validation
-	DefaultServerPort       = 3000             // Port for the server
-	DefaultTimeout          = 15 * time.Second // Default timeouts
+	DefaultAPIVersion           = "v1"             // Version of API
+	DefaultPaymailClientTimeout = 15 * time.Second // Default timeout for outgoing paymail requests
+	DefaultPrefix               = "https://"       // Paymail specs require SSL
+	DefaultSenderValidation     = false            // If true, it requires extra sender validation
+	DefaultServerPort           = 3000             // Port for the server
+	DefaultTimeout              = 15 * time.Second // Default timeouts
+
+	maxRequestMetadataSize = 64 << 10 // Size of a request body besides the BEEF (reference, metadata & JSON)
 )
 
 // Url params
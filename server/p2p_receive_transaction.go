@@ -1,6 +1,7 @@
 package server
 
 import (
+	stderrors "errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -92,9 +93,11 @@ func (c *Configuration) p2pReceiveBeefTx(context *gin.Context) {
 		panic("empty beef after parsing!")
 	}
 
-	err = spv.ExecuteSimplifiedPaymentVerification(context.Request.Context(), dBeef, c.actions)
-	if err != nil {
-		errors.ErrorResponse(context, errors.ErrSPVFailed, c.Logger)
+	// The error code is kept for the clients (except for low fees and malformed BEEFs), the report tells which checks failed
+	verifier := spv.NewVerifier(c.merkleRootVerifier, append([]spv.VerifierOps{spv.WithFeePolicy(c.FeePolicy)}, c.spvVerifierOptions...)...)
+	if report := verifier.Verify(context.Request.Context(), dBeef); !report.Valid {
+		c.Logger.Warn().Err(report.Err()).Str("txid", report.SubjectTxID).Msg("SPV failed")
+		errors.ErrorResponseWithDetails(context, spvResponseError(report), report, c.Logger)
 		return
 	}
 
@@ -108,3 +111,21 @@ func (c *Configuration) p2pReceiveBeefTx(context *gin.Context) {
 
 	context.JSON(http.StatusOK, response)
 }
+
+// spvResponseError will return the error of the response for the failed SPV
+//
+// Low fees and malformed BEEFs have their own error, the other failures are ErrSPVFailed
+func spvResponseError(report *spv.VerificationReport) error {
+	for _, spvErr := range []errors.SPVError{
+		errors.ErrFeeTooLow,
+		errors.ErrInvalidBUMPIndex,
+		errors.ErrDuplicateTransaction,
+		errors.ErrInvalidTransactionOrder,
+		errors.ErrDoubleSpend,
+	} {
+		if stderrors.Is(report.Err(), spvErr) {
+			return spvErr
+		}
+	}
+	return errors.ErrSPVFailed
+}
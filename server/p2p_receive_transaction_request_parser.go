@@ -2,9 +2,11 @@ package server
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"net/http"
 
 	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/beef"
 	"github.com/bsv-blockchain/go-paymail/errors"
 )
 
@@ -21,9 +23,17 @@ func parseP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPayma
 		incomingPaymailDomain: domain,
 	}
 
+	// The BEEF size is limited (hex is twice the size of the binary BEEF)
+	if format == beefP2pPayload {
+		req.Body = http.MaxBytesReader(nil, req.Body, maxBeefRequestSize(c.BeefLimits))
+	}
+
 	var p2pTransaction paymail.P2PTransaction
 	err := json.NewDecoder(req.Body).Decode(&p2pTransaction)
-	if err != nil {
+	var maxBytesErr *http.MaxBytesError
+	if stderrors.As(err, &maxBytesErr) {
+		return nil, errors.ErrRequestBodyTooLarge
+	} else if err != nil {
 		return nil, errors.ErrCannotBindRequest
 	}
 	if len(p2pTransaction.Reference) == 0 {
@@ -49,6 +59,15 @@ func parseP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPayma
 	return &requestData, nil
 }
 
+// maxBeefRequestSize will return the maximum size of a BEEF request body (the hex BEEF and the metadata)
+func maxBeefRequestSize(limits beef.Limits) int64 {
+	maxSize := limits.MaxSize
+	if maxSize <= 0 {
+		maxSize = beef.DefaultMaxSize
+	}
+	return 2*maxSize + maxRequestMetadataSize
+}
+
 func validateMetadata(c *Configuration, metadata *paymail.P2PMetaData) error {
 	// Check signature if: 1) sender validation enabled or 2) a signature was given (optional)
 	if c.SenderValidationEnabled || len(metadata.Signature) > 0 {
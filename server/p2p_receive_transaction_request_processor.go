@@ -3,11 +3,11 @@ package server
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
 	script "github.com/bsv-blockchain/go-sdk/script"
 	sdk "github.com/bsv-blockchain/go-sdk/transaction"
-	"github.com/rs/zerolog"
 
 	"github.com/bsv-blockchain/go-paymail"
 	"github.com/bsv-blockchain/go-paymail/beef"
@@ -34,7 +34,7 @@ func processP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPay
 		return returnError(err)
 	}
 
-	tx, beefData, err := getProcessedTxData(payload, format, c.Logger)
+	tx, beefData, err := getProcessedTxData(c, payload, format)
 	if err != nil {
 		return returnError(err)
 	}
@@ -54,7 +54,8 @@ func processP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPay
 	return payload, beefData, md, nil
 }
 
-func getProcessedTxData(payload *p2pReceiveTxReqPayload, format p2pPayloadFormat, log *zerolog.Logger) (*sdk.Transaction, *beef.DecodedBEEF, error) {
+func getProcessedTxData(c *Configuration, payload *p2pReceiveTxReqPayload, format p2pPayloadFormat) (*sdk.Transaction, *beef.DecodedBEEF, error) {
+	log := c.Logger
 	var processedTx *sdk.Transaction
 	var beefData *beef.DecodedBEEF
 	var err error
@@ -68,7 +69,7 @@ func getProcessedTxData(payload *p2pReceiveTxReqPayload, format p2pPayloadFormat
 		}
 
 	case beefP2pPayload:
-		beefData, err = beef.DecodeBEEF(payload.Beef)
+		beefData, err = beef.NewDecoder(strings.NewReader(payload.Beef), c.BeefLimits).Decode()
 		if err != nil {
 			log.Error().Msgf("error while parsing beef: %s", err.Error())
 			return nil, nil, errors.ErrProcessingBEEF
@@ -97,6 +98,7 @@ func verifyIncomingPaymail(ctx context.Context, c *Configuration, md *RequestMet
 	return nil
 }
 
+// verifySignature will verify the signature of the txid (base64, see paymail.EncodeSignature) with the PubKey of the sender
 func verifySignature(metadata *paymail.P2PMetaData, txID string) error {
 	// Get the address from pubKey
 	var rawAddress *script.Address
@@ -106,8 +108,14 @@ func verifySignature(metadata *paymail.P2PMetaData, txID string) error {
 		return errors.ErrInvalidPubKey
 	}
 
+	// Decode the signature (base64, as created by the sender)
+	var sigBytes []byte
+	if sigBytes, err = paymail.DecodeSignature(metadata.Signature); err != nil {
+		return errors.ErrInvalidSignature
+	}
+
 	// Validate the signature of the tx id
-	if err = bsm.VerifyMessage(rawAddress.AddressString, []byte(metadata.Signature), []byte(txID)); err != nil {
+	if err = bsm.VerifyMessage(rawAddress.AddressString, sigBytes, []byte(txID)); err != nil {
 		return errors.ErrInvalidSignature
 	}
 
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// Test_verifySignature will test the verification of the sender signature of the txid
+func Test_verifySignature(t *testing.T) {
+	t.Parallel()
+
+	const txID = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	sigBytes, err := bsm.SignMessage(key, []byte(txID))
+	require.NoError(t, err)
+	pubKey := key.PubKey().ToDERHex()
+
+	t.Run("base64 signature of the sender", func(t *testing.T) {
+		metadata := &paymail.P2PMetaData{PublicKey: pubKey, Signature: paymail.EncodeSignature(sigBytes)}
+		require.NoError(t, verifySignature(metadata, txID))
+	})
+
+	t.Run("signature of another txid", func(t *testing.T) {
+		metadata := &paymail.P2PMetaData{PublicKey: pubKey, Signature: paymail.EncodeSignature(sigBytes)}
+		require.ErrorIs(t, verifySignature(metadata, strings.Repeat("0", 64)), errors.ErrInvalidSignature)
+	})
+
+	t.Run("signature is not base64", func(t *testing.T) {
+		metadata := &paymail.P2PMetaData{PublicKey: pubKey, Signature: string(sigBytes)}
+		require.ErrorIs(t, verifySignature(metadata, txID), errors.ErrInvalidSignature)
+	})
+
+	t.Run("invalid pubkey", func(t *testing.T) {
+		metadata := &paymail.P2PMetaData{PublicKey: "invalid", Signature: paymail.EncodeSignature(sigBytes)}
+		require.ErrorIs(t, verifySignature(metadata, txID), errors.ErrInvalidPubKey)
+	})
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+	"github.com/bsv-blockchain/go-paymail/spv"
+)
+
+// testBEEFHex is a valid BEEF with one mined parent and the processed transaction
+const testBEEFHex = "0100beef01fe636d0c0007021400fe507c0c7aa754cef1f7889d5fd395cf1f785dd7de98eed895dbedfe4e5bc70d1502ac4e164f5bc16746bb0868404292ac8318bbac3800e4aad13a014da427adce3e010b00bc4ff395efd11719b277694cface5aa50d085a0bb81f613f70313acd28cf4557010400574b2d9142b8d28b61d88e3b2c3f44d858411356b49a28a4643b6d1a6a092a5201030051a05fc84d531b5d250c23f4f886f6812f9fe3f402d61607f977b4ecd2701c19010000fd781529d58fc2523cf396a7f25440b409857e7e221766c57214b1d38c7b481f01010062f542f45ea3660f86c013ced80534cb5fd4c19d66c56e7e8c5d4bf2d40acc5e010100b121e91836fd7cd5102b654e9f72f3cf6fdbfd0b161c53a9c54b12c841126331020100000001cd4e4cac3c7b56920d1e7655e7e260d31f29d9a388d04910f1bbd72304a79029010000006b483045022100e75279a205a547c445719420aa3138bf14743e3f42618e5f86a19bde14bb95f7022064777d34776b05d816daf1699493fcdf2ef5a5ab1ad710d9c97bfb5b8f7cef3641210263e2dee22b1ddc5e11f6fab8bcd2378bdd19580d640501ea956ec0e786f93e76ffffffff013e660000000000001976a9146bfd5c7fbe21529d45803dbcf0c87dd3c71efbc288ac0000000001000100000001ac4e164f5bc16746bb0868404292ac8318bbac3800e4aad13a014da427adce3e000000006a47304402203a61a2e931612b4bda08d541cfb980885173b8dcf64a3471238ae7abcd368d6402204cbf24f04b9aa2256d8901f0ed97866603d2be8324c2bfb7a37bf8fc90edd5b441210263e2dee22b1ddc5e11f6fab8bcd2378bdd19580d640501ea956ec0e786f93e76ffffffff013c660000000000001976a9146bfd5c7fbe21529d45803dbcf0c87dd3c71efbc288ac0000000000"
+
+// newBeefRequest will return a P2P BEEF request with the BEEF
+func newBeefRequest(beefHex string) *http.Request {
+	body := `{"beef":"` + beefHex + `","reference":"ref","metadata":{}}`
+	return httptest.NewRequest(http.MethodPost, "/v1/bsvalias/beef/test@test.com", strings.NewReader(body))
+}
+
+// TestConfiguration_BeefLimits will test the limits of incoming BEEF transactions
+func TestConfiguration_BeefLimits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("beef within the limits", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+
+		payload, err := parseP2pReceiveTxRequest(c, newBeefRequest(testBEEFHex), "test@test.com", beefP2pPayload)
+		require.NoError(t, err)
+		tx, beefData, err := getProcessedTxData(c, payload, beefP2pPayload)
+		require.NoError(t, err)
+		assert.Len(t, beefData.Transactions, 2)
+		assert.Equal(t, "157428aee67d11123203735e4c540fa1bdab3b36d5882c6f8c5ff79f07d20d1c", tx.TxID().String())
+	})
+
+	t.Run("request body too large", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		c.BeefLimits = beef.Limits{MaxSize: 100}
+
+		payload, err := parseP2pReceiveTxRequest(c, newBeefRequest(strings.Repeat("00", 100+maxRequestMetadataSize)), "test@test.com", beefP2pPayload)
+		require.ErrorIs(t, err, errors.ErrRequestBodyTooLarge)
+		assert.Nil(t, payload)
+	})
+
+	t.Run("beef exceeding the limits", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		c.BeefLimits = beef.Limits{MaxTransactions: 1}
+
+		payload, err := parseP2pReceiveTxRequest(c, newBeefRequest(testBEEFHex), "test@test.com", beefP2pPayload)
+		require.NoError(t, err)
+		_, _, err = getProcessedTxData(c, payload, beefP2pPayload)
+		require.ErrorIs(t, err, errors.ErrProcessingBEEF)
+	})
+
+	t.Run("basic p2p requests are not limited", func(t *testing.T) {
+		c := testConfig(t, "test.com")
+		c.BeefLimits = beef.Limits{MaxSize: 1}
+
+		body := `{"hex":"` + strings.Repeat("00", maxRequestMetadataSize) + `","reference":"ref","metadata":{}}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/bsvalias/receive-transaction/test@test.com", strings.NewReader(body))
+		payload, err := parseP2pReceiveTxRequest(c, req, "test@test.com", basicP2pPayload)
+		require.NoError(t, err)
+		assert.Equal(t, &paymail.P2PMetaData{}, payload.MetaData)
+	})
+}
+
+// TestConfiguration_FeePolicy will test the rejection of incoming BEEF transactions with low fees
+func TestConfiguration_FeePolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fee too low", func(t *testing.T) {
+		dBeef, err := beef.DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		verifier := spv.NewVerifier(new(mockServiceProvider), spv.WithRules(spv.NewFeeRule(spv.FeePolicy{StandardRate: 1e9})))
+
+		report := verifier.Verify(context.Background(), dBeef)
+		require.False(t, report.Valid)
+		assert.Equal(t, errors.ErrFeeTooLow, spvResponseError(report))
+	})
+
+	t.Run("malformed BEEF", func(t *testing.T) {
+		dBeef, err := beef.DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		dBeef.Transactions = append(dBeef.Transactions, dBeef.Transactions[len(dBeef.Transactions)-1])
+		verifier := spv.NewVerifier(new(mockServiceProvider))
+
+		report := verifier.Verify(context.Background(), dBeef)
+		require.False(t, report.Valid)
+		assert.Equal(t, errors.ErrDuplicateTransaction, spvResponseError(report))
+	})
+
+	t.Run("other spv failures", func(t *testing.T) {
+		dBeef, err := beef.DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+		dBeef.Transactions = dBeef.Transactions[1:]
+		verifier := spv.NewVerifier(new(mockServiceProvider), spv.WithFeePolicy(spv.DefaultFeePolicy()))
+
+		report := verifier.Verify(context.Background(), dBeef)
+		require.False(t, report.Valid)
+		assert.Equal(t, errors.ErrSPVFailed, spvResponseError(report))
+	})
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -45,7 +46,7 @@ func (c *Configuration) pikeGetOutputTemplates(rc *gin.Context) {
 		return
 	}
 
-	pki, err := getPKI(paymentDestinationRequest.SenderPaymail)
+	pki, err := c.getPKI(rc.Request.Context(), paymentDestinationRequest.SenderPaymail)
 	if err != nil {
 		errors.ErrorResponse(rc, err, c.Logger)
 		return
@@ -62,27 +63,31 @@ func (c *Configuration) pikeGetOutputTemplates(rc *gin.Context) {
 	rc.JSON(http.StatusOK, response)
 }
 
-func getPKI(paymailAddress string) (*paymail.PKIResponse, error) {
-	alias, domain, paymailAddress := paymail.SanitizePaymail(paymailAddress)
-	if len(paymailAddress) == 0 {
+// getPKI will fetch the PKI for the given paymail address
+func (c *Configuration) getPKI(ctx context.Context, paymailAddress string) (*paymail.PKIResponse, error) {
+	if _, _, paymailAddress = paymail.SanitizePaymail(paymailAddress); len(paymailAddress) == 0 {
 		return nil, errors.ErrInvalidPaymail
 	}
 
-	client, err := paymail.NewClient()
+	client, err := c.getPaymailClient()
 	if err != nil {
 		return nil, err
 	}
 
-	var capabilities *paymail.CapabilitiesResponse
-	if capabilities, err = client.GetCapabilities(domain, paymail.DefaultPort); err != nil {
+	return resolvePKI(ctx, client, paymailAddress)
+}
+
+// resolvePKI will discover the provider for the paymail address and fetch its PKI
+func resolvePKI(ctx context.Context, client paymail.ClientInterface, paymailAddress string) (*paymail.PKIResponse, error) {
+	resolver, err := paymail.NewResolver(client)
+	if err != nil {
 		return nil, err
 	}
 
-	pkiURL := capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate)
-
-	var pki *paymail.PKIResponse
-	if pki, err = client.GetPKI(pkiURL, alias, domain); err != nil {
+	var endpoint *paymail.PaymailEndpoint
+	if endpoint, err = resolver.Resolve(ctx, paymailAddress); err != nil {
 		return nil, err
 	}
-	return pki, nil
+
+	return client.GetPKICtx(ctx, endpoint.PKIURL(), endpoint.Alias, endpoint.Domain)
 }
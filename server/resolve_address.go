@@ -1,9 +1,8 @@
 package server
 
 import (
-	"net"
+	"context"
 	"net/http"
-	"time"
 
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
 	script "github.com/bsv-blockchain/go-sdk/script"
@@ -79,7 +78,7 @@ func (c *Configuration) resolveAddress(context *gin.Context) {
 
 			// Get the pubKey from the corresponding sender paymail address
 			var senderPubKey *ec.PublicKey
-			senderPubKey, err = getSenderPubKey(senderRequest.SenderHandle)
+			senderPubKey, err = c.getSenderPubKey(context.Request.Context(), senderRequest.SenderHandle)
 			if err != nil {
 				errors.ErrorResponse(context, err, c.Logger)
 				return
@@ -131,41 +130,18 @@ func (c *Configuration) resolveAddress(context *gin.Context) {
 }
 
 // getSenderPubKey will fetch the pubKey from a PKI request for the sender handle
-func getSenderPubKey(senderPaymailAddress string) (*ec.PublicKey, error) {
-	// Sanitize and break apart
-	alias, domain, _ := paymail.SanitizePaymail(senderPaymailAddress)
-
+//
+// The lookups are bound to the given (request) context
+func (c *Configuration) getSenderPubKey(ctx context.Context, senderPaymailAddress string) (*ec.PublicKey, error) {
 	// Load the client
-	client, err := paymail.NewClient(paymail.WithHTTPTimeout(15 * time.Second))
+	client, err := c.getPaymailClient()
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the SRV record
-	var srv *net.SRV
-	if srv, err = client.GetSRVRecord(
-		paymail.DefaultServiceName, paymail.DefaultProtocol, domain,
-	); err != nil {
-		return nil, err
-	}
-
-	// Get the capabilities
-	// This is required first to get the corresponding PKI endpoint url
-	var capabilities *paymail.CapabilitiesResponse
-	if capabilities, err = client.GetCapabilities(
-		srv.Target, paymail.DefaultPort,
-	); err != nil {
-		return nil, err
-	}
-
-	// Extract the PKI URL from the capabilities response
-	pkiURL := capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate)
-
-	// Get the actual PKI
+	// Get the actual PKI (via host and capability discovery)
 	var pki *paymail.PKIResponse
-	if pki, err = client.GetPKI(
-		pkiURL, alias, domain,
-	); err != nil {
+	if pki, err = resolvePKI(ctx, client, senderPaymailAddress); err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,15 +11,25 @@ import (
 func Test_getSenderPubKey(t *testing.T) {
 	// todo: this needs proper mocking
 
+	c := &Configuration{}
+
 	t.Run("error - bad domain", func(t *testing.T) {
-		key, err := getSenderPubKey("bad@domain.com")
+		key, err := c.getSenderPubKey(context.Background(), "bad@domain.com")
 		require.Error(t, err)
 		require.Nil(t, key)
 	})
 
 	t.Run("valid - good paymail", func(t *testing.T) {
-		key, err := getSenderPubKey("mrzz@handcash.io")
+		key, err := c.getSenderPubKey(context.Background(), "mrzz@handcash.io")
 		require.NoError(t, err)
 		require.NotNil(t, key)
 	})
+
+	t.Run("error - canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		key, err := c.getSenderPubKey(ctx, "mrzz@handcash.io")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, key)
+	})
 }
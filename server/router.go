@@ -41,25 +41,26 @@ func (c *Configuration) RegisterBasicRoutes(engine *gin.Engine) {
 
 // RegisterRoutes register all the available paymail routes to the http router
 func (c *Configuration) RegisterRoutes(engine *gin.Engine) {
-	engine.GET("/.well-known/"+c.ServiceName, c.showCapabilities) // service discovery
+	discoveryPath := "/.well-known/" + c.ServiceName
+	engine.GET(discoveryPath, c.handlers(telemetryOperationCapabilities, "", discoveryPath, c.showCapabilities)...) // service discovery
 
-	for _, cap := range c.callableCapabilities {
-		c.registerRoute(engine, cap)
+	for key, cap := range c.callableCapabilities {
+		c.registerRoute(engine, key, key, cap)
 	}
 
-	for _, nestedCap := range c.nestedCapabilities {
-		for _, cap := range nestedCap {
-			c.registerRoute(engine, cap)
+	for brfc, nestedCap := range c.nestedCapabilities {
+		for key, cap := range nestedCap {
+			c.registerRoute(engine, key, brfc, cap)
 		}
 	}
 }
 
-func (c *Configuration) registerRoute(engine *gin.Engine, capability CallableCapability) {
+func (c *Configuration) registerRoute(engine *gin.Engine, operation, brfc string, capability CallableCapability) {
 	routerPath := c.templateToRouterPath(capability.Path)
 	engine.Handle(
 		capability.Method,
 		routerPath,
-		capability.Handler,
+		c.handlers(operation, brfc, routerPath, capability.Handler)...,
 	)
 }
 
@@ -0,0 +1,139 @@
+package server
+
+import (
+	stderrors "errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// AttributeErrorCode is the telemetry attribute key for the error code returned by a handler
+const AttributeErrorCode = attribute.Key("paymail.error_code")
+
+// telemetryOperationCapabilities is the operation name of the capability discovery route
+const telemetryOperationCapabilities = "capabilities"
+
+// telemetry holds the OpenTelemetry tracer and instruments for the server
+type telemetry struct {
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+	tracer   trace.Tracer
+}
+
+// newTelemetry will create the tracer and instruments (using the global providers if not set)
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*telemetry, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	t := &telemetry{tracer: tracerProvider.Tracer(paymail.InstrumentationName + "/server")}
+	meter := meterProvider.Meter(paymail.InstrumentationName + "/server")
+
+	var err error
+	if t.requests, err = meter.Int64Counter(
+		"paymail.server.requests",
+		metric.WithDescription("Number of paymail requests handled by the server"),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		return nil, err
+	}
+	if t.duration, err = meter.Float64Histogram(
+		"paymail.server.duration",
+		metric.WithDescription("Duration of paymail requests handled by the server"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// instrument will return the middleware that creates a span and records the metrics for a route
+//
+// The brfc is the capability key (the parent key for nested capabilities) and the operation
+// is the key of the capability itself. The domain is only set on the span (it comes from the
+// request and would make the metrics unbounded).
+func (c *Configuration) instrument(operation, brfc, route string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		start := time.Now()
+		attributes := []attribute.KeyValue{
+			paymail.AttributeOperation.String(operation),
+			paymail.AttributeBRFC.String(brfc),
+		}
+
+		// Continue the trace of the caller (if propagated)
+		ctx := otel.GetTextMapPropagator().Extract(context.Request.Context(), propagation.HeaderCarrier(context.Request.Header))
+		ctx, span := c.telemetry.tracer.Start(ctx, context.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attributes...),
+			trace.WithAttributes(
+				attribute.String("http.request.method", context.Request.Method),
+				attribute.String("http.route", route),
+				paymail.AttributeDomain.String(requestDomain(context, operation)),
+			),
+		)
+		context.Request = context.Request.WithContext(ctx)
+
+		context.Next()
+
+		// Set the result (and the error code if the handler failed)
+		statusCode := context.Writer.Status()
+		attributes = append(attributes, paymail.AttributeStatusCode.Int(statusCode))
+		if code := errorCode(context); len(code) > 0 || statusCode >= 400 {
+			if len(code) == 0 {
+				code = errors.UnknownErrorCode
+			}
+			attributes = append(attributes, paymail.AttributeResult.String(paymail.ResultError), AttributeErrorCode.String(code))
+			span.SetStatus(codes.Error, code)
+		} else {
+			attributes = append(attributes, paymail.AttributeResult.String(paymail.ResultOK))
+		}
+		span.SetAttributes(attributes...)
+		span.End()
+
+		measurement := metric.WithAttributes(attributes...)
+		c.telemetry.requests.Add(ctx, 1, measurement)
+		c.telemetry.duration.Record(ctx, time.Since(start).Seconds(), measurement)
+	}
+}
+
+// handlers will return the handlers for a route (with the telemetry middleware if enabled)
+func (c *Configuration) handlers(operation, brfc, route string, handler gin.HandlerFunc) []gin.HandlerFunc {
+	if c.telemetry == nil {
+		return []gin.HandlerFunc{handler}
+	}
+	return []gin.HandlerFunc{c.instrument(operation, brfc, route), handler}
+}
+
+// requestDomain will return the domain of the request (the paymail domain or the host for discovery)
+func requestDomain(context *gin.Context, operation string) string {
+	if operation == telemetryOperationCapabilities {
+		return context.Request.Host
+	}
+	_, domain, _ := paymail.SanitizePaymail(context.Param(PaymailAddressParamName))
+	return domain
+}
+
+// errorCode will return the code of the last error set by errors.ErrorResponse (if any)
+func errorCode(context *gin.Context) string {
+	lastErr := context.Errors.Last()
+	if lastErr == nil {
+		return ""
+	}
+	var extendedErr errors.ExtendedError
+	if stderrors.As(lastErr.Err, &extendedErr) {
+		return extendedErr.GetCode()
+	}
+	return errors.UnknownErrorCode
+}
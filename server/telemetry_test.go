@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bsv-blockchain/go-paymail"
+)
+
+// testTelemetryServer will return the handlers using the in-memory span exporter and metric reader
+func testTelemetryServer(t *testing.T) (http.Handler, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+
+	sl := &PaymailServiceLocator{}
+	sl.RegisterPaymailService(new(mockServiceProvider))
+	sl.RegisterPikeContactService(new(mockServiceProvider))
+	config, err := NewConfig(
+		sl,
+		WithDomain("test.com"),
+		WithLogger(testLogger()),
+		WithPikeContactCapabilities(),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	require.NoError(t, err)
+	return Handlers(config), exporter, reader
+}
+
+// serveTestRequest will send the request to the handlers and return the only exported span
+func serveTestRequest(t *testing.T, handler http.Handler, exporter *tracetest.InMemoryExporter,
+	method, target string,
+) tracetest.SpanStub {
+	req := httptest.NewRequestWithContext(context.Background(), method, target, nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	return spans[0]
+}
+
+// testSpanAttributes will return the span attributes as a set
+func testSpanAttributes(span tracetest.SpanStub) *attribute.Set {
+	set := attribute.NewSet(span.Attributes...)
+	return &set
+}
+
+// TestConfiguration_Telemetry will test the server spans and metrics
+func TestConfiguration_Telemetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capability handler error", func(t *testing.T) {
+		handler, exporter, reader := testTelemetryServer(t)
+
+		span := serveTestRequest(t, handler, exporter, http.MethodGet, "http://test.com/v1/bsvalias/id/mrz@test.com")
+		assert.Equal(t, "GET /v1/bsvalias/id/:paymailAddress", span.Name)
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+		assert.Equal(t, codes.Error, span.Status.Code)
+
+		attributes := testSpanAttributes(span)
+		value, _ := attributes.Value(paymail.AttributeBRFC)
+		assert.Equal(t, paymail.BRFCPki, value.AsString())
+		value, _ = attributes.Value(paymail.AttributeDomain)
+		assert.Equal(t, "test.com", value.AsString())
+		value, _ = attributes.Value(paymail.AttributeResult)
+		assert.Equal(t, paymail.ResultError, value.AsString())
+		value, _ = attributes.Value(AttributeErrorCode)
+		assert.Equal(t, "error-unknown", value.AsString())
+		value, _ = attributes.Value(paymail.AttributeStatusCode)
+		assert.Equal(t, int64(http.StatusInternalServerError), value.AsInt64())
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(context.Background(), &rm))
+		require.Len(t, rm.ScopeMetrics, 1)
+		require.Len(t, rm.ScopeMetrics[0].Metrics, 2)
+		requests, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+		require.Len(t, requests.DataPoints, 1)
+		assert.Equal(t, int64(1), requests.DataPoints[0].Value)
+		_, hasDomain := requests.DataPoints[0].Attributes.Value(paymail.AttributeDomain)
+		assert.False(t, hasDomain)
+	})
+
+	t.Run("spv error code", func(t *testing.T) {
+		handler, exporter, _ := testTelemetryServer(t)
+
+		span := serveTestRequest(t, handler, exporter, http.MethodGet, "http://test.com/v1/bsvalias/id/mrz@unknown.com")
+
+		attributes := testSpanAttributes(span)
+		value, _ := attributes.Value(paymail.AttributeDomain)
+		assert.Equal(t, "unknown.com", value.AsString())
+		value, _ = attributes.Value(AttributeErrorCode)
+		assert.Equal(t, "error-capabilities-domain-unknown", value.AsString())
+		value, _ = attributes.Value(paymail.AttributeStatusCode)
+		assert.Equal(t, int64(http.StatusBadRequest), value.AsInt64())
+	})
+
+	t.Run("nested capability", func(t *testing.T) {
+		handler, exporter, _ := testTelemetryServer(t)
+
+		span := serveTestRequest(t, handler, exporter, http.MethodPost, "http://test.com/v1/bsvalias/contact/invite/mrz@test.com")
+
+		attributes := testSpanAttributes(span)
+		value, _ := attributes.Value(paymail.AttributeBRFC)
+		assert.Equal(t, paymail.BRFCPike, value.AsString())
+		value, _ = attributes.Value(paymail.AttributeOperation)
+		assert.Equal(t, paymail.BRFCPikeInvite, value.AsString())
+	})
+
+	t.Run("capability discovery", func(t *testing.T) {
+		handler, exporter, _ := testTelemetryServer(t)
+
+		span := serveTestRequest(t, handler, exporter, http.MethodGet, "http://test.com/.well-known/bsvalias")
+		assert.Equal(t, "GET /.well-known/bsvalias", span.Name)
+		assert.Equal(t, codes.Unset, span.Status.Code)
+
+		attributes := testSpanAttributes(span)
+		value, _ := attributes.Value(paymail.AttributeDomain)
+		assert.Equal(t, "test.com", value.AsString())
+		value, _ = attributes.Value(paymail.AttributeResult)
+		assert.Equal(t, paymail.ResultOK, value.AsString())
+		_, hasCode := attributes.Value(AttributeErrorCode)
+		assert.False(t, hasCode)
+	})
+}
+
+// TestWithTracerProvider will test the method WithTracerProvider()
+func TestWithTracerProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := sdktrace.NewTracerProvider()
+	c := &Configuration{}
+	WithTracerProvider(provider)(c)
+	assert.Equal(t, provider, c.tracerProvider)
+}
+
+// TestWithMeterProvider will test the method WithMeterProvider()
+func TestWithMeterProvider(t *testing.T) {
+	t.Parallel()
+
+	provider := sdkmetric.NewMeterProvider()
+	c := &Configuration{}
+	WithMeterProvider(provider)(c)
+	assert.Equal(t, provider, c.meterProvider)
+}
@@ -9,26 +9,12 @@ import (
 	"github.com/bsv-blockchain/go-paymail/errors"
 )
 
-func ensureAncestorsArePresentInBump(tx *sdk.Transaction, dBeef *beef.DecodedBEEF) error {
-	ancestors, err := findMinedAncestors(tx, dBeef.Transactions)
-	if err != nil {
-		return err
-	}
-
-	for _, tx := range ancestors {
-		if !existsInBumps(tx, dBeef.BUMPs) {
-			return errors.ErrBUMPAncestorNotPresent
-		}
-	}
-
-	return nil
-}
-
-func findMinedAncestors(tx *sdk.Transaction, ancestors []*beef.TxData) (map[string]*beef.TxData, error) {
+func findMinedAncestors(tx *sdk.Transaction, dBeef *beef.DecodedBEEF) (map[string]*beef.TxData, error) {
 	am := make(map[string]*beef.TxData)
+	visited := make(map[string]struct{})
 
 	for _, input := range tx.Inputs {
-		if err := findMinedAncestorsForInput(input, ancestors, am); err != nil {
+		if err := findMinedAncestorsForInput(input, dBeef, am, visited); err != nil {
 			return nil, err
 		}
 	}
@@ -36,19 +22,29 @@ func findMinedAncestors(tx *sdk.Transaction, ancestors []*beef.TxData) (map[stri
 	return am, nil
 }
 
-func findMinedAncestorsForInput(input *sdk.TransactionInput, ancestors []*beef.TxData, ma map[string]*beef.TxData) error {
-	parent := findParentForInput(input, ancestors)
+func findMinedAncestorsForInput(input *sdk.TransactionInput, dBeef *beef.DecodedBEEF, ma map[string]*beef.TxData, visited map[string]struct{}) error {
+	parent := findParentForInput(input, dBeef)
 	if parent == nil {
 		return errors.ErrBUMPCouldNotFindMinedParent
 	}
 
+	if parent.TxIDOnly {
+		return nil // known transaction, no BUMP needed
+	}
+
 	if !parent.Unmined() {
 		ma[parent.GetTxID()] = parent
 		return nil
 	}
 
+	// the unmined ancestors shared by several inputs are visited once
+	if _, ok := visited[parent.GetTxID()]; ok {
+		return nil
+	}
+	visited[parent.GetTxID()] = struct{}{}
+
 	for _, in := range parent.Transaction.Inputs {
-		err := findMinedAncestorsForInput(in, ancestors, ma) // we don't have to worry about infinite recursion - the graph will always be acyclic due to the nature of the transactions
+		err := findMinedAncestorsForInput(in, dBeef, ma, visited) // we don't have to worry about infinite recursion - the graph will always be acyclic due to the nature of the transactions
 		if err != nil {
 			return err
 		}
@@ -65,7 +61,7 @@ func existsInBumps(tx *beef.TxData, bumps beef.BUMPs) bool {
 	bumpIdx := int(*tx.BumpIndex) // #nosec G115 - overflow checked above
 	txID := tx.GetTxID()
 
-	if len(bumps) > bumpIdx {
+	if len(bumps) > bumpIdx && len(bumps[bumpIdx].Path) > 0 {
 		leafs := bumps[bumpIdx].Path[0]
 
 		for _, lf := range leafs {
@@ -0,0 +1,314 @@
+package spv
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults of the cached verifier
+const (
+	DefaultBatchTimeout      = 30 * time.Second      // An upstream call (and the bisection of a failed call) is canceled after this duration
+	DefaultBatchWindow       = 10 * time.Millisecond // Requests are batched within the window
+	DefaultCacheSize         = 10000                 // Maximum number of cached merkle roots
+	DefaultConfirmationDepth = 6                     // Merkle roots deeper than this are cached permanently
+	DefaultMaxBatchSize      = 500                   // A batch is sent as soon as it reaches this size
+	DefaultNegativeTTL       = 10 * time.Second      // Failed merkle roots are cached for this duration
+	DefaultRecentTTL         = time.Minute           // Recent merkle roots (within the depth) are cached for this duration
+)
+
+// CachedVerifierOps allow functional options to be supplied
+// that overwrite default cached verifier options.
+type CachedVerifierOps func(v *CachedVerifier)
+
+// WithBatchTimeout will set the timeout of the upstream calls of a batch (with the bisection of a failed call)
+//
+// The upstream calls are shared by the requests of the batch: they are not canceled with the requests
+func WithBatchTimeout(timeout time.Duration) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		if timeout > 0 {
+			v.batchTimeout = timeout
+		}
+	}
+}
+
+// WithBatchWindow will set the window in which concurrent requests are batched into one upstream call
+//
+// A zero window sends the requests without waiting (concurrent requests can still be batched)
+func WithBatchWindow(window time.Duration) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		v.batchWindow = window
+	}
+}
+
+// WithCacheSize will set the maximum number of cached merkle roots
+func WithCacheSize(size int) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		if size > 0 {
+			v.cacheSize = size
+		}
+	}
+}
+
+// WithConfirmationDepth will set the depth (below the highest verified height) of the permanently cached merkle roots
+func WithConfirmationDepth(depth uint64) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		v.confirmationDepth = depth
+	}
+}
+
+// WithMaxBatchSize will set the maximum number of merkle roots of an upstream call
+func WithMaxBatchSize(size int) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		if size > 0 {
+			v.maxBatchSize = size
+		}
+	}
+}
+
+// WithNegativeTTL will set the duration failed merkle roots are cached (zero disables the negative cache)
+//
+// The upstream errors are cached too (e.g. a timeout), a short duration is recommended
+func WithNegativeTTL(ttl time.Duration) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		v.negativeTTL = ttl
+	}
+}
+
+// WithRecentTTL will set the duration the recent merkle roots (within the confirmation depth) are cached
+//
+// Recent blocks can be replaced by a reorg (zero disables the cache of recent merkle roots)
+func WithRecentTTL(ttl time.Duration) CachedVerifierOps {
+	return func(v *CachedVerifier) {
+		v.recentTTL = ttl
+	}
+}
+
+// CachedVerifier is a MerkleRootVerifier caching, coalescing and batching the requests of an upstream verifier
+//
+// Verified merkle roots deeper than the confirmation depth (below the highest verified height) are cached
+// permanently (within the cache size), recent and failed ones for their TTL. Concurrent requests for the
+// same merkle root share one request, and the requests within the batch window share one upstream call
+type CachedVerifier struct {
+	batch             *merkleRootBatch // The batch waiting for the window (nil if none)
+	batchTimeout      time.Duration
+	batchWindow       time.Duration
+	bestHeight        uint64 // The highest verified height
+	cache             map[string]*cachedMerkleRoot
+	cacheSize         int
+	confirmationDepth uint64
+	inFlight          map[string]*merkleRootCall
+	maxBatchSize      int
+	mu                sync.Mutex
+	negativeTTL       time.Duration
+	recentTTL         time.Duration
+	upstream          MerkleRootVerifier
+}
+
+// cachedMerkleRoot is the cached result of a merkle root
+type cachedMerkleRoot struct {
+	err     error
+	expires time.Time // Zero if permanent
+}
+
+// merkleRootCall is the request of a merkle root (shared by the concurrent requests)
+type merkleRootCall struct {
+	done chan struct{}
+	err  error
+	item *MerkleRootConfirmationRequestItem
+	key  string
+}
+
+// merkleRootBatch is the calls sent in one upstream call
+type merkleRootBatch struct {
+	calls []*merkleRootCall
+	ctx   context.Context
+	timer *time.Timer
+}
+
+// NewCachedVerifier will return the caching and batching decorator of the upstream verifier
+func NewCachedVerifier(upstream MerkleRootVerifier, opts ...CachedVerifierOps) *CachedVerifier {
+	v := &CachedVerifier{
+		batchTimeout:      DefaultBatchTimeout,
+		batchWindow:       DefaultBatchWindow,
+		cache:             make(map[string]*cachedMerkleRoot),
+		cacheSize:         DefaultCacheSize,
+		confirmationDepth: DefaultConfirmationDepth,
+		inFlight:          make(map[string]*merkleRootCall),
+		maxBatchSize:      DefaultMaxBatchSize,
+		negativeTTL:       DefaultNegativeTTL,
+		recentTTL:         DefaultRecentTTL,
+		upstream:          upstream,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(v)
+		}
+	}
+	return v
+}
+
+// VerifyMerkleRoots will verify the merkle roots from the cache, or with the upstream verifier
+//
+// The first error (in the order of the merkle roots) is returned
+func (v *CachedVerifier) VerifyMerkleRoots(ctx context.Context, merkleRoots []*MerkleRootConfirmationRequestItem) error {
+	calls := make([]*merkleRootCall, 0, len(merkleRoots))
+
+	v.mu.Lock()
+	for _, item := range merkleRoots {
+		key := merkleRootKey(item)
+		if cached := v.cached(key); cached != nil {
+			if cached.err != nil {
+				v.mu.Unlock()
+				return cached.err
+			}
+			continue
+		}
+
+		call, ok := v.inFlight[key]
+		if !ok {
+			call = &merkleRootCall{done: make(chan struct{}), item: item, key: key}
+			v.inFlight[key] = call
+			v.enqueue(ctx, call)
+		}
+		calls = append(calls, call)
+	}
+	v.mu.Unlock()
+
+	for _, call := range calls {
+		select {
+		case <-call.done:
+			if call.err != nil {
+				return call.err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// cached will return the cached result of the merkle root (nil if not cached or expired, the lock must be held)
+func (v *CachedVerifier) cached(key string) *cachedMerkleRoot {
+	cached, ok := v.cache[key]
+	if !ok {
+		return nil
+	} else if !cached.expires.IsZero() && time.Now().After(cached.expires) {
+		delete(v.cache, key)
+		return nil
+	}
+	return cached
+}
+
+// enqueue will add the call to the waiting batch (the lock must be held)
+//
+// The batch is sent when the window ends, or as soon as it is full. The upstream call keeps the values
+// of the context of the first request, but it is not canceled with it (the batch is shared): it is
+// canceled after the batch timeout
+func (v *CachedVerifier) enqueue(ctx context.Context, call *merkleRootCall) {
+	if v.batch == nil {
+		batch := &merkleRootBatch{ctx: context.WithoutCancel(ctx)}
+		batch.timer = time.AfterFunc(v.batchWindow, func() {
+			v.flush(batch)
+		})
+		v.batch = batch
+	}
+
+	v.batch.calls = append(v.batch.calls, call)
+	if len(v.batch.calls) >= v.maxBatchSize {
+		batch := v.batch
+		batch.timer.Stop()
+		v.batch = nil
+		go v.execute(batch)
+	}
+}
+
+// flush will send the batch at the end of its window (unless it was already sent when full)
+func (v *CachedVerifier) flush(batch *merkleRootBatch) {
+	v.mu.Lock()
+	if v.batch != batch {
+		v.mu.Unlock()
+		return
+	}
+	v.batch = nil
+	v.mu.Unlock()
+
+	v.execute(batch)
+}
+
+// execute will verify the merkle roots of the batch with the upstream verifier and cache the results
+//
+// If the upstream call fails for multiple merkle roots, the batch is bisected to find which ones failed
+func (v *CachedVerifier) execute(batch *merkleRootBatch) {
+	items := make([]*MerkleRootConfirmationRequestItem, 0, len(batch.calls))
+	for _, call := range batch.calls {
+		items = append(items, call.item)
+	}
+
+	ctx, cancel := context.WithTimeout(batch.ctx, v.batchTimeout)
+	errs := bisectMerkleRoots(ctx, v.upstream, items, v.upstream.VerifyMerkleRoots(ctx, items))
+	cancel()
+	for i, call := range batch.calls {
+		call.err = errs[i]
+	}
+
+	v.mu.Lock()
+	for _, call := range batch.calls {
+		if call.err == nil {
+			v.bestHeight = max(v.bestHeight, call.item.BlockHeight)
+		}
+	}
+	for _, call := range batch.calls {
+		delete(v.inFlight, call.key)
+		v.store(call)
+	}
+	v.mu.Unlock()
+
+	for _, call := range batch.calls {
+		close(call.done)
+	}
+}
+
+// store will cache the result of the call (the lock must be held, and the best height updated with the batch)
+func (v *CachedVerifier) store(call *merkleRootCall) {
+	cached := &cachedMerkleRoot{err: call.err}
+	if call.err != nil {
+		if v.negativeTTL <= 0 {
+			return
+		}
+		cached.expires = time.Now().Add(v.negativeTTL)
+	} else if call.item.BlockHeight+v.confirmationDepth > v.bestHeight {
+		if v.recentTTL <= 0 {
+			return
+		}
+		cached.expires = time.Now().Add(v.recentTTL)
+	}
+
+	if len(v.cache) >= v.cacheSize {
+		v.evict()
+	}
+	v.cache[call.key] = cached
+}
+
+// evict will remove the expired merkle roots, or one merkle root if none expired (the lock must be held)
+func (v *CachedVerifier) evict() {
+	now := time.Now()
+	for key, cached := range v.cache {
+		if !cached.expires.IsZero() && now.After(cached.expires) {
+			delete(v.cache, key)
+		}
+	}
+	for key := range v.cache {
+		if len(v.cache) < v.cacheSize {
+			return
+		}
+		delete(v.cache, key)
+	}
+}
+
+// merkleRootKey will return the cache key of the merkle root
+func merkleRootKey(item *MerkleRootConfirmationRequestItem) string {
+	return strconv.FormatUint(item.BlockHeight, 10) + ":" + item.MerkleRoot
+}
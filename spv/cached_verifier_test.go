@@ -0,0 +1,225 @@
+package spv
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errTestUpstream is returned by the test upstream verifier for the rejected merkle roots
+var errTestUpstream = stderrors.New("merkle root not in the longest chain")
+
+// countingVerifier is an upstream verifier recording its calls
+type countingVerifier struct {
+	calls    [][]*MerkleRootConfirmationRequestItem
+	mu       sync.Mutex
+	rejected map[string]bool
+	release  chan struct{} // If set, the calls wait for it to be closed
+}
+
+// VerifyMerkleRoots will record the call and fail if a merkle root is rejected
+func (c *countingVerifier) VerifyMerkleRoots(_ context.Context, merkleRoots []*MerkleRootConfirmationRequestItem) error {
+	if c.release != nil {
+		<-c.release
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, merkleRoots)
+	for _, item := range merkleRoots {
+		if c.rejected[item.MerkleRoot] {
+			return errTestUpstream
+		}
+	}
+	return nil
+}
+
+// callCount will return the number of upstream calls
+func (c *countingVerifier) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+// hangingVerifier is an upstream verifier answering when its context is done
+type hangingVerifier struct{}
+
+// VerifyMerkleRoots will wait for the context
+func (h *hangingVerifier) VerifyMerkleRoots(ctx context.Context, _ []*MerkleRootConfirmationRequestItem) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// testMerkleRootItem will return a request item at the height
+func testMerkleRootItem(height uint64) *MerkleRootConfirmationRequestItem {
+	return &MerkleRootConfirmationRequestItem{BlockHeight: height, MerkleRoot: "root-" + strconv.FormatUint(height, 10)}
+}
+
+func TestCachedVerifier_VerifyMerkleRoots(t *testing.T) {
+	t.Parallel()
+
+	t.Run("confirmed merkle roots are cached", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(0), WithRecentTTL(0))
+		items := []*MerkleRootConfirmationRequestItem{testMerkleRootItem(100), testMerkleRootItem(106)}
+
+		// when
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items))
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items[:1]))
+
+		// then the deep merkle root is cached, the recent one is not
+		assert.Equal(t, 1, upstream.callCount())
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items[1:]))
+		assert.Equal(t, 2, upstream.callCount())
+	})
+
+	t.Run("recent merkle roots expire", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(0), WithRecentTTL(20*time.Millisecond))
+		items := []*MerkleRootConfirmationRequestItem{testMerkleRootItem(100)}
+
+		// when
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items))
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items))
+
+		// then
+		assert.Equal(t, 1, upstream.callCount())
+		time.Sleep(30 * time.Millisecond)
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items))
+		assert.Equal(t, 2, upstream.callCount())
+	})
+
+	t.Run("failed merkle roots are cached for the negative TTL", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{rejected: map[string]bool{"root-100": true}}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(0), WithNegativeTTL(time.Hour))
+		items := []*MerkleRootConfirmationRequestItem{testMerkleRootItem(100)}
+
+		// when
+		err := verifier.VerifyMerkleRoots(context.Background(), items)
+		require.ErrorIs(t, err, errTestUpstream)
+		err = verifier.VerifyMerkleRoots(context.Background(), items)
+
+		// then
+		require.ErrorIs(t, err, errTestUpstream)
+		assert.Equal(t, 1, upstream.callCount())
+	})
+
+	t.Run("concurrent requests are batched", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(100*time.Millisecond))
+
+		// when
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(height uint64) {
+				defer wg.Done()
+				// half of the requests are for the same merkle root
+				assert.NoError(t, verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{
+					testMerkleRootItem(height % 10),
+				}))
+			}(uint64(i))
+		}
+		wg.Wait()
+
+		// then
+		require.Equal(t, 1, upstream.callCount())
+		assert.Len(t, upstream.calls[0], 10)
+	})
+
+	t.Run("full batches are sent without waiting", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(time.Hour), WithMaxBatchSize(2))
+
+		// when
+		err := verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{
+			testMerkleRootItem(1), testMerkleRootItem(2), testMerkleRootItem(3), testMerkleRootItem(4),
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, upstream.callCount())
+	})
+
+	t.Run("failed batches are bisected", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{rejected: map[string]bool{"root-3": true}}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(0))
+		items := make([]*MerkleRootConfirmationRequestItem, 0, 64)
+		for height := uint64(1); height <= 64; height++ {
+			items = append(items, testMerkleRootItem(height))
+		}
+
+		// when
+		err := verifier.VerifyMerkleRoots(context.Background(), items)
+
+		// then the failed merkle root is found with two calls per half (instead of one call per merkle root)
+		require.ErrorIs(t, err, errTestUpstream)
+		assert.Equal(t, 1+2*6, upstream.callCount())
+
+		// and the results of the other merkle roots are cached
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), append(items[:2:2], items[3:]...)))
+		require.ErrorIs(t, verifier.VerifyMerkleRoots(context.Background(), items[2:3]), errTestUpstream)
+		assert.Equal(t, 1+2*6, upstream.callCount())
+	})
+
+	t.Run("hung upstream calls time out", func(t *testing.T) {
+		// given
+		verifier := NewCachedVerifier(new(hangingVerifier), WithBatchWindow(0), WithBatchTimeout(20*time.Millisecond))
+
+		// when
+		err := verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{
+			testMerkleRootItem(1), testMerkleRootItem(2),
+		})
+
+		// then the batch is not bisected, and the merkle roots are not in flight
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		verifier.mu.Lock()
+		defer verifier.mu.Unlock()
+		assert.Empty(t, verifier.inFlight)
+	})
+
+	t.Run("canceled request", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{release: make(chan struct{})}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(0))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		err := verifier.VerifyMerkleRoots(ctx, []*MerkleRootConfirmationRequestItem{testMerkleRootItem(1)})
+
+		// then the upstream call is not canceled (it is shared)
+		require.ErrorIs(t, err, context.Canceled)
+		close(upstream.release)
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{testMerkleRootItem(1)}))
+		assert.Equal(t, 1, upstream.callCount())
+	})
+
+	t.Run("cache size", func(t *testing.T) {
+		// given
+		upstream := &countingVerifier{}
+		verifier := NewCachedVerifier(upstream, WithBatchWindow(0), WithCacheSize(2), WithConfirmationDepth(0))
+
+		// when
+		for height := uint64(1); height <= 5; height++ {
+			require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{testMerkleRootItem(height)}))
+		}
+
+		// then
+		verifier.mu.Lock()
+		defer verifier.mu.Unlock()
+		assert.Len(t, verifier.cache, 2)
+	})
+}
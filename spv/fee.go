@@ -0,0 +1,176 @@
+package spv
+
+import (
+	"context"
+
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// Default fee rates of the fee policy (satoshis per 1000 bytes)
+const (
+	DefaultDataFeeRate     = 1
+	DefaultStandardFeeRate = 1
+
+	// RuleNameFeeRate is the name of the fee rate rule (see WithFeePolicy)
+	RuleNameFeeRate = "fee-rate"
+
+	bytesPerKB = 1000
+)
+
+// FeePolicy is the minimum fee rate of unmined transactions, in satoshis per 1000 bytes (sat/KB)
+//
+// Data bytes are the locking scripts of data outputs (OP_RETURN or OP_FALSE OP_RETURN),
+// standard bytes are the other bytes of the transaction. Zero rates accept any fee
+type FeePolicy struct {
+	DataRate     uint64 `json:"data_rate"`
+	StandardRate uint64 `json:"standard_rate"`
+}
+
+// DefaultFeePolicy will return the default fee policy (1 sat/KB for standard and data bytes)
+func DefaultFeePolicy() FeePolicy {
+	return FeePolicy{
+		DataRate:     DefaultDataFeeRate,
+		StandardRate: DefaultStandardFeeRate,
+	}
+}
+
+// MinimumFee will return the minimum fee of the transaction (rounded up to the next satoshi)
+func (p FeePolicy) MinimumFee(tx *sdk.Transaction) uint64 {
+	return (p.feeUnits(tx) + bytesPerKB - 1) / bytesPerKB
+}
+
+// feeUnits will return the fee of the transaction in satoshis per 1000 (summed for packages before rounding)
+func (p FeePolicy) feeUnits(tx *sdk.Transaction) uint64 {
+	size, dataBytes := uint64(len(tx.Bytes())), uint64(0)
+	for _, output := range tx.Outputs {
+		if output.LockingScript != nil && output.LockingScript.IsData() {
+			dataBytes += uint64(len(*output.LockingScript))
+		}
+	}
+	return (size-dataBytes)*p.StandardRate + dataBytes*p.DataRate
+}
+
+// WithFeePolicy will add the fee rate rule to the verifier
+func WithFeePolicy(policy FeePolicy) VerifierOps {
+	return WithRule(NewFeeRule(policy))
+}
+
+// NewFeeRule will return the rule checking the fee rate of the unmined transactions against the policy
+//
+// A transaction is accepted if the package of the transaction or of a descendant (with their unmined
+// ancestors) pays the fee of the package, and the transaction or descendant pays its own fee: a child
+// can pay for its parents (CPFP), but a parent cannot pay for its children. A transaction is rejected if
+// its unmined ancestors are not paid. Transactions spending known (TXID-only) transactions are not
+// checked, as their fee is unknown
+func NewFeeRule(policy FeePolicy) Rule {
+	return NewRule(RuleNameFeeRate, func(_ context.Context, rc *RuleContext) error {
+		return verifyFeeRate(rc, policy)
+	})
+}
+
+// feeEntry is an unmined transaction with a known fee
+type feeEntry struct {
+	fee      uint64
+	feeUnits uint64 // The minimum fee in satoshis per 1000 (see FeePolicy.feeUnits)
+	paid     bool   // The fee of a package of the transaction is paid
+	parents  []*feeEntry
+}
+
+// verifyFeeRate will check the fee of the unmined transactions (and of their packages)
+func verifyFeeRate(rc *RuleContext, policy FeePolicy) error {
+	entries := make(map[string]*feeEntry)
+	ordered := make([]*feeEntry, 0, len(rc.BEEF.Transactions))
+	for i, txDt := range rc.BEEF.Transactions {
+		result := rc.Report.Transactions[i]
+		if entry := newFeeEntry(txDt, result, policy, entries); entry != nil {
+			entries[result.TxID] = entry
+			ordered = append(ordered, entry)
+		}
+	}
+
+	// the package of a transaction is the transaction and its unmined ancestors (the fee of the
+	// transaction is not paid by its ancestors)
+	for _, entry := range ordered {
+		if !entry.paysOwnFee() {
+			continue
+		}
+		pkg := entry.ancestors()
+		fee, feeUnits := uint64(0), uint64(0)
+		for _, e := range pkg {
+			fee += e.fee
+			feeUnits += e.feeUnits
+		}
+		if fee*bytesPerKB >= feeUnits {
+			for _, e := range pkg {
+				e.paid = true
+			}
+		}
+	}
+
+	return rc.EachTransaction(func(_ *beef.TxData, result *TransactionResult) error {
+		entry, ok := entries[result.TxID]
+		if !ok {
+			return nil
+		}
+
+		result.FeeRateValid = entry.paysOwnFee()
+		result.FeePaidByPackage = !result.FeeRateValid && entry.paid
+		if !entry.paid {
+			return errors.ErrFeeTooLow
+		}
+		return nil
+	})
+}
+
+// newFeeEntry will return the fee entry of an unmined transaction (nil if the fee is unknown)
+func newFeeEntry(txDt *beef.TxData, result *TransactionResult, policy FeePolicy, entries map[string]*feeEntry) *feeEntry {
+	if txDt.TxIDOnly || result.Mined {
+		return nil
+	}
+
+	entry := &feeEntry{feeUnits: policy.feeUnits(txDt.Transaction)}
+	inputSatoshis, outputSatoshis := uint64(0), uint64(0)
+	for _, input := range result.Inputs {
+		if !input.ParentFound || input.ParentTxIDOnly {
+			return nil
+		}
+		inputSatoshis += input.Satoshis
+
+		// the transactions are in topological order (the unmined parents are already in the entries)
+		if parent, ok := entries[input.SourceTxID]; ok {
+			entry.parents = append(entry.parents, parent)
+		}
+	}
+	for _, output := range txDt.Transaction.Outputs {
+		outputSatoshis += output.Satoshis
+	}
+	if inputSatoshis > outputSatoshis {
+		entry.fee = inputSatoshis - outputSatoshis
+	}
+
+	result.Fee, result.MinimumFee = entry.fee, policy.MinimumFee(txDt.Transaction)
+	return entry
+}
+
+// paysOwnFee will return true if the fee of the transaction meets the policy (without its package)
+func (e *feeEntry) paysOwnFee() bool {
+	return e.fee*bytesPerKB >= e.feeUnits
+}
+
+// ancestors will return the entry and its unmined ancestors (each one once)
+func (e *feeEntry) ancestors() []*feeEntry {
+	visited := map[*feeEntry]bool{e: true}
+	pkg := []*feeEntry{e}
+	for i := 0; i < len(pkg); i++ {
+		for _, parent := range pkg[i].parents {
+			if !visited[parent] {
+				visited[parent] = true
+				pkg = append(pkg, parent)
+			}
+		}
+	}
+	return pkg
+}
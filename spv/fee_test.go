@@ -0,0 +1,166 @@
+package spv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/script"
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/util"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// testFeePolicy is one satoshi per byte (the minimum fee is the size of the transaction)
+var testFeePolicy = FeePolicy{DataRate: 1000, StandardRate: 1000}
+
+// newTestFeeTx will return a transaction spending the first output of the parent with the fee
+func newTestFeeTx(parent *sdk.Transaction, fee uint64) *sdk.Transaction {
+	unlockingScript := script.Script(make([]byte, 107))
+	lockingScript := script.Script(make([]byte, 25))
+
+	tx := sdk.NewTransaction()
+	tx.AddInput(&sdk.TransactionInput{
+		SourceTXID:       parent.TxID(),
+		SourceTxOutIndex: 0,
+		UnlockingScript:  &unlockingScript,
+		SequenceNumber:   0xffffffff,
+	})
+	tx.AddOutput(&sdk.TransactionOutput{Satoshis: parent.Outputs[0].Satoshis - fee, LockingScript: &lockingScript})
+	return tx
+}
+
+// newTestFeeBEEF will return a BEEF with a mined transaction, its unmined child and grandchild
+func newTestFeeBEEF(parentFee, childFee uint64) *beef.DecodedBEEF {
+	lockingScript := script.Script(make([]byte, 25))
+	mined := sdk.NewTransaction()
+	mined.AddOutput(&sdk.TransactionOutput{Satoshis: 100000, LockingScript: &lockingScript})
+	parent := newTestFeeTx(mined, parentFee)
+	child := newTestFeeTx(parent, childFee)
+
+	bumpIndex := util.VarInt(0)
+	return &beef.DecodedBEEF{Transactions: []*beef.TxData{
+		{Transaction: mined, BumpIndex: &bumpIndex},
+		{Transaction: parent},
+		{Transaction: child},
+	}}
+}
+
+func TestFeePolicy_MinimumFee(t *testing.T) {
+	t.Parallel()
+
+	dataScript := script.Script(append([]byte{script.OpFALSE, script.OpRETURN}, make([]byte, 998)...))
+	tx := newTestFeeTx(newTestFeeBEEF(0, 0).Transactions[0].Transaction, 0)
+	size := uint64(len(tx.Bytes()))
+
+	t.Run("standard bytes", func(t *testing.T) {
+		require.Equal(t, size, testFeePolicy.MinimumFee(tx))
+		require.Equal(t, uint64(1), FeePolicy{StandardRate: 1}.MinimumFee(tx))
+		require.Equal(t, uint64(0), FeePolicy{}.MinimumFee(tx))
+	})
+
+	t.Run("data bytes", func(t *testing.T) {
+		dataTx := tx.ShallowClone()
+		dataTx.AddOutput(&sdk.TransactionOutput{LockingScript: &dataScript})
+		dataSize := uint64(len(dataTx.Bytes()))
+
+		require.Equal(t, dataSize, testFeePolicy.MinimumFee(dataTx))
+		require.Equal(t, dataSize-1000, FeePolicy{StandardRate: 1000}.MinimumFee(dataTx))
+		require.Equal(t, uint64(1), FeePolicy{DataRate: 1}.MinimumFee(dataTx))
+	})
+}
+
+func TestVerifier_Verify_FeePolicy(t *testing.T) {
+	t.Parallel()
+
+	sizes := newTestFeeBEEF(0, 0)
+	parentSize := uint64(len(sizes.Transactions[1].Transaction.Bytes()))
+	childSize := uint64(len(sizes.Transactions[2].Transaction.Bytes()))
+	verify := func(dBeef *beef.DecodedBEEF) *VerificationReport {
+		verifier := NewVerifier(new(mockServiceProvider), WithRules(NewFeeRule(testFeePolicy)))
+		return verifier.Verify(context.Background(), dBeef)
+	}
+
+	t.Run("fees above the policy", func(t *testing.T) {
+		// when
+		report := verify(newTestFeeBEEF(parentSize, childSize))
+
+		// then
+		require.True(t, report.Valid)
+		require.False(t, report.Transactions[0].FeeRateValid)
+		for _, result := range report.Transactions[1:] {
+			require.True(t, result.FeeRateValid)
+			require.False(t, result.FeePaidByPackage)
+			require.Equal(t, result.MinimumFee, result.Fee)
+		}
+	})
+
+	t.Run("child pays for parent", func(t *testing.T) {
+		// when
+		report := verify(newTestFeeBEEF(0, parentSize+childSize))
+
+		// then
+		require.True(t, report.Valid)
+		parent, child := report.Transactions[1], report.Transactions[2]
+		require.Zero(t, parent.Fee)
+		require.False(t, parent.FeeRateValid)
+		require.True(t, parent.FeePaidByPackage)
+		require.True(t, parent.Valid)
+		require.True(t, child.FeeRateValid)
+	})
+
+	t.Run("child does not pay for parent", func(t *testing.T) {
+		// when
+		report := verify(newTestFeeBEEF(0, parentSize+childSize-1))
+
+		// then
+		require.Equal(t, errors.ErrFeeTooLow, report.Err())
+		parent, child := report.Transactions[1], report.Transactions[2]
+		require.False(t, parent.Valid)
+		require.False(t, parent.FeePaidByPackage)
+
+		// the child pays its own fee, but it cannot be mined without its parent
+		require.True(t, child.FeeRateValid)
+		require.False(t, child.Valid)
+		require.Equal(t, errors.ErrFeeTooLow.Error(), child.Error)
+	})
+
+	t.Run("parent pays, child below the policy", func(t *testing.T) {
+		// when
+		report := verify(newTestFeeBEEF(parentSize, childSize-1))
+
+		// then
+		require.Equal(t, errors.ErrFeeTooLow, report.Err())
+		require.True(t, report.Transactions[1].Valid)
+		require.False(t, report.Transactions[2].Valid)
+	})
+
+	t.Run("parent pays for child", func(t *testing.T) {
+		// when
+		report := verify(newTestFeeBEEF(parentSize+childSize, 0))
+
+		// then
+		require.Equal(t, errors.ErrFeeTooLow, report.Err())
+		parent, child := report.Transactions[1], report.Transactions[2]
+		require.True(t, parent.Valid)
+		require.True(t, parent.FeeRateValid)
+		require.False(t, child.Valid)
+		require.False(t, child.FeeRateValid)
+		require.False(t, child.FeePaidByPackage)
+	})
+
+	t.Run("known parent", func(t *testing.T) {
+		// given
+		dBeef := newTestFeeBEEF(0, 0)
+		dBeef.Transactions[1] = beef.NewTxIDOnly(dBeef.Transactions[1].GetTxID())
+
+		// when
+		report := verify(dBeef)
+
+		// then
+		require.True(t, report.Valid)
+		require.Zero(t, report.Transactions[2].MinimumFee)
+	})
+}
@@ -0,0 +1,208 @@
+package headers
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Parameters of the difficulty adjustments of the main network
+const (
+	mainnetDAAHeight  = 504031     // The last header before the CW-144 difficulty adjustment (November 2017)
+	mainnetEDAHeight  = 478558     // The last header before the emergency difficulty adjustment (August 2017)
+	mainnetPowLimit   = 0x1d00ffff // The compact target of the minimum difficulty of the main network
+	regtestPowLimit   = 0x207fffff // The compact target of the minimum difficulty of regtest
+	retargetInterval  = 2016       // The headers between the legacy difficulty adjustments
+	targetSpacing     = 600        // The expected time between two headers (seconds)
+	targetTimespan    = retargetInterval * targetSpacing
+	daaWindow         = 144       // The headers of the window of the CW-144 difficulty adjustment
+	edaHeaders        = 6         // The headers of the emergency difficulty adjustment
+	edaTimespan       = 12 * 3600 // The time of the EDA headers above which the difficulty is reduced
+	medianTimeHeaders = 11        // The headers of the median time past
+)
+
+// ChainParams are the proof of work parameters of a network (see WithChainParams)
+type ChainParams struct {
+	DAAHeight     uint64 // The height of the last header before the CW-144 difficulty adjustment
+	EDAHeight     uint64 // The height of the last header before the emergency difficulty adjustment
+	NoRetargeting bool   // The difficulty bits never change (regtest)
+	PowLimitBits  uint32 // The compact target of the minimum difficulty
+}
+
+// MainnetParams will return the parameters of the main network (legacy, EDA and CW-144 difficulty adjustments)
+func MainnetParams() ChainParams {
+	return ChainParams{
+		DAAHeight:    mainnetDAAHeight,
+		EDAHeight:    mainnetEDAHeight,
+		PowLimitBits: mainnetPowLimit,
+	}
+}
+
+// RegtestParams will return the parameters of regtest (the difficulty bits of the checkpoint never change)
+func RegtestParams() ChainParams {
+	return ChainParams{
+		NoRetargeting: true,
+		PowLimitBits:  regtestPowLimit,
+	}
+}
+
+// validateBits will check the difficulty bits of the header on the parent (the store must be locked)
+func (p ChainParams) validateBits(parent *node, bits uint32) error {
+	if compactToBig(bits).Cmp(compactToBig(p.PowLimitBits)) > 0 {
+		return fmt.Errorf("bits %08x above the limit %08x: %w", bits, p.PowLimitBits, ErrHeaderInvalidBits)
+	}
+	if expected, ok := p.nextBits(parent); ok && bits != expected {
+		return fmt.Errorf("bits %08x, expected %08x: %w", bits, expected, ErrHeaderInvalidDifficulty)
+	}
+	return nil
+}
+
+// nextBits will return the difficulty bits of the header on the parent (false if the ancestors are not in the store)
+func (p ChainParams) nextBits(parent *node) (uint32, bool) {
+	switch {
+	case p.NoRetargeting:
+		return parent.header.Bits, true
+	case parent.height >= p.DAAHeight:
+		return p.cashBits(parent)
+	default:
+		return p.legacyBits(parent)
+	}
+}
+
+// legacyBits will return the bits of the legacy difficulty adjustment (every 2016 headers)
+// with the emergency difficulty adjustment (EDA, after its activation height)
+func (p ChainParams) legacyBits(parent *node) (uint32, bool) {
+	height := parent.height + 1
+	if height%retargetInterval == 0 {
+		first := parent.ancestor(height - retargetInterval)
+		if first == nil {
+			return 0, false
+		}
+		timespan := int64(parent.header.Timestamp) - int64(first.header.Timestamp)
+		timespan = min(max(timespan, targetTimespan/4), targetTimespan*4)
+
+		target := compactToBig(parent.header.Bits)
+		target.Mul(target, big.NewInt(timespan))
+		return p.limitBits(target.Div(target, big.NewInt(targetTimespan))), true
+	}
+
+	if height <= p.EDAHeight || parent.header.Bits == p.PowLimitBits {
+		return parent.header.Bits, true
+	}
+
+	// the target is increased by a quarter if the last 6 headers took more than 12 hours
+	first := parent.ancestor(height - edaHeaders - 1)
+	if first == nil {
+		return 0, false
+	}
+	last, lastOk := parent.medianTimePast()
+	previous, previousOk := first.medianTimePast()
+	if !lastOk || !previousOk {
+		return 0, false
+	} else if last-previous < edaTimespan {
+		return parent.header.Bits, true
+	}
+
+	target := compactToBig(parent.header.Bits)
+	return p.limitBits(target.Add(target, new(big.Int).Rsh(target, 2))), true
+}
+
+// cashBits will return the bits of the CW-144 difficulty adjustment (the work and the time of the last 144 headers)
+func (p ChainParams) cashBits(parent *node) (uint32, bool) {
+	last := parent.suitable()
+	first := parent.ancestor(parent.height - daaWindow).suitable()
+	if last == nil || first == nil {
+		return 0, false
+	}
+
+	timespan := int64(last.header.Timestamp) - int64(first.header.Timestamp)
+	timespan = min(max(timespan, daaWindow/2*targetSpacing), 2*daaWindow*targetSpacing)
+
+	work := new(big.Int).Sub(last.work, first.work)
+	work.Mul(work, big.NewInt(targetSpacing))
+	work.Div(work, big.NewInt(timespan))
+	if work.Sign() <= 0 {
+		return 0, false
+	}
+
+	// the target is 2^256 / work - 1
+	target := new(big.Int).Lsh(big.NewInt(1), 256)
+	target.Sub(target, work)
+	return p.limitBits(target.Div(target, work)), true
+}
+
+// limitBits will return the compact target (the proof of work limit if the target is above)
+func (p ChainParams) limitBits(target *big.Int) uint32 {
+	if target.Cmp(compactToBig(p.PowLimitBits)) > 0 {
+		return p.PowLimitBits
+	}
+	return bigToCompact(target)
+}
+
+// ancestor will return the ancestor of the node at the height (nil if it is not in the store)
+func (n *node) ancestor(height uint64) *node {
+	if n == nil || height > n.height {
+		return nil
+	}
+	for n != nil && n.height > height {
+		n = n.parent
+	}
+	return n
+}
+
+// suitable will return the header with the median time of the node and its two parents (nil if not in the store)
+func (n *node) suitable() *node {
+	if n == nil || n.parent == nil || n.parent.parent == nil {
+		return nil
+	}
+
+	nodes := []*node{n.parent.parent, n.parent, n}
+	if nodes[0].header.Timestamp > nodes[2].header.Timestamp {
+		nodes[0], nodes[2] = nodes[2], nodes[0]
+	}
+	if nodes[0].header.Timestamp > nodes[1].header.Timestamp {
+		nodes[0], nodes[1] = nodes[1], nodes[0]
+	}
+	if nodes[1].header.Timestamp > nodes[2].header.Timestamp {
+		nodes[1], nodes[2] = nodes[2], nodes[1]
+	}
+	return nodes[1]
+}
+
+// medianTimePast will return the median time of the node and its 10 parents (false if they are not in the store)
+//
+// The genesis has fewer parents: the median is the one of the headers from the genesis
+func (n *node) medianTimePast() (int64, bool) {
+	timestamps := make([]int64, 0, medianTimeHeaders)
+	for ; n != nil && len(timestamps) < medianTimeHeaders; n = n.parent {
+		timestamps = append(timestamps, int64(n.header.Timestamp))
+		if len(timestamps) < medianTimeHeaders && n.parent == nil && n.height > 0 {
+			return 0, false
+		}
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	return timestamps[len(timestamps)/2], true
+}
+
+// bigToCompact will return the compact difficulty bits of the (positive) target
+func bigToCompact(target *big.Int) uint32 {
+	if target.Sign() <= 0 {
+		return 0
+	}
+
+	exponent := uint(len(target.Bytes()))
+	var mantissa uint32
+	if exponent <= 3 {
+		mantissa = uint32(target.Uint64()) << (8 * (3 - exponent)) //nolint:gosec // G115: at most 3 bytes
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(target, 8*(exponent-3)).Uint64()) //nolint:gosec // G115: 3 bytes
+	}
+
+	// the mantissa is positive (the sign bit moves to the exponent)
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+	return uint32(exponent<<24) | mantissa //nolint:gosec // G115: the exponent of a 256-bit target is one byte
+}
@@ -0,0 +1,164 @@
+package headers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHistory will return a chain of (unmined) headers with the bits, the timestamps are spaced by the spacing
+func newTestHistory(length int, bits, spacing uint32) []*block.Header {
+	headers := make([]*block.Header, 0, length)
+	prev := &block.Header{Version: 1, Bits: bits, Timestamp: 1500000000}
+	for range length {
+		prev = &block.Header{Version: 1, PrevHash: prev.Hash(), Bits: bits, Timestamp: prev.Timestamp + spacing}
+		headers = append(headers, prev)
+	}
+	return headers
+}
+
+// newTestHistoryStore will return a main network store at the last header of the history (at the height)
+func newTestHistoryStore(history []*block.Header, height uint64) *Store {
+	last := len(history) - 1
+	return NewStore(history[last], height, WithCheckpointHistory(history[:last]...))
+}
+
+func TestChainParams_nextBits(t *testing.T) {
+	t.Parallel()
+
+	t.Run("same bits between the legacy adjustments", func(t *testing.T) {
+		// given
+		store := newTestHistoryStore(newTestHistory(20, 0x1c3fffc0, 600), 100)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then
+		require.True(t, ok)
+		assert.Equal(t, uint32(0x1c3fffc0), bits)
+	})
+
+	t.Run("emergency difficulty adjustment", func(t *testing.T) {
+		// given the last 6 headers took more than 12 hours
+		store := newTestHistoryStore(newTestHistory(20, 0x1c3fffc0, 3*3600), mainnetEDAHeight+100)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then the target is increased by a quarter
+		require.True(t, ok)
+		assert.Equal(t, uint32(0x1c4fffb0), bits)
+	})
+
+	t.Run("slow headers before the emergency difficulty adjustment", func(t *testing.T) {
+		// given the last 6 headers took more than 12 hours (before the activation of the EDA)
+		store := newTestHistoryStore(newTestHistory(20, 0x1c3fffc0, 3*3600), mainnetEDAHeight-1)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then
+		require.True(t, ok)
+		assert.Equal(t, uint32(0x1c3fffc0), bits)
+	})
+
+	t.Run("legacy adjustment of fast headers", func(t *testing.T) {
+		// given
+		store := newTestHistoryStore(newTestHistory(retargetInterval, mainnetPowLimit, 1), retargetInterval-1)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then the target is divided by 4 (at most)
+		require.True(t, ok)
+		assert.Equal(t, uint32(0x1c3fffc0), bits)
+	})
+
+	t.Run("legacy adjustment of slow headers", func(t *testing.T) {
+		// given
+		store := newTestHistoryStore(newTestHistory(retargetInterval, 0x1c3fffc0, 10000), 2*retargetInterval-1)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then the target is multiplied by 4 (at most, up to the limit)
+		require.True(t, ok)
+		assert.Equal(t, uint32(mainnetPowLimit), bits)
+	})
+
+	t.Run("CW-144 adjustment of headers on time", func(t *testing.T) {
+		// given
+		store := newTestHistoryStore(newTestHistory(150, 0x1803a30c, 600), 600000)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then
+		require.True(t, ok)
+		assert.Equal(t, uint32(0x1803a30c), bits)
+	})
+
+	t.Run("CW-144 adjustment of slow headers", func(t *testing.T) {
+		// given
+		store := newTestHistoryStore(newTestHistory(150, 0x1803a30c, 6000), 600000)
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then the target is multiplied by 2 (at most)
+		require.True(t, ok)
+		ratio, _ := new(big.Rat).SetFrac(compactToBig(bits), compactToBig(0x1803a30c)).Float64()
+		assert.InDelta(t, 2, ratio, 0.001)
+	})
+
+	t.Run("adjustment window before the checkpoint", func(t *testing.T) {
+		// given
+		history := newTestHistory(150, 0x1803a30c, 600)
+		store := NewStore(history[len(history)-1], 600000)
+
+		// when
+		_, ok := store.params.nextBits(store.tip())
+
+		// then
+		assert.False(t, ok)
+	})
+
+	t.Run("history not linked to the checkpoint", func(t *testing.T) {
+		// given
+		history := newTestHistory(150, 0x1803a30c, 600)
+		store := NewStore(history[len(history)-1], 600000, WithCheckpointHistory(history[:len(history)-2]...))
+
+		// when
+		_, ok := store.params.nextBits(store.tip())
+
+		// then
+		assert.False(t, ok)
+		assert.Nil(t, store.tip().parent)
+	})
+
+	t.Run("regtest", func(t *testing.T) {
+		// given
+		checkpoint := &block.Header{Version: 1, Bits: testBits}
+		store := NewStore(checkpoint, 1000, WithChainParams(RegtestParams()))
+
+		// when
+		bits, ok := store.params.nextBits(store.tip())
+
+		// then
+		require.True(t, ok)
+		assert.Equal(t, uint32(testBits), bits)
+	})
+}
+
+func TestBigToCompact(t *testing.T) {
+	t.Parallel()
+
+	for _, bits := range []uint32{mainnetPowLimit, regtestPowLimit, 0x1c3fffc0, 0x1803a30c, 0x03123456, 0x02008000} {
+		assert.Equal(t, bits, bigToCompact(compactToBig(bits)), "%08x", bits)
+	}
+	assert.Equal(t, uint32(0x02008000), bigToCompact(big.NewInt(0x80)))
+	assert.Zero(t, bigToCompact(big.NewInt(0)))
+}
@@ -0,0 +1,349 @@
+// Package headers is an offline block header store (implements spv.MerkleRootVerifier)
+//
+// Raw 80-byte headers are imported from a file or a stream, the proof of work and the linkage
+// of the headers are validated, and the longest chain (most work) is tracked through reorgs.
+// The difficulty bits of a header cannot be above the proof of work limit of the network, and
+// must be the bits of the difficulty adjustment of the network (see ChainParams)
+package headers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+
+	"github.com/bsv-blockchain/go-paymail/spv"
+)
+
+// MainnetGenesisHeader is the raw header of the genesis block of the main network
+const MainnetGenesisHeader = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+
+var (
+	// ErrHeaderInvalidBits is returned when the difficulty bits of a header are not a valid target (or above the limit)
+	ErrHeaderInvalidBits = errors.New("invalid block header - invalid difficulty bits")
+	// ErrHeaderInvalidDifficulty is returned when the difficulty bits of a header are not the ones of the difficulty adjustment
+	ErrHeaderInvalidDifficulty = errors.New("invalid block header - unexpected difficulty bits")
+	// ErrHeaderInvalidProofOfWork is returned when the hash of a header is above the target of its bits
+	ErrHeaderInvalidProofOfWork = errors.New("invalid block header - hash above the target")
+	// ErrHeaderNotConnected is returned when the previous header of a header is not in the store
+	ErrHeaderNotConnected = errors.New("invalid block header - previous header not found")
+	// ErrHeaderTruncated is returned when a header stream ends in the middle of a header
+	ErrHeaderTruncated = errors.New("invalid header stream - truncated header")
+	// ErrHeightNotFound is returned when the height is not in the longest chain
+	ErrHeightNotFound = errors.New("height not found in the longest chain")
+	// ErrMerkleRootNotFound is returned when the merkle root is not the one of the longest chain at the height
+	ErrMerkleRootNotFound = errors.New("merkle root not found in the longest chain")
+)
+
+// Reorg is a change of the longest chain (the headers above the fork are replaced)
+type Reorg struct {
+	Depth      uint64         // The number of headers removed from the longest chain
+	ForkHeight uint64         // The height of the common ancestor
+	NewTip     chainhash.Hash // The hash of the new tip
+	OldTip     chainhash.Hash // The hash of the previous tip
+}
+
+// StoreOps allow functional options to be supplied
+// that overwrite default store options.
+type StoreOps func(s *Store)
+
+// WithoutProofOfWork will disable the validation of the proof of work and of the difficulty bits (e.g. for tests)
+func WithoutProofOfWork() StoreOps {
+	return func(s *Store) {
+		s.proofOfWork = false
+	}
+}
+
+// WithChainParams will set the proof of work parameters of the network (default is MainnetParams)
+func WithChainParams(params ChainParams) StoreOps {
+	return func(s *Store) {
+		s.params = params
+	}
+}
+
+// WithCheckpointHistory will set the headers preceding the checkpoint (trusted, oldest first)
+//
+// The headers are only used for the difficulty adjustment of the first headers after the checkpoint
+// (the last 2016 headers for the main network). The headers not linked to the checkpoint are ignored
+func WithCheckpointHistory(headers ...*block.Header) StoreOps {
+	return func(s *Store) {
+		child := s.chain[0]
+		for i := len(headers) - 1; i >= 0 && headers[i].Hash() == child.header.PrevHash && child.height > 0; i-- {
+			child.parent = &node{
+				hash:   child.header.PrevHash,
+				header: headers[i],
+				height: child.height - 1,
+				work:   new(big.Int).Sub(child.work, headerWork(child.header.Bits)),
+			}
+			child = child.parent
+		}
+	}
+}
+
+// WithReorgHandler will set a handler called for every reorg of the longest chain
+//
+// The handler is called while the store is locked (it must not call the store)
+func WithReorgHandler(handler func(reorg Reorg)) StoreOps {
+	return func(s *Store) {
+		s.reorgHandler = handler
+	}
+}
+
+// node is a header of the store (in the longest chain or in a side chain)
+type node struct {
+	hash   chainhash.Hash
+	header *block.Header
+	height uint64
+	parent *node
+	work   *big.Int // The cumulative work of the chain up to the header
+}
+
+// Store is a block header chain (safe for concurrent use)
+type Store struct {
+	chain        []*node // The longest chain, from the checkpoint
+	mu           sync.RWMutex
+	nodes        map[chainhash.Hash]*node
+	params       ChainParams
+	proofOfWork  bool
+	reorgHandler func(reorg Reorg)
+}
+
+// NewStore will return a store starting at the checkpoint header (trusted) at the height
+//
+// Use MainnetGenesisHeader at height 0 to import the main network from its genesis. The difficulty
+// of the headers after another checkpoint is computed from the headers preceding the checkpoint
+// (see WithCheckpointHistory): until the store has the headers of the difficulty adjustment,
+// the difficulty bits are only checked against the proof of work limit
+func NewStore(checkpoint *block.Header, height uint64, opts ...StoreOps) *Store {
+	root := &node{
+		hash:   checkpoint.Hash(),
+		header: checkpoint,
+		height: height,
+		work:   headerWork(checkpoint.Bits),
+	}
+	s := &Store{
+		chain:       []*node{root},
+		nodes:       map[chainhash.Hash]*node{root.hash: root},
+		params:      MainnetParams(),
+		proofOfWork: true,
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// AddHeader will validate the header and add it to the store (known headers are ignored)
+//
+// If the header is the tip of a chain with more work, it becomes the tip of the longest chain (reorg)
+func (s *Store) AddHeader(header *block.Header) error {
+	hash := header.Hash()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.nodes[hash]; ok {
+		return nil
+	}
+	parent, ok := s.nodes[header.PrevHash]
+	if !ok {
+		return fmt.Errorf("header %s, previous %s: %w", hash, header.PrevHash, ErrHeaderNotConnected)
+	}
+	if s.proofOfWork {
+		if err := validateProofOfWork(hash, header.Bits); err != nil {
+			return fmt.Errorf("header %s: %w", hash, err)
+		}
+		if err := s.params.validateBits(parent, header.Bits); err != nil {
+			return fmt.Errorf("header %s: %w", hash, err)
+		}
+	}
+
+	n := &node{
+		hash:   hash,
+		header: header,
+		height: parent.height + 1,
+		parent: parent,
+		work:   new(big.Int).Add(parent.work, headerWork(header.Bits)),
+	}
+	s.nodes[hash] = n
+
+	if tip := s.tip(); n.work.Cmp(tip.work) > 0 {
+		s.setTip(n, tip)
+	}
+	return nil
+}
+
+// Import will read raw 80-byte headers from the stream and add them (returns the number of headers read)
+func (s *Store) Import(r io.Reader) (int, error) {
+	raw := make([]byte, block.HeaderSize)
+	for count := 0; ; count++ {
+		if _, err := io.ReadFull(r, raw); errors.Is(err, io.EOF) {
+			return count, nil
+		} else if errors.Is(err, io.ErrUnexpectedEOF) {
+			return count, fmt.Errorf("header %d: %w", count, ErrHeaderTruncated)
+		} else if err != nil {
+			return count, err
+		}
+
+		header, err := block.NewHeaderFromBytes(raw)
+		if err != nil {
+			return count, err
+		}
+		if err = s.AddHeader(header); err != nil {
+			return count, fmt.Errorf("header %d: %w", count, err)
+		}
+	}
+}
+
+// ImportFile will import the raw 80-byte headers of the file (see Import)
+func (s *Store) ImportFile(path string) (int, error) {
+	f, err := os.Open(path) // #nosec G304 - the path is provided by the operator
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return s.Import(f)
+}
+
+// Height will return the height of the tip of the longest chain
+func (s *Store) Height() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tip().height
+}
+
+// Tip will return the header of the tip of the longest chain
+func (s *Store) Tip() *block.Header {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tip().header
+}
+
+// HeaderByHeight will return the header of the longest chain at the height
+func (s *Store) HeaderByHeight(height uint64) (*block.Header, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := s.nodeByHeight(height)
+	if n == nil {
+		return nil, fmt.Errorf("height %d: %w", height, ErrHeightNotFound)
+	}
+	return n.header, nil
+}
+
+// VerifyMerkleRoots will check that the merkle roots are the ones of the longest chain at their heights
+func (s *Store) VerifyMerkleRoots(_ context.Context, merkleRoots []*spv.MerkleRootConfirmationRequestItem) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, item := range merkleRoots {
+		n := s.nodeByHeight(item.BlockHeight)
+		if n == nil || n.header.MerkleRoot.String() != item.MerkleRoot {
+			return fmt.Errorf("merkle root %s at height %d: %w", item.MerkleRoot, item.BlockHeight, ErrMerkleRootNotFound)
+		}
+	}
+	return nil
+}
+
+// tip will return the tip of the longest chain (the store must be locked)
+func (s *Store) tip() *node {
+	return s.chain[len(s.chain)-1]
+}
+
+// nodeByHeight will return the header of the longest chain at the height (the store must be locked)
+func (s *Store) nodeByHeight(height uint64) *node {
+	base := s.chain[0].height
+	if height < base || height-base >= uint64(len(s.chain)) {
+		return nil
+	}
+	return s.chain[height-base]
+}
+
+// setTip will make the node the tip of the longest chain (the store must be locked)
+func (s *Store) setTip(n, oldTip *node) {
+	// the new chain is linked back to the longest chain (the checkpoint is always in the longest chain)
+	var branch []*node
+	fork := n
+	for fork != s.nodeByHeight(fork.height) {
+		branch = append(branch, fork)
+		fork = fork.parent
+	}
+
+	base := s.chain[0].height
+	s.chain = s.chain[:fork.height-base+1]
+	for i := len(branch) - 1; i >= 0; i-- {
+		s.chain = append(s.chain, branch[i])
+	}
+
+	if fork != oldTip && s.reorgHandler != nil {
+		s.reorgHandler(Reorg{
+			Depth:      oldTip.height - fork.height,
+			ForkHeight: fork.height,
+			NewTip:     n.hash,
+			OldTip:     oldTip.hash,
+		})
+	}
+}
+
+// validateProofOfWork will check that the hash is below the target of the bits
+func validateProofOfWork(hash chainhash.Hash, bits uint32) error {
+	target := compactToBig(bits)
+	if target.Sign() <= 0 {
+		return ErrHeaderInvalidBits
+	}
+
+	// the hash is a little-endian number
+	if hashToBig(hash).Cmp(target) > 0 {
+		return ErrHeaderInvalidProofOfWork
+	}
+	return nil
+}
+
+// headerWork will return the work of a header (2^256 / (target + 1))
+func headerWork(bits uint32) *big.Int {
+	target := compactToBig(bits)
+	if target.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	numerator := new(big.Int).Lsh(big.NewInt(1), 256)
+	return numerator.Div(numerator, target.Add(target, big.NewInt(1)))
+}
+
+// compactToBig will return the target of the compact difficulty bits (negative targets are returned as negative)
+func compactToBig(bits uint32) *big.Int {
+	mantissa := bits & 0x007fffff
+	exponent := uint(bits >> 24)
+
+	target := big.NewInt(int64(mantissa))
+	if exponent <= 3 {
+		target.Rsh(target, 8*(3-exponent))
+	} else {
+		target.Lsh(target, 8*(exponent-3))
+	}
+
+	if bits&0x00800000 != 0 {
+		target.Neg(target)
+	}
+	return target
+}
+
+// hashToBig will return the number of the hash (the bytes of the hash are little-endian)
+func hashToBig(hash chainhash.Hash) *big.Int {
+	reversed := make([]byte, chainhash.HashSize)
+	for i, b := range hash {
+		reversed[chainhash.HashSize-1-i] = b
+	}
+	return new(big.Int).SetBytes(reversed)
+}
@@ -0,0 +1,324 @@
+package headers
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/block"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/spv"
+)
+
+// testBits is the regtest difficulty (half of the hashes are below the target)
+const testBits = 0x207fffff
+
+// newTestStore will return a regtest store starting at the checkpoint
+func newTestStore(checkpoint *block.Header, height uint64, opts ...StoreOps) *Store {
+	return NewStore(checkpoint, height, append([]StoreOps{WithChainParams(RegtestParams())}, opts...)...)
+}
+
+// newTestHeader will mine a header on the previous header with the merkle root
+func newTestHeader(t *testing.T, prev *block.Header, merkleRoot byte) *block.Header {
+	t.Helper()
+
+	header := &block.Header{Version: 1, PrevHash: prev.Hash(), Bits: testBits, Timestamp: prev.Timestamp + 600}
+	header.MerkleRoot[0] = merkleRoot
+	return mineTestHeader(t, header)
+}
+
+// mineTestHeader will increment the nonce of the header until its hash is below the target of its bits
+func mineTestHeader(t *testing.T, header *block.Header) *block.Header {
+	t.Helper()
+
+	for ; validateProofOfWork(header.Hash(), header.Bits) != nil; header.Nonce++ {
+		require.Less(t, header.Nonce, uint32(1000))
+	}
+	return header
+}
+
+// newTestChain will return the checkpoint and a chain of headers on it
+func newTestChain(t *testing.T, length int, merkleRoot byte) (*block.Header, []*block.Header) {
+	t.Helper()
+
+	checkpoint := &block.Header{Version: 1, Bits: testBits}
+	return checkpoint, extendTestChain(t, checkpoint, length, merkleRoot)
+}
+
+// extendTestChain will mine headers on the previous header (the merkle roots are incremented)
+func extendTestChain(t *testing.T, prev *block.Header, length int, merkleRoot byte) []*block.Header {
+	t.Helper()
+
+	headers := make([]*block.Header, 0, length)
+	for i := 0; i < length; i++ {
+		prev = newTestHeader(t, prev, merkleRoot+byte(i))
+		headers = append(headers, prev)
+	}
+	return headers
+}
+
+// rawHeaders will return the headers as a stream of raw 80-byte headers
+func rawHeaders(headers []*block.Header) []byte {
+	var raw []byte
+	for _, header := range headers {
+		raw = append(raw, header.Bytes()...)
+	}
+	return raw
+}
+
+func TestMainnetGenesisHeader(t *testing.T) {
+	t.Parallel()
+
+	genesis, err := block.NewHeaderFromHex(MainnetGenesisHeader)
+	require.NoError(t, err)
+
+	hash := genesis.Hash()
+	assert.Equal(t, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", hash.String())
+	assert.Equal(t, "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", genesis.MerkleRoot.String())
+	require.NoError(t, validateProofOfWork(hash, genesis.Bits))
+}
+
+func TestStore_Import(t *testing.T) {
+	t.Parallel()
+
+	t.Run("longest chain", func(t *testing.T) {
+		// given
+		checkpoint, headers := newTestChain(t, 10, 1)
+		store := newTestStore(checkpoint, 100)
+
+		// when
+		count, err := store.Import(bytes.NewReader(rawHeaders(headers)))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 10, count)
+		assert.Equal(t, uint64(110), store.Height())
+		assert.Equal(t, headers[9], store.Tip())
+
+		header, err := store.HeaderByHeight(105)
+		require.NoError(t, err)
+		assert.Equal(t, headers[4], header)
+		header, err = store.HeaderByHeight(100)
+		require.NoError(t, err)
+		assert.Equal(t, checkpoint, header)
+	})
+
+	t.Run("known headers are ignored", func(t *testing.T) {
+		// given
+		checkpoint, headers := newTestChain(t, 3, 1)
+		store := newTestStore(checkpoint, 0)
+
+		// when
+		_, err := store.Import(bytes.NewReader(rawHeaders(append(headers, headers...))))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), store.Height())
+	})
+
+	t.Run("truncated stream", func(t *testing.T) {
+		// given
+		checkpoint, headers := newTestChain(t, 3, 1)
+		store := newTestStore(checkpoint, 0)
+		raw := rawHeaders(headers)
+
+		// when
+		count, err := store.Import(bytes.NewReader(raw[:len(raw)-1]))
+
+		// then
+		require.ErrorIs(t, err, ErrHeaderTruncated)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, uint64(2), store.Height())
+	})
+
+	t.Run("header not connected", func(t *testing.T) {
+		// given
+		checkpoint, headers := newTestChain(t, 3, 1)
+		store := newTestStore(checkpoint, 0)
+
+		// when
+		count, err := store.Import(bytes.NewReader(rawHeaders(headers[1:])))
+
+		// then
+		require.ErrorIs(t, err, ErrHeaderNotConnected)
+		assert.Zero(t, count)
+		assert.Zero(t, store.Height())
+	})
+
+	t.Run("invalid proof of work", func(t *testing.T) {
+		// given
+		genesis, err := block.NewHeaderFromHex(MainnetGenesisHeader)
+		require.NoError(t, err)
+		store := NewStore(genesis, 0)
+		header := &block.Header{Version: 1, PrevHash: genesis.Hash(), Bits: genesis.Bits}
+
+		// when
+		err = store.AddHeader(header)
+
+		// then
+		require.ErrorIs(t, err, ErrHeaderInvalidProofOfWork)
+		require.NoError(t, NewStore(genesis, 0, WithoutProofOfWork()).AddHeader(header))
+	})
+
+	t.Run("invalid bits", func(t *testing.T) {
+		// given
+		checkpoint, _ := newTestChain(t, 0, 1)
+		store := newTestStore(checkpoint, 0)
+
+		// when
+		err := store.AddHeader(&block.Header{Version: 1, PrevHash: checkpoint.Hash(), Bits: 0x20800000})
+
+		// then
+		require.ErrorIs(t, err, ErrHeaderInvalidBits)
+	})
+
+	t.Run("regtest header on the main network", func(t *testing.T) {
+		// given
+		genesis, err := block.NewHeaderFromHex(MainnetGenesisHeader)
+		require.NoError(t, err)
+		store := NewStore(genesis, 0)
+
+		// when
+		err = store.AddHeader(newTestHeader(t, genesis, 1))
+
+		// then
+		require.ErrorIs(t, err, ErrHeaderInvalidBits)
+		assert.Zero(t, store.Height())
+	})
+
+	t.Run("difficulty changed without an adjustment", func(t *testing.T) {
+		// given
+		checkpoint, _ := newTestChain(t, 0, 1)
+		store := newTestStore(checkpoint, 0)
+		header := mineTestHeader(t, &block.Header{Version: 1, PrevHash: checkpoint.Hash(), Bits: 0x207ffffe})
+
+		// when
+		err := store.AddHeader(header)
+
+		// then
+		require.ErrorIs(t, err, ErrHeaderInvalidDifficulty)
+		require.NoError(t, newTestStore(checkpoint, 0, WithoutProofOfWork()).AddHeader(header))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		// given
+		checkpoint, headers := newTestChain(t, 5, 1)
+		store := newTestStore(checkpoint, 0)
+		path := filepath.Join(t.TempDir(), "headers.bin")
+		require.NoError(t, os.WriteFile(path, rawHeaders(headers), 0o600))
+
+		// when
+		count, err := store.ImportFile(path)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+		assert.Equal(t, uint64(5), store.Height())
+	})
+}
+
+func TestStore_Reorg(t *testing.T) {
+	t.Parallel()
+
+	// given
+	checkpoint, main := newTestChain(t, 3, 1)
+	fork := extendTestChain(t, main[0], 3, 100)
+	var reorgs []Reorg
+	store := newTestStore(checkpoint, 0, WithReorgHandler(func(reorg Reorg) {
+		reorgs = append(reorgs, reorg)
+	}))
+	_, err := store.Import(bytes.NewReader(rawHeaders(main)))
+	require.NoError(t, err)
+
+	// when
+	_, err = store.Import(bytes.NewReader(rawHeaders(fork[:2])))
+	require.NoError(t, err)
+
+	// then the fork has the same work (the first chain is kept)
+	assert.Equal(t, main[2], store.Tip())
+	assert.Empty(t, reorgs)
+
+	// when
+	require.NoError(t, store.AddHeader(fork[2]))
+
+	// then
+	assert.Equal(t, uint64(4), store.Height())
+	assert.Equal(t, fork[2], store.Tip())
+	require.Len(t, reorgs, 1)
+	assert.Equal(t, Reorg{Depth: 2, ForkHeight: 1, NewTip: fork[2].Hash(), OldTip: main[2].Hash()}, reorgs[0])
+
+	header, err := store.HeaderByHeight(2)
+	require.NoError(t, err)
+	assert.Equal(t, fork[0], header)
+	header, err = store.HeaderByHeight(1)
+	require.NoError(t, err)
+	assert.Equal(t, main[0], header)
+}
+
+func TestStore_VerifyMerkleRoots(t *testing.T) {
+	t.Parallel()
+
+	checkpoint, headers := newTestChain(t, 3, 1)
+	store := newTestStore(checkpoint, 10)
+	_, err := store.Import(bytes.NewReader(rawHeaders(headers)))
+	require.NoError(t, err)
+
+	t.Run("merkle roots in the longest chain", func(t *testing.T) {
+		err := store.VerifyMerkleRoots(context.Background(), []*spv.MerkleRootConfirmationRequestItem{
+			{BlockHeight: 11, MerkleRoot: headers[0].MerkleRoot.String()},
+			{BlockHeight: 13, MerkleRoot: headers[2].MerkleRoot.String()},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("merkle root of another height", func(t *testing.T) {
+		err := store.VerifyMerkleRoots(context.Background(), []*spv.MerkleRootConfirmationRequestItem{
+			{BlockHeight: 12, MerkleRoot: headers[0].MerkleRoot.String()},
+		})
+		require.ErrorIs(t, err, ErrMerkleRootNotFound)
+	})
+
+	t.Run("height not in the chain", func(t *testing.T) {
+		err := store.VerifyMerkleRoots(context.Background(), []*spv.MerkleRootConfirmationRequestItem{
+			{BlockHeight: 14, MerkleRoot: headers[0].MerkleRoot.String()},
+		})
+		require.ErrorIs(t, err, ErrMerkleRootNotFound)
+
+		_, err = store.HeaderByHeight(9)
+		require.ErrorIs(t, err, ErrHeightNotFound)
+	})
+
+	t.Run("merkle root of a BUMP", func(t *testing.T) {
+		// given
+		txIDs := []string{
+			"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b",
+			"0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098",
+		}
+		bump, err := beef.NewBUMP(1, txIDs, txIDs[1])
+		require.NoError(t, err)
+		merkleRoot, err := bump.CalculateMerkleRoot()
+		require.NoError(t, err)
+
+		genesis := &block.Header{Version: 1, Bits: testBits}
+		header := newTestHeader(t, genesis, 0)
+		root, err := chainhash.NewHashFromHex(merkleRoot)
+		require.NoError(t, err)
+		header.MerkleRoot = *root
+		offline := NewStore(genesis, 0, WithoutProofOfWork())
+		require.NoError(t, offline.AddHeader(header))
+
+		// when
+		err = offline.VerifyMerkleRoots(context.Background(), []*spv.MerkleRootConfirmationRequestItem{
+			{BlockHeight: 1, MerkleRoot: merkleRoot},
+		})
+
+		// then
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,165 @@
+package spv
+
+import (
+	"context"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+)
+
+// VerificationReport is the detailed result of the SPV of a BEEF (see VerifyBEEF)
+type VerificationReport struct {
+	Error        string               `json:"error,omitempty"` // The first failure (as returned by ExecuteSimplifiedPaymentVerification)
+	MerkleRoots  []*MerkleRootResult  `json:"merkleRoots"`     // Results for every BUMP
+	Rules        []*RuleResult        `json:"rules"`           // Results for every rule (in the order of the verifier)
+	SubjectTxID  string               `json:"subjectTxId"`     // The processed transaction (the last one or the Atomic BEEF subject)
+	Transactions []*TransactionResult `json:"transactions"`    // Results for every transaction (in BEEF order)
+	Valid        bool                 `json:"valid"`           // True if every check passed
+
+	err error
+}
+
+// TransactionResult is the verification of a transaction of the BEEF
+//
+// Only unmined transactions have input results (mined transactions are proven by their BUMP),
+// and TXID-only transactions (BEEF V2) are known-valid transactions which are not verified.
+// The fields of the checks of disabled rules are not set
+type TransactionResult struct {
+	BumpIndex        *uint64        `json:"bumpIndex,omitempty"` // The BUMP of a mined transaction
+	Error            string         `json:"error,omitempty"`     // The first failure of the transaction
+	Fee              uint64         `json:"fee"`                 // Inputs minus outputs (set by the fee rate rule)
+	FeePaidByPackage bool           `json:"feePaidByPackage"`    // The fee is paid with the unmined ancestors or descendants (CPFP)
+	FeeRateValid     bool           `json:"feeRateValid"`        // The fee of the transaction alone is above the fee policy
+	HasInputs        bool           `json:"hasInputs"`
+	HasOutputs       bool           `json:"hasOutputs"`
+	InBUMP           bool           `json:"inBump"` // The BUMP of the mined transaction contains the txid
+	InputSatoshis    uint64         `json:"inputSatoshis"`
+	Inputs           []*InputResult `json:"inputs,omitempty"`
+	LockTimeValid    bool           `json:"lockTimeValid"`
+	Mined            bool           `json:"mined"`
+	MinimumFee       uint64         `json:"minimumFee"` // The minimum fee of the fee policy
+	OutputSatoshis   uint64         `json:"outputSatoshis"`
+	SatoshisBalanced bool           `json:"satoshisBalanced"` // Inputs are more than outputs (not checked if a parent is TXID-only)
+	TxID             string         `json:"txId"`
+	TxIDOnly         bool           `json:"txIdOnly,omitempty"`
+	Valid            bool           `json:"valid"`
+}
+
+// InputResult is the verification of an input of an unmined transaction
+type InputResult struct {
+	Error             string `json:"error,omitempty"` // The failure of the input
+	Index             int    `json:"index"`
+	ParentFound       bool   `json:"parentFound"`              // The parent transaction is in the BEEF
+	ParentTxIDOnly    bool   `json:"parentTxIdOnly,omitempty"` // The parent is a known transaction (the script is not verified)
+	Satoshis          uint64 `json:"satoshis"`                 // Satoshis of the spent output
+	ScriptError       string `json:"scriptError,omitempty"`    // The error of the script interpreter
+	ScriptValid       bool   `json:"scriptValid"`
+	SourceOutputIndex uint32 `json:"sourceOutputIndex"`
+	SourceTxID        string `json:"sourceTxId"`
+	Valid             bool   `json:"valid"`
+}
+
+// RuleResult is the result of a rule of the verifier
+type RuleResult struct {
+	Error string `json:"error,omitempty"`
+	Name  string `json:"name"`
+	Valid bool   `json:"valid"`
+}
+
+// MerkleRootResult is the verification of the merkle root of a BUMP
+type MerkleRootResult struct {
+	BlockHeight uint64 `json:"blockHeight"`
+	Error       string `json:"error,omitempty"`
+	MerkleRoot  string `json:"merkleRoot,omitempty"`
+	Verified    bool   `json:"verified"` // The merkle root is in the longest chain (verified by the MerkleRootVerifier)
+}
+
+// Err will return the first failure of the verification (nil if the BEEF is valid)
+func (r *VerificationReport) Err() error {
+	return r.err
+}
+
+// fail will record the failure (only the first failure is returned by Err)
+func (r *VerificationReport) fail(err error) {
+	if r.err == nil {
+		r.err = err
+		r.Error = err.Error()
+	}
+}
+
+// Fail will record the failure of the transaction (only the first failure is kept)
+func (t *TransactionResult) Fail(err error) {
+	if t.Valid {
+		t.Valid = false
+		t.Error = err.Error()
+	}
+}
+
+// Fail will record the failure of the input (only the first failure is kept)
+func (i *InputResult) Fail(err error) {
+	if i.Valid {
+		i.Valid = false
+		i.Error = err.Error()
+	}
+}
+
+// VerifyBEEF will verify the decoded BEEF with the default rules and report the result of every check
+//
+// The checks are those of ExecuteSimplifiedPaymentVerification, but the verification does not stop at the
+// first failure: every rule is run on every transaction (the report error is the first failure)
+func VerifyBEEF(ctx context.Context, dBeef *beef.DecodedBEEF, provider MerkleRootVerifier) *VerificationReport {
+	return NewVerifier(provider).Verify(ctx, dBeef)
+}
+
+// newVerificationReport will return the report of the BEEF before the rules are run
+//
+// The transactions, the inputs of unmined transactions and their parents are set (the checks are done by the rules)
+func newVerificationReport(dBeef *beef.DecodedBEEF) *VerificationReport {
+	report := &VerificationReport{
+		MerkleRoots:  make([]*MerkleRootResult, 0, len(dBeef.BUMPs)),
+		Transactions: make([]*TransactionResult, 0, len(dBeef.Transactions)),
+	}
+	if latestTx := dBeef.GetLatestTx(); latestTx != nil {
+		report.SubjectTxID = latestTx.TxID().String()
+	}
+
+	for _, txDt := range dBeef.Transactions {
+		result := &TransactionResult{
+			Mined:    !txDt.Unmined(),
+			TxID:     txDt.GetTxID(),
+			TxIDOnly: txDt.TxIDOnly,
+			Valid:    true,
+		}
+		report.Transactions = append(report.Transactions, result)
+
+		switch {
+		case txDt.TxIDOnly:
+			continue
+		case result.Mined:
+			bumpIndex := uint64(*txDt.BumpIndex)
+			result.BumpIndex = &bumpIndex
+			continue
+		}
+
+		for i, input := range txDt.Transaction.Inputs {
+			inputResult := &InputResult{
+				Index:             i,
+				SourceOutputIndex: input.SourceTxOutIndex,
+				SourceTxID:        input.SourceTXID.String(),
+				Valid:             true,
+			}
+			result.Inputs = append(result.Inputs, inputResult)
+
+			parent := findParentForInput(input, dBeef)
+			switch {
+			case parent == nil:
+				continue
+			case parent.TxIDOnly:
+				inputResult.ParentFound, inputResult.ParentTxIDOnly = true, true
+			case int(input.SourceTxOutIndex) < len(parent.Transaction.Outputs):
+				inputResult.ParentFound = true
+				inputResult.Satoshis = parent.Transaction.Outputs[input.SourceTxOutIndex].Satoshis
+			}
+		}
+	}
+	return report
+}
@@ -0,0 +1,192 @@
+package spv
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// errTestUnknownMerkleRoot is returned by the test verifier for the rejected merkle root
+var errTestUnknownMerkleRoot = stderrors.New("unknown merkle root")
+
+func TestVerifyBEEF(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid BEEF", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+
+		// when
+		report := VerifyBEEF(context.Background(), dBeef, new(mockServiceProvider))
+
+		// then
+		require.True(t, report.Valid)
+		require.NoError(t, report.Err())
+		require.Empty(t, report.Error)
+		require.Equal(t, dBeef.GetLatestTx().TxID().String(), report.SubjectTxID)
+		require.Len(t, report.Transactions, len(dBeef.Transactions))
+		for i, result := range report.Transactions {
+			require.True(t, result.Valid)
+			require.Equal(t, dBeef.Transactions[i].GetTxID(), result.TxID)
+			require.True(t, result.LockTimeValid)
+			if result.Mined {
+				require.True(t, result.InBUMP)
+				require.NotNil(t, result.BumpIndex)
+				require.Empty(t, result.Inputs)
+				continue
+			}
+
+			require.True(t, result.SatoshisBalanced)
+			require.Greater(t, result.InputSatoshis, result.OutputSatoshis)
+			require.Len(t, result.Inputs, len(dBeef.Transactions[i].Transaction.Inputs))
+			for _, input := range result.Inputs {
+				require.True(t, input.Valid)
+				require.True(t, input.ParentFound)
+				require.True(t, input.ScriptValid)
+				require.Empty(t, input.ScriptError)
+			}
+		}
+		require.Len(t, report.MerkleRoots, len(dBeef.BUMPs))
+		for _, result := range report.MerkleRoots {
+			require.True(t, result.Verified)
+			require.NotEmpty(t, result.MerkleRoot)
+		}
+	})
+
+	t.Run("outputs higher than inputs", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		latest := dBeef.Transactions[len(dBeef.Transactions)-1]
+		latest.Transaction.Outputs[0].Satoshis = 21e14
+
+		// when
+		report := VerifyBEEF(context.Background(), dBeef, new(mockServiceProvider))
+
+		// then
+		require.False(t, report.Valid)
+		require.Equal(t, errors.ErrOutputValueTooHigh, report.Err())
+		require.Equal(t, errors.ErrOutputValueTooHigh.Error(), report.Error)
+
+		result := report.Transactions[len(report.Transactions)-1]
+		require.False(t, result.Valid)
+		require.False(t, result.SatoshisBalanced)
+		require.Equal(t, errors.ErrOutputValueTooHigh.Error(), result.Error)
+
+		// the signatures do not sign the changed output
+		for _, input := range result.Inputs {
+			require.True(t, input.ParentFound)
+			require.False(t, input.ScriptValid)
+			require.NotEmpty(t, input.ScriptError)
+		}
+
+		// the other checks are still done
+		require.True(t, report.Transactions[0].Valid)
+		for _, result := range report.MerkleRoots {
+			require.True(t, result.Verified)
+		}
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		latest := dBeef.Transactions[len(dBeef.Transactions)-1]
+		parentTxID := latest.Transaction.Inputs[0].SourceTXID.String()
+		for i, tx := range dBeef.Transactions {
+			if tx.GetTxID() == parentTxID {
+				dBeef.Transactions = append(dBeef.Transactions[:i], dBeef.Transactions[i+1:]...)
+				break
+			}
+		}
+
+		// when
+		report := VerifyBEEF(context.Background(), dBeef, new(mockServiceProvider))
+
+		// then
+		require.False(t, report.Valid)
+		require.Equal(t, errors.ErrInvalidParentTransactions, report.Err())
+
+		result := report.Transactions[len(report.Transactions)-1]
+		require.False(t, result.Valid)
+		require.False(t, result.Inputs[0].ParentFound)
+		require.Equal(t, parentTxID, result.Inputs[0].SourceTxID)
+		require.Equal(t, errors.ErrInvalidParentTransactions.Error(), result.Inputs[0].Error)
+	})
+
+	t.Run("TXID-only parents", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		dBeef.Format = beef.BEEFV2
+		dBeef.BUMPs = nil
+		for i, tx := range dBeef.Transactions[:len(dBeef.Transactions)-1] {
+			dBeef.Transactions[i] = beef.NewTxIDOnly(tx.GetTxID())
+		}
+
+		// when
+		report := VerifyBEEF(context.Background(), dBeef, new(mockServiceProvider))
+
+		// then
+		require.True(t, report.Valid)
+		require.Empty(t, report.MerkleRoots)
+		require.True(t, report.Transactions[0].TxIDOnly)
+
+		result := report.Transactions[len(report.Transactions)-1]
+		require.True(t, result.SatoshisBalanced)
+		for _, input := range result.Inputs {
+			require.True(t, input.ParentTxIDOnly)
+			require.Zero(t, input.Satoshis)
+		}
+	})
+
+	t.Run("merkle root not verified", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		require.Greater(t, len(dBeef.BUMPs), 1)
+		rejected, err := dBeef.BUMPs[1].CalculateMerkleRoot()
+		require.NoError(t, err)
+
+		// when
+		report := VerifyBEEF(context.Background(), dBeef, &rejectingServiceProvider{merkleRoot: rejected})
+
+		// then
+		require.False(t, report.Valid)
+		require.ErrorIs(t, report.Err(), errTestUnknownMerkleRoot)
+		for i, result := range report.MerkleRoots {
+			require.Equal(t, dBeef.BUMPs[i].BlockHeight, result.BlockHeight)
+			if result.MerkleRoot == rejected {
+				require.False(t, result.Verified)
+				require.Equal(t, errTestUnknownMerkleRoot.Error(), result.Error)
+				continue
+			}
+			require.True(t, result.Verified)
+			require.Empty(t, result.Error)
+		}
+		for _, result := range report.Transactions {
+			require.True(t, result.Valid)
+		}
+	})
+}
+
+// rejectingServiceProvider is a service provider which does not know one merkle root
+type rejectingServiceProvider struct {
+	merkleRoot string
+}
+
+// VerifyMerkleRoots will fail if the requests contain the rejected merkle root
+func (m *rejectingServiceProvider) VerifyMerkleRoots(_ context.Context, requests []*MerkleRootConfirmationRequestItem) error {
+	for _, request := range requests {
+		if request.MerkleRoot == m.merkleRoot {
+			return errTestUnknownMerkleRoot
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package spv
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"runtime"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// Names of the built-in rules (see DefaultRules)
+const (
+	RuleNameBUMPIndexes          = "bump-indexes"          // The BUMP indexes of the mined transactions are BUMPs of the BEEF
+	RuleNameBUMPs                = "bumps"                 // The mined ancestors of the processed transaction are in their BUMP
+	RuleNameDoubleSpends         = "double-spends"         // No outpoint is spent by two transactions of the BEEF
+	RuleNameDuplicates           = "duplicates"            // Every transaction is in the BEEF once
+	RuleNameLockTime             = "locktime"              // The locktime is final (sequences of the inputs)
+	RuleNameMerkleRoots          = "merkle-roots"          // The merkle roots of the BUMPs are verified by the provider
+	RuleNameOrder                = "order"                 // The parents are before their children, the processed transaction is a raw transaction
+	RuleNameSatoshis             = "satoshis"              // The parents are in the BEEF, inputs are more than outputs
+	RuleNameScripts              = "scripts"               // The unlocking scripts of the inputs are valid
+	RuleNameTransactionStructure = "transaction-structure" // The transaction has inputs and outputs
+)
+
+// DefaultRules will return the built-in rules of the SPV (in the order of ExecuteSimplifiedPaymentVerification)
+//
+// The structure of the BEEF is checked first (see beef.ValidateStructure), then the transactions
+func DefaultRules() []Rule {
+	return []Rule{
+		NewStructureRule(RuleNameBUMPIndexes, beef.ValidateBUMPIndexes, errors.ErrInvalidBUMPIndex),
+		NewStructureRule(RuleNameDuplicates, beef.ValidateUniqueTransactions, errors.ErrDuplicateTransaction),
+		NewStructureRule(RuleNameOrder, beef.ValidateTransactionOrder, errors.ErrInvalidTransactionOrder),
+		NewStructureRule(RuleNameDoubleSpends, beef.ValidateNoDoubleSpends, errors.ErrDoubleSpend),
+		NewRule(RuleNameTransactionStructure, verifyTransactionStructure),
+		NewRule(RuleNameLockTime, verifyLockTime),
+		NewRule(RuleNameSatoshis, verifySatoshis),
+		NewScriptRule(runtime.GOMAXPROCS(0)),
+		NewRule(RuleNameBUMPs, verifyBUMPs),
+		NewRule(RuleNameMerkleRoots, verifyMerkleRoots),
+	}
+}
+
+// NewStructureRule will return the rule running the structure validation of the BEEF (e.g. beef.ValidateNoDoubleSpends)
+//
+// The error is the SPV error wrapping the validation error, and the failed transaction is marked in the report
+func NewStructureRule(name string, validate func(d *beef.DecodedBEEF) error, spvErr errors.SPVError) Rule {
+	return NewRule(name, func(_ context.Context, rc *RuleContext) error {
+		err := validate(rc.BEEF)
+		if err == nil {
+			return nil
+		}
+
+		err = fmt.Errorf("%w: %w", spvErr, err)
+		var structureErr *beef.StructureError
+		if stderrors.As(err, &structureErr) && structureErr.Index < len(rc.Report.Transactions) {
+			rc.Report.Transactions[structureErr.Index].Fail(err)
+		}
+		return err
+	})
+}
+
+// verifyTransactionStructure will check that the transactions have outputs and inputs
+func verifyTransactionStructure(_ context.Context, rc *RuleContext) error {
+	return rc.EachTransaction(func(txDt *beef.TxData, result *TransactionResult) error {
+		result.HasOutputs = len(txDt.Transaction.Outputs) > 0
+		result.HasInputs = len(txDt.Transaction.Inputs) > 0
+
+		switch {
+		case !result.HasOutputs:
+			return errors.ErrNoOutputs
+		case !result.HasInputs:
+			return errors.ErrNoInputs
+		}
+		return nil
+	})
+}
+
+// verifyLockTime will check the locktime of the transactions
+func verifyLockTime(_ context.Context, rc *RuleContext) error {
+	return rc.EachTransaction(func(txDt *beef.TxData, result *TransactionResult) error {
+		err := validateLockTime(txDt.Transaction)
+		result.LockTimeValid = err == nil
+		return err
+	})
+}
+
+// verifySatoshis will check that the parents of the unmined transactions are in the BEEF,
+// and that the inputs are more than the outputs
+//
+// The satoshis of known transactions (TXID-only) are not in the BEEF: the balance is not checked
+func verifySatoshis(_ context.Context, rc *RuleContext) error {
+	return rc.EachTransaction(func(txDt *beef.TxData, result *TransactionResult) error {
+		if result.Mined {
+			return nil
+		}
+
+		parentsFound, balanceKnown := true, true
+		for _, input := range result.Inputs {
+			if !input.ParentFound {
+				input.Fail(errors.ErrInvalidParentTransactions)
+				parentsFound = false
+			}
+			balanceKnown = balanceKnown && !input.ParentTxIDOnly
+			result.InputSatoshis += input.Satoshis
+		}
+		for _, output := range txDt.Transaction.Outputs {
+			result.OutputSatoshis += output.Satoshis
+		}
+
+		if !parentsFound {
+			return errors.ErrInvalidParentTransactions
+		}
+		if result.SatoshisBalanced = !balanceKnown || result.InputSatoshis > result.OutputSatoshis; !result.SatoshisBalanced {
+			return errors.ErrOutputValueTooHigh
+		}
+		return nil
+	})
+}
+
+// verifyBUMPs will check that the mined ancestors of the processed transaction can be found,
+// and that the mined transactions are in their BUMP
+func verifyBUMPs(_ context.Context, rc *RuleContext) error {
+	latestTx := rc.BEEF.GetLatestTx()
+	if latestTx == nil {
+		return nil
+	}
+
+	if _, err := findMinedAncestors(latestTx, rc.BEEF); err != nil {
+		return err
+	}
+
+	return rc.EachTransaction(func(txDt *beef.TxData, result *TransactionResult) error {
+		if !result.Mined {
+			return nil
+		}
+
+		if result.InBUMP = existsInBumps(txDt, rc.BEEF.BUMPs); !result.InBUMP {
+			return errors.ErrBUMPAncestorNotPresent
+		}
+		return nil
+	})
+}
+
+// verifyMerkleRoots will calculate the merkle roots of the BUMPs and verify them with the provider
+//
+// If the verification of multiple merkle roots fails, each one is verified to find which ones failed
+func verifyMerkleRoots(ctx context.Context, rc *RuleContext) error {
+	requests := make([]*MerkleRootConfirmationRequestItem, 0, len(rc.BEEF.BUMPs))
+	results := make([]*MerkleRootResult, 0, len(rc.BEEF.BUMPs))
+	var firstErr error
+	for _, bump := range rc.BEEF.BUMPs {
+		result := &MerkleRootResult{BlockHeight: bump.BlockHeight}
+		rc.Report.MerkleRoots = append(rc.Report.MerkleRoots, result)
+
+		merkleRoot, err := bump.CalculateMerkleRoot()
+		if err != nil {
+			result.Error = err.Error()
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		result.MerkleRoot = merkleRoot
+		requests = append(requests, &MerkleRootConfirmationRequestItem{
+			BlockHeight: bump.BlockHeight,
+			MerkleRoot:  merkleRoot,
+		})
+		results = append(results, result)
+	}
+	if len(requests) == 0 {
+		return firstErr
+	}
+
+	err := rc.Provider.VerifyMerkleRoots(ctx, requests)
+	if err == nil {
+		for _, result := range results {
+			result.Verified = true
+		}
+		return firstErr
+	} else if firstErr == nil {
+		firstErr = err
+	}
+
+	for i, err := range bisectMerkleRoots(ctx, rc.Provider, requests, err) {
+		if err != nil {
+			results[i].Error = err.Error()
+		} else {
+			results[i].Verified = true
+		}
+	}
+	return firstErr
+}
+
+// bisectMerkleRoots will return the error of each merkle root, after their verification failed with the error
+//
+// The merkle roots are verified again by halves until the failed ones are found (a few failed merkle roots
+// take a few calls instead of one call per merkle root). The errors of the context are not bisected
+func bisectMerkleRoots(ctx context.Context, verifier MerkleRootVerifier,
+	merkleRoots []*MerkleRootConfirmationRequestItem, err error,
+) []error {
+	errs := make([]error, len(merkleRoots))
+	if err == nil {
+		return errs
+	} else if len(merkleRoots) == 1 || ctx.Err() != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	half := len(merkleRoots) / 2
+	left, right := merkleRoots[:half], merkleRoots[half:]
+	copy(errs, bisectMerkleRoots(ctx, verifier, left, verifier.VerifyMerkleRoots(ctx, left)))
+	copy(errs[half:], bisectMerkleRoots(ctx, verifier, right, verifier.VerifyMerkleRoots(ctx, right)))
+	return errs
+}
@@ -1,6 +1,11 @@
 package spv
 
 import (
+	"context"
+	"runtime"
+	"slices"
+	"sync"
+
 	interpreter "github.com/bsv-blockchain/go-sdk/script/interpreter"
 	sdk "github.com/bsv-blockchain/go-sdk/transaction"
 
@@ -8,19 +13,119 @@ import (
 	"github.com/bsv-blockchain/go-paymail/errors"
 )
 
-func validateScripts(tx *sdk.Transaction, inputTxs []*beef.TxData) error {
-	for i, input := range tx.Inputs {
-		inputParentTx := findParentForInput(input, inputTxs)
-		if inputParentTx == nil {
-			return errors.ErrNoMatchingTransactionsForInput
+// WithScriptWorkers will set the number of goroutines verifying the input scripts (GOMAXPROCS by default)
+func WithScriptWorkers(workers int) VerifierOps {
+	return func(v *Verifier) {
+		v.rules = slices.Clone(v.rules)
+		for i, rule := range v.rules {
+			if rule.Name() == RuleNameScripts {
+				v.rules[i] = NewScriptRule(workers)
+			}
+		}
+	}
+}
+
+// NewScriptRule will return the rule running the unlocking scripts of the inputs of the unmined transactions
+//
+// The inputs are verified by a bounded pool of workers (zero or less uses GOMAXPROCS). The locking scripts
+// of known transactions (TXID-only) are not in the BEEF: the inputs spending them are not checked
+func NewScriptRule(workers int) Rule {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return NewRule(RuleNameScripts, func(ctx context.Context, rc *RuleContext) error {
+		return verifyInputScripts(ctx, rc, workers)
+	})
+}
+
+// scriptJob is the verification of the script of an input
+type scriptJob struct {
+	err   error
+	input *InputResult
+	txDt  *beef.TxData
+}
+
+// verifyInputScripts will verify the inputs with the workers, and report the failures in the order of the BEEF
+func verifyInputScripts(ctx context.Context, rc *RuleContext, workers int) error {
+	jobs := make(map[*TransactionResult][]*scriptJob)
+	queue := make([]*scriptJob, 0)
+	for i, txDt := range rc.BEEF.Transactions {
+		result := rc.Report.Transactions[i]
+		if txDt.TxIDOnly || result.Mined {
+			continue
 		}
+		for _, input := range result.Inputs {
+			job := &scriptJob{input: input, txDt: txDt}
+			jobs[result] = append(jobs[result], job)
+			queue = append(queue, job)
+		}
+	}
+	runScriptJobs(ctx, rc.BEEF, queue, workers)
 
-		err := verifyScripts(tx, inputParentTx.Transaction, i)
-		if err != nil {
-			return errors.ErrInvalidScript
+	return rc.EachTransaction(func(_ *beef.TxData, result *TransactionResult) error {
+		var firstErr error
+		for _, job := range jobs[result] {
+			if job.err != nil {
+				job.input.Fail(job.err)
+				if firstErr == nil {
+					firstErr = job.err
+				}
+			}
 		}
+		return firstErr
+	})
+}
+
+// runScriptJobs will verify the inputs of the jobs with a pool of workers (the jobs are canceled with the context)
+//
+// The interpreter sets the source output of the verified input on the transaction, and reads the source outputs
+// of every input to check the signatures: each worker verifies the inputs on its own clone of the transaction
+func runScriptJobs(ctx context.Context, dBeef *beef.DecodedBEEF, jobs []*scriptJob, workers int) {
+	queue := make(chan *scriptJob)
+	var wg sync.WaitGroup
+	for range min(workers, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var source, clone *sdk.Transaction
+			for job := range queue {
+				if job.err = ctx.Err(); job.err != nil {
+					continue
+				}
+				// the jobs of a transaction are queued together (the clone is reused for its inputs)
+				if job.txDt.Transaction != source {
+					source, clone = job.txDt.Transaction, job.txDt.Transaction.ShallowClone()
+				}
+				job.err = verifyInputScript(clone, job.txDt, job.input, dBeef)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
 	}
+	close(queue)
+	wg.Wait()
+}
 
+// verifyInputScript will run the unlocking script of the input (on the clone of the transaction) against the
+// locking script of its parent
+//
+// The input result is only changed by its job (the jobs of a transaction can run concurrently)
+func verifyInputScript(tx *sdk.Transaction, txDt *beef.TxData, input *InputResult, dBeef *beef.DecodedBEEF) error {
+	if !input.ParentFound {
+		return errors.ErrNoMatchingTransactionsForInput
+	} else if input.ParentTxIDOnly {
+		input.ScriptValid = true
+		return nil
+	}
+
+	parent := findParentForInput(txDt.Transaction.Inputs[input.Index], dBeef)
+	if err := verifyScripts(tx, parent.Transaction, input.Index); err != nil {
+		input.ScriptError = err.Error()
+		return errors.ErrInvalidScript
+	}
+	input.ScriptValid = true
 	return nil
 }
 
@@ -0,0 +1,216 @@
+package spv
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	sdk "github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+	"github.com/bsv-blockchain/go-sdk/util"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// newTestConsolidationBEEF will return a BEEF of a consolidation transaction spending the outputs of unmined parents
+//
+// The parents spend the outputs of a mined transaction (two inputs per consolidated output). The locking scripts
+// are OP_TRUE, or OP_FALSE for the invalid parents (the inputs spending them fail the script verification)
+func newTestConsolidationBEEF(tb testing.TB, inputs int, invalid ...int) *beef.DecodedBEEF {
+	tb.Helper()
+
+	validScript, invalidScript := script.Script{script.OpTRUE}, script.Script{script.OpFALSE}
+	mined := sdk.NewTransaction()
+	mined.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{}, UnlockingScript: &script.Script{}, SequenceNumber: 0xffffffff})
+	for range inputs {
+		mined.AddOutput(&sdk.TransactionOutput{Satoshis: 1000, LockingScript: &validScript})
+	}
+	bump, err := beef.NewBUMP(800000, []string{mined.TxID().String(), chainhash.Hash{}.String()}, mined.TxID().String())
+	require.NoError(tb, err)
+
+	bumpIndex := util.VarInt(0)
+	transactions := []*beef.TxData{{Transaction: mined, BumpIndex: &bumpIndex}}
+	consolidation := sdk.NewTransaction()
+	for i := range inputs {
+		lockingScript := &validScript
+		for _, index := range invalid {
+			if index == i {
+				lockingScript = &invalidScript
+			}
+		}
+
+		parent := sdk.NewTransaction()
+		parent.AddInput(&sdk.TransactionInput{SourceTXID: mined.TxID(), SourceTxOutIndex: uint32(i), UnlockingScript: &script.Script{}, SequenceNumber: 0xffffffff}) // #nosec G115 - test sizes
+		parent.AddOutput(&sdk.TransactionOutput{Satoshis: 900, LockingScript: lockingScript})
+		transactions = append(transactions, &beef.TxData{Transaction: parent})
+
+		consolidation.AddInput(&sdk.TransactionInput{SourceTXID: parent.TxID(), UnlockingScript: &script.Script{}, SequenceNumber: 0xffffffff})
+	}
+	consolidation.AddOutput(&sdk.TransactionOutput{Satoshis: uint64(800 * inputs), LockingScript: &validScript}) // #nosec G115 - test sizes
+
+	dBeef := &beef.DecodedBEEF{
+		BUMPs:        beef.BUMPs{bump},
+		Format:       beef.BEEFV1,
+		Transactions: append(transactions, &beef.TxData{Transaction: consolidation}),
+	}
+	dBeef.Reindex()
+	return dBeef
+}
+
+// newTestSignedConsolidationBEEF will return a BEEF of a signed P2PKH consolidation spending the outputs of a mined transaction
+//
+// The consolidation is decoded from its bytes (the source outputs of the inputs are not set, as in a received BEEF)
+func newTestSignedConsolidationBEEF(t *testing.T, inputs int) *beef.DecodedBEEF {
+	t.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(t, err)
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+
+	mined := sdk.NewTransaction()
+	mined.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{}, UnlockingScript: &script.Script{}, SequenceNumber: 0xffffffff})
+	for range inputs {
+		mined.AddOutput(&sdk.TransactionOutput{Satoshis: 1000, LockingScript: lockingScript})
+	}
+	bump, err := beef.NewBUMP(800000, []string{mined.TxID().String(), chainhash.Hash{}.String()}, mined.TxID().String())
+	require.NoError(t, err)
+
+	consolidation := sdk.NewTransaction()
+	for i := range inputs {
+		consolidation.AddInput(&sdk.TransactionInput{
+			SourceTXID:              mined.TxID(),
+			SourceTransaction:       mined,
+			SourceTxOutIndex:        uint32(i), // #nosec G115 - test sizes
+			SequenceNumber:          0xffffffff,
+			UnlockingScriptTemplate: unlocker,
+		})
+	}
+	consolidation.AddOutput(&sdk.TransactionOutput{Satoshis: uint64(900 * inputs), LockingScript: lockingScript}) // #nosec G115 - test sizes
+	require.NoError(t, consolidation.Sign())
+	received, err := sdk.NewTransactionFromBytes(consolidation.Bytes())
+	require.NoError(t, err)
+
+	bumpIndex := util.VarInt(0)
+	return &beef.DecodedBEEF{
+		BUMPs:  beef.BUMPs{bump},
+		Format: beef.BEEFV1,
+		Transactions: []*beef.TxData{
+			{Transaction: mined, BumpIndex: &bumpIndex},
+			{Transaction: received},
+		},
+	}
+}
+
+func TestWithScriptWorkers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid consolidation", func(t *testing.T) {
+		// given
+		dBeef := newTestConsolidationBEEF(t, 100)
+		verifier := NewVerifier(new(mockServiceProvider), WithScriptWorkers(8))
+
+		// when
+		report := verifier.Verify(context.Background(), dBeef)
+
+		// then
+		require.True(t, report.Valid, report.Error)
+		for _, input := range report.Transactions[len(report.Transactions)-1].Inputs {
+			require.True(t, input.ScriptValid)
+		}
+	})
+
+	t.Run("signed P2PKH inputs of a transaction verified concurrently", func(t *testing.T) {
+		// given
+		dBeef := newTestSignedConsolidationBEEF(t, 64)
+		verifier := NewVerifier(new(mockServiceProvider), WithScriptWorkers(8))
+
+		// when
+		report := verifier.Verify(context.Background(), dBeef)
+
+		// then
+		require.True(t, report.Valid, report.Error)
+		for _, input := range report.Transactions[1].Inputs {
+			require.True(t, input.ScriptValid, input.ScriptError)
+		}
+	})
+
+	t.Run("the report does not depend on the workers", func(t *testing.T) {
+		// given
+		dBeef := newTestConsolidationBEEF(t, 100, 10, 42, 99)
+
+		// when
+		sequential := NewVerifier(new(mockServiceProvider), WithScriptWorkers(1)).Verify(context.Background(), dBeef)
+		parallel := NewVerifier(new(mockServiceProvider), WithScriptWorkers(8)).Verify(context.Background(), dBeef)
+
+		// then
+		require.Equal(t, errors.ErrInvalidScript, sequential.Err())
+		require.Equal(t, sequential, parallel)
+		consolidation := parallel.Transactions[len(parallel.Transactions)-1]
+		for i, input := range consolidation.Inputs {
+			require.Equal(t, i != 10 && i != 42 && i != 99, input.ScriptValid, i)
+		}
+	})
+
+	t.Run("canceled verification", func(t *testing.T) {
+		// given
+		dBeef := newTestConsolidationBEEF(t, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		report := NewVerifier(new(mockServiceProvider), WithRules(NewScriptRule(4))).Verify(ctx, dBeef)
+
+		// then
+		require.ErrorIs(t, report.Err(), context.Canceled)
+	})
+
+	t.Run("the scripts rule keeps its position", func(t *testing.T) {
+		// when
+		verifier := NewVerifier(new(mockServiceProvider), WithScriptWorkers(2))
+
+		// then
+		require.Equal(t, NewVerifier(new(mockServiceProvider)).Rules(), verifier.Rules())
+	})
+}
+
+// BenchmarkVerifier_Verify benchmarks the verification of consolidation BEEFs with thousands of inputs
+func BenchmarkVerifier_Verify(b *testing.B) {
+	for _, inputs := range []int{1000, 5000} {
+		dBeef := newTestConsolidationBEEF(b, inputs)
+		for _, workers := range []int{1, 8} {
+			verifier := NewVerifier(new(mockServiceProvider), WithScriptWorkers(workers))
+			b.Run(fmt.Sprintf("%d inputs %d workers", 2*inputs, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if report := verifier.Verify(context.Background(), dBeef); !report.Valid {
+						b.Fatal(report.Error)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkVerifier_Verify_WithoutScripts benchmarks the ancestor lookups of consolidation BEEFs (scripts are not verified)
+func BenchmarkVerifier_Verify_WithoutScripts(b *testing.B) {
+	for _, inputs := range []int{1000, 5000} {
+		dBeef := newTestConsolidationBEEF(b, inputs)
+		verifier := NewVerifier(new(mockServiceProvider), WithoutRules(RuleNameScripts))
+		b.Run(fmt.Sprintf("%d inputs", 2*inputs), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if report := verifier.Verify(context.Background(), dBeef); !report.Valid {
+					b.Fatal(report.Error)
+				}
+			}
+		})
+	}
+}
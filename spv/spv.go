@@ -24,38 +24,11 @@ type MerkleRootConfirmationRequestItem struct {
 }
 
 // ExecuteSimplifiedPaymentVerification executes the SPV for decoded BEEF tx
+//
+// TXID-only entries (BEEF V2) are known-valid transactions: they are not verified,
+// and the inputs spending them are not script or satoshi checked. Use VerifyBEEF for the result of every check
 func ExecuteSimplifiedPaymentVerification(ctx context.Context, dBeef *beef.DecodedBEEF, provider MerkleRootVerifier) error {
-	for _, txDt := range dBeef.Transactions {
-		tx := txDt.Transaction
-
-		if len(tx.Outputs) == 0 {
-			return errors.ErrNoOutputs
-		}
-
-		if len(tx.Inputs) == 0 {
-			return errors.ErrNoInputs
-		}
-
-		if err := validateLockTime(tx); err != nil {
-			return err
-		}
-
-		if txDt.Unmined() {
-			if err := validateSatoshisSum(tx, dBeef.Transactions); err != nil {
-				return err
-			}
-
-			if err := validateScripts(tx, dBeef.Transactions); err != nil {
-				return err
-			}
-		}
-	}
-
-	if err := verifyMerkleRoots(ctx, dBeef, provider); err != nil {
-		return err
-	}
-
-	return nil
+	return VerifyBEEF(ctx, dBeef, provider).Err()
 }
 
 func validateLockTime(tx *sdk.Transaction) error {
@@ -70,37 +43,6 @@ func validateLockTime(tx *sdk.Transaction) error {
 	return nil
 }
 
-func validateSatoshisSum(tx *sdk.Transaction, inputTxs []*beef.TxData) error {
-	inputSum, outputSum := uint64(0), uint64(0)
-
-	for _, input := range tx.Inputs {
-		inputParentTx := findParentForInput(input, inputTxs)
-
-		if inputParentTx == nil {
-			return errors.ErrInvalidParentTransactions
-		}
-
-		inputSum += inputParentTx.Transaction.Outputs[input.SourceTxOutIndex].Satoshis
-	}
-	for _, output := range tx.Outputs {
-		outputSum += output.Satoshis
-	}
-
-	if inputSum <= outputSum {
-		return errors.ErrOutputValueTooHigh
-	}
-
-	return nil
-}
-
-func findParentForInput(input *sdk.TransactionInput, parentTxs []*beef.TxData) *beef.TxData {
-	parentID := input.SourceTXID.String()
-
-	for _, ptx := range parentTxs {
-		if ptx.GetTxID() == parentID {
-			return ptx
-		}
-	}
-
-	return nil
+func findParentForInput(input *sdk.TransactionInput, dBeef *beef.DecodedBEEF) *beef.TxData {
+	return dBeef.FindTransaction(input.SourceTXID.String())
 }
@@ -0,0 +1,149 @@
+package spv
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+)
+
+// Rule is a check of the SPV (see DefaultRules for the built-in rules)
+//
+// Rules are run in the order of the verifier, and every rule is run even if a previous rule failed
+type Rule interface {
+	// Name is the unique name of the rule (used to disable it)
+	Name() string
+	// Verify will check the BEEF and return the first failure (recorded in the report of the context)
+	Verify(ctx context.Context, rc *RuleContext) error
+}
+
+// RuleContext is shared by the rules of a verification
+type RuleContext struct {
+	BEEF     *beef.DecodedBEEF
+	Provider MerkleRootVerifier
+	Report   *VerificationReport
+	Values   map[string]any // Values shared between the rules (e.g. computed by a rule for the next ones)
+}
+
+// EachTransaction will call fn for every transaction with a raw transaction (TXID-only transactions are not verified)
+//
+// An error fails the transaction in the report, and the first error is returned
+func (rc *RuleContext) EachTransaction(fn func(txDt *beef.TxData, result *TransactionResult) error) error {
+	var firstErr error
+	for i, txDt := range rc.BEEF.Transactions {
+		if txDt.TxIDOnly {
+			continue
+		}
+
+		result := rc.Report.Transactions[i]
+		if err := fn(txDt, result); err != nil {
+			result.Fail(err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// ruleFunc is a rule defined by a function (see NewRule)
+type ruleFunc struct {
+	name   string
+	verify func(ctx context.Context, rc *RuleContext) error
+}
+
+// NewRule will return a rule running the verify function
+func NewRule(name string, verify func(ctx context.Context, rc *RuleContext) error) Rule {
+	return &ruleFunc{name: name, verify: verify}
+}
+
+// Name will return the name of the rule
+func (r *ruleFunc) Name() string {
+	return r.name
+}
+
+// Verify will run the verify function of the rule
+func (r *ruleFunc) Verify(ctx context.Context, rc *RuleContext) error {
+	return r.verify(ctx, rc)
+}
+
+// VerifierOps allow functional options to be supplied
+// that overwrite the default rules of the verifier.
+type VerifierOps func(v *Verifier)
+
+// Verifier runs an ordered set of rules on decoded BEEF
+type Verifier struct {
+	provider MerkleRootVerifier
+	rules    []Rule
+}
+
+// NewVerifier will return a verifier with the default rules (see DefaultRules)
+//
+// The provider verifies the merkle roots of the BUMPs
+func NewVerifier(provider MerkleRootVerifier, opts ...VerifierOps) *Verifier {
+	v := &Verifier{
+		provider: provider,
+		rules:    DefaultRules(),
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(v)
+		}
+	}
+	return v
+}
+
+// WithRules will replace the rules of the verifier (the default rules can be included with DefaultRules)
+func WithRules(rules ...Rule) VerifierOps {
+	return func(v *Verifier) {
+		v.rules = rules
+	}
+}
+
+// WithRule will add the rule after the rules of the verifier (e.g. a policy rule for fees or dust)
+func WithRule(rule Rule) VerifierOps {
+	return func(v *Verifier) {
+		v.rules = append(slices.Clone(v.rules), rule)
+	}
+}
+
+// WithoutRules will disable the rules with the names (e.g. RuleNameScripts)
+func WithoutRules(names ...string) VerifierOps {
+	return func(v *Verifier) {
+		v.rules = slices.DeleteFunc(slices.Clone(v.rules), func(rule Rule) bool {
+			return slices.Contains(names, rule.Name())
+		})
+	}
+}
+
+// Rules will return the names of the rules of the verifier (in order)
+func (v *Verifier) Rules() []string {
+	names := make([]string, 0, len(v.rules))
+	for _, rule := range v.rules {
+		names = append(names, rule.Name())
+	}
+	return names
+}
+
+// Verify will run the rules on the decoded BEEF and report the result of every check
+func (v *Verifier) Verify(ctx context.Context, dBeef *beef.DecodedBEEF) *VerificationReport {
+	rc := &RuleContext{
+		BEEF:     dBeef,
+		Provider: v.provider,
+		Report:   newVerificationReport(dBeef),
+		Values:   make(map[string]any),
+	}
+
+	for _, rule := range v.rules {
+		result := &RuleResult{Name: rule.Name(), Valid: true}
+		if err := rule.Verify(ctx, rc); err != nil {
+			result.Valid, result.Error = false, err.Error()
+			rc.Report.fail(err)
+		}
+		rc.Report.Rules = append(rc.Report.Rules, result)
+	}
+
+	rc.Report.Valid = rc.Report.err == nil
+	return rc.Report
+}
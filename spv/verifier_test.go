@@ -0,0 +1,175 @@
+package spv
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/beef"
+	"github.com/bsv-blockchain/go-paymail/errors"
+)
+
+// errTestDust is returned by the test dust rule
+var errTestDust = stderrors.New("dust output")
+
+// newTestDustRule will return a policy rule failing the transactions with outputs below the limit
+func newTestDustRule(limit uint64) Rule {
+	return NewRule("dust", func(_ context.Context, rc *RuleContext) error {
+		return rc.EachTransaction(func(txDt *beef.TxData, _ *TransactionResult) error {
+			for _, output := range txDt.Transaction.Outputs {
+				if output.Satoshis < limit {
+					return errTestDust
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func TestNewVerifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default rules", func(t *testing.T) {
+		verifier := NewVerifier(new(mockServiceProvider))
+
+		require.Equal(t, []string{
+			RuleNameBUMPIndexes,
+			RuleNameDuplicates,
+			RuleNameOrder,
+			RuleNameDoubleSpends,
+			RuleNameTransactionStructure,
+			RuleNameLockTime,
+			RuleNameSatoshis,
+			RuleNameScripts,
+			RuleNameBUMPs,
+			RuleNameMerkleRoots,
+		}, verifier.Rules())
+	})
+
+	t.Run("added and disabled rules", func(t *testing.T) {
+		verifier := NewVerifier(
+			new(mockServiceProvider),
+			WithoutRules(RuleNameScripts, RuleNameLockTime),
+			WithRule(newTestDustRule(1)),
+		)
+
+		require.Equal(t, []string{
+			RuleNameBUMPIndexes,
+			RuleNameDuplicates,
+			RuleNameOrder,
+			RuleNameDoubleSpends,
+			RuleNameTransactionStructure,
+			RuleNameSatoshis,
+			RuleNameBUMPs,
+			RuleNameMerkleRoots,
+			"dust",
+		}, verifier.Rules())
+	})
+
+	t.Run("replaced rules", func(t *testing.T) {
+		verifier := NewVerifier(new(mockServiceProvider), WithRules(newTestDustRule(1)))
+
+		require.Equal(t, []string{"dust"}, verifier.Rules())
+	})
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("custom rule failure", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		verifier := NewVerifier(new(mockServiceProvider), WithRule(newTestDustRule(21e14)))
+
+		// when
+		report := verifier.Verify(context.Background(), dBeef)
+
+		// then
+		require.False(t, report.Valid)
+		require.Equal(t, errTestDust, report.Err())
+		for _, result := range report.Rules[:len(report.Rules)-1] {
+			require.True(t, result.Valid, result.Name)
+		}
+		require.Equal(t, &RuleResult{Name: "dust", Error: errTestDust.Error()}, report.Rules[len(report.Rules)-1])
+		for _, result := range report.Transactions {
+			require.Equal(t, errTestDust.Error(), result.Error)
+		}
+	})
+
+	t.Run("structure failure", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		latest := dBeef.Transactions[len(dBeef.Transactions)-1]
+		dBeef.Transactions = append(dBeef.Transactions, &beef.TxData{Transaction: latest.Transaction})
+		verifier := NewVerifier(new(mockServiceProvider))
+
+		// when
+		report := verifier.Verify(context.Background(), dBeef)
+
+		// then
+		require.False(t, report.Valid)
+		require.ErrorIs(t, report.Err(), errors.ErrDuplicateTransaction)
+		require.ErrorIs(t, report.Err(), beef.ErrBeefDuplicateTransaction)
+		require.Equal(t, RuleNameDuplicates, report.Rules[1].Name)
+		require.False(t, report.Rules[1].Valid)
+		require.False(t, report.Rules[3].Valid, "the outpoints of the duplicate are spent twice")
+		require.Equal(t, report.Err().Error(), report.Transactions[len(report.Transactions)-1].Error)
+	})
+
+	t.Run("disabled rules are not run", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		latest := dBeef.Transactions[len(dBeef.Transactions)-1]
+		latest.Transaction.Outputs[0].Satoshis = 21e14
+		verifier := NewVerifier(new(mockServiceProvider), WithoutRules(RuleNameSatoshis, RuleNameScripts))
+
+		// when
+		report := verifier.Verify(context.Background(), dBeef)
+
+		// then
+		require.True(t, report.Valid)
+		require.Len(t, report.Rules, len(DefaultRules())-2)
+		result := report.Transactions[len(report.Transactions)-1]
+		require.False(t, result.SatoshisBalanced)
+		for _, input := range result.Inputs {
+			require.False(t, input.ScriptValid)
+		}
+	})
+
+	t.Run("rules share the values of the context", func(t *testing.T) {
+		// given
+		dBeef, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		verifier := NewVerifier(
+			new(mockServiceProvider),
+			WithRules(
+				NewRule("count", func(_ context.Context, rc *RuleContext) error {
+					rc.Values["count"] = len(rc.BEEF.Transactions)
+					return nil
+				}),
+				NewRule("max-ancestors", func(_ context.Context, rc *RuleContext) error {
+					if rc.Values["count"].(int) > 2 {
+						return errors.ErrInvalidParentTransactions
+					}
+					return nil
+				}),
+			),
+		)
+
+		// when
+		report := verifier.Verify(context.Background(), dBeef)
+
+		// then
+		require.Greater(t, len(dBeef.Transactions), 2)
+		require.Equal(t, errors.ErrInvalidParentTransactions, report.Err())
+		require.Empty(t, report.MerkleRoots)
+		for _, result := range report.Transactions {
+			require.True(t, result.Valid)
+		}
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -25,6 +28,8 @@ var (
 	ErrSRVWeightMismatch = errors.New("srv weight does not match")
 	// ErrSRVTargetNoHost is returned when SRV target could not resolve a host
 	ErrSRVTargetNoHost = errors.New("srv target could not resolve a host")
+	// ErrSRVServiceUnavailable is returned when the SRV record says the service is not available (target of ".")
+	ErrSRVServiceUnavailable = errors.New("srv service is not available for the domain")
 )
 
 // defaultResolver will return a custom dns resolver
@@ -47,8 +52,39 @@ func (c *Client) defaultResolver() net.Resolver {
 
 // GetSRVRecord will get the SRV record for a given domain name
 //
+// If several records exist, the first record selected per RFC 2782 is returned (see GetSRVRecords)
+//
 // Specs: http://bsvalias.org/02-01-host-discovery.html
 func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SRV, err error) {
+	return c.GetSRVRecordCtx(context.Background(), service, protocol, domainName)
+}
+
+// GetSRVRecordCtx is the context-aware version of GetSRVRecord
+//
+// If the context is canceled or expires, its error is returned instead of the default SRV record
+func (c *Client) GetSRVRecordCtx(ctx context.Context, service, protocol, domainName string) (srv *net.SRV, err error) {
+	var records []*net.SRV
+	if records, err = c.GetSRVRecordsCtx(ctx, service, protocol, domainName); err != nil {
+		return nil, err
+	}
+	return records[0], nil
+}
+
+// GetSRVRecords will get all SRV records for a given domain name, in the order they should be tried
+//
+// Records are ordered by priority (lowest first) and by weighted random selection within
+// the same priority. If no SRV record exists, the domain and port 443 are returned (per the spec)
+//
+// Specs: http://bsvalias.org/02-01-host-discovery.html & https://www.rfc-editor.org/rfc/rfc2782
+func (c *Client) GetSRVRecords(service, protocol, domainName string) (records []*net.SRV, err error) {
+	return c.GetSRVRecordsCtx(context.Background(), service, protocol, domainName)
+}
+
+// GetSRVRecordsCtx is the context-aware version of GetSRVRecords
+func (c *Client) GetSRVRecordsCtx(ctx context.Context, service, protocol, domainName string) (records []*net.SRV, err error) {
+	ctx, end := c.startSpan(ctx, "GetSRVRecords", "", domainName)
+	defer func() { end(err) }()
+
 	// Invalid parameters?
 	if len(service) == 0 { // Use the default from paymail specs
 		service = DefaultServiceName
@@ -58,30 +94,40 @@ func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SR
 	}
 	if len(domainName) == 0 || len(domainName) > 255 {
 		err = ErrSRVInvalidDomainName
-		return srv, err
+		return nil, err
 	}
 
 	// Force the case
 	protocol = strings.TrimSpace(strings.ToLower(protocol))
 
+	// Use the cached records (if found)
+	cacheKey := srvCacheKey(service, protocol, domainName)
+	if records = c.loadCachedSRV(ctx, cacheKey); records != nil {
+		return orderSRVRecords(records), nil
+	}
+
 	// The computed cname to check against
 	cnameCheck := fmt.Sprintf("_%s._%s.%s.", service, protocol, domainName)
 
-	// Lookup the SRV record
+	// Lookup the SRV records
 	var cname string
-	var records []*net.SRV
 	if cname, records, err = c.resolver.LookupSRV(
-		context.Background(), service, protocol, domainName,
+		ctx, service, protocol, domainName,
 	); err != nil || len(records) == 0 {
+		// Do not fall back if the caller gave up
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
 		// @rohenaz: Paymail spec says if SRV record doesn't exist, assume it is <domain>.<tld> and port of 443
 		err = nil
 		cname = cnameCheck
-		records = append(records, &net.SRV{
+		records = []*net.SRV{{
 			Port:     DefaultPort,
 			Priority: DefaultPriority,
 			Target:   domainName,
 			Weight:   DefaultWeight,
-		})
+		}}
 	}
 
 	// Basic CNAME check (sanity check!)
@@ -90,27 +136,127 @@ func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SR
 			"using: %s and expected: %s: %w",
 			cnameCheck, cname, ErrSRVInvalidCNAME,
 		)
-		return srv, err
+		return nil, err
+	}
+
+	// Copy the records (do not modify the resolver results) and remove any period on the end
+	found := make([]*net.SRV, 0, len(records))
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		srv := *record
+		srv.Target = strings.TrimSuffix(srv.Target, ".")
+		found = append(found, &srv)
+	}
+
+	// A single record with a target of "." means the service is decidedly not available (RFC 2782)
+	if len(found) == 0 || (len(found) == 1 && len(found[0].Target) == 0) {
+		return nil, fmt.Errorf("domain %s: %w", domainName, ErrSRVServiceUnavailable)
+	}
+
+	// Cache the records
+	c.storeCachedSRV(ctx, cacheKey, found)
+
+	return orderSRVRecords(found), nil
+}
+
+// orderSRVRecords will return a copy of the records sorted by priority (lowest first),
+// using a weighted random selection for records with the same priority
+//
+// Specs: https://www.rfc-editor.org/rfc/rfc2782 (Usage rules)
+func orderSRVRecords(records []*net.SRV) []*net.SRV {
+	ordered := make([]*net.SRV, len(records))
+	copy(ordered, records)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority < ordered[j].Priority
+	})
+
+	// Shuffle by weight within each priority
+	for start := 0; start < len(ordered); {
+		end := start + 1
+		for end < len(ordered) && ordered[end].Priority == ordered[start].Priority {
+			end++
+		}
+		shuffleSRVByWeight(ordered[start:end])
+		start = end
+	}
+	return ordered
+}
+
+// shuffleSRVByWeight will order records (of the same priority) by weighted random selection
+//
+// Records with a weight of zero are placed first, so they have a small chance of being selected,
+// and records that all have a weight of zero are shuffled uniformly
+func shuffleSRVByWeight(records []*net.SRV) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Weight == 0 && records[j].Weight != 0
+	})
+
+	sum := 0
+	for _, record := range records {
+		sum += int(record.Weight)
 	}
 
-	// Only return the first record (in case multiple are returned)
-	srv = records[0]
+	for sum > 0 && len(records) > 1 {
+		selected := rand.IntN(sum + 1) //nolint:gosec // G404: selection does not need a secure random source
+		running := 0
+		for i := range records {
+			running += int(records[i].Weight)
+			if running >= selected {
+				records[0], records[i] = records[i], records[0]
+				break
+			}
+		}
+		sum -= int(records[0].Weight)
+		records = records[1:]
+	}
 
-	// Remove any period on the end
-	srv.Target = strings.TrimSuffix(srv.Target, ".")
+	// The remaining records have a weight of zero (RFC 2782): they are selected uniformly
+	rand.Shuffle(len(records), func(i, j int) { //nolint:gosec // G404: selection does not need a secure random source
+		records[i], records[j] = records[j], records[i]
+	})
+}
 
-	return srv, err
+// SRVPolicy is the policy used for validating SRV records
+//
+// Empty lists allow any value, the port must always be non-zero
+type SRVPolicy struct {
+	AllowedPorts      []uint16 // Ports that are accepted (empty is any port)
+	AllowedPriorities []uint16 // Priorities that are accepted (empty is any priority)
+	AllowedWeights    []uint16 // Weights that are accepted (empty is any weight)
+	RequireTargetHost bool     // If enabled, the target must resolve to at least one host
+}
+
+// DefaultSRVPolicy will return the default (permissive) SRV policy
+//
+// Any port, priority and weight is accepted, the target must resolve
+func DefaultSRVPolicy() SRVPolicy {
+	return SRVPolicy{RequireTargetHost: true}
+}
+
+// StrictSRVPolicy will return the SRV policy matching the recommended values from the paymail specs
+//
+// Specs: http://bsvalias.org/02-01-host-discovery.html
+func StrictSRVPolicy() SRVPolicy {
+	return SRVPolicy{
+		AllowedPorts:      []uint16{DefaultPort},
+		AllowedPriorities: []uint16{DefaultPriority},
+		AllowedWeights:    []uint16{DefaultWeight},
+		RequireTargetHost: true,
+	}
 }
 
 // ValidateSRVRecord will check for a valid SRV record for paymail following specifications
 //
+// The port, priority and weight must match exactly (zero values use the spec defaults),
+// see ValidateSRVRecordWithPolicy() for a configurable validation
+//
 // Specs: http://bsvalias.org/02-01-host-discovery.html
 func (c *Client) ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, priority, weight uint16) error {
-	// Check the parameters
-	if srv == nil {
-		return ErrSRVMissing
-	}
-	if port <= 0 { // Use the default(s) from paymail specs
+	// Use the default(s) from paymail specs
+	if port <= 0 {
 		port = uint16(DefaultPort)
 	}
 	if priority <= 0 {
@@ -120,18 +266,45 @@ func (c *Client) ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, prio
 		weight = uint16(DefaultWeight)
 	}
 
+	return c.ValidateSRVRecordWithPolicy(ctx, srv, &SRVPolicy{
+		AllowedPorts:      []uint16{port},
+		AllowedPriorities: []uint16{priority},
+		AllowedWeights:    []uint16{weight},
+		RequireTargetHost: true,
+	})
+}
+
+// ValidateSRVRecordWithPolicy will check the SRV record against the given policy
+//
+// If no policy is given, the client policy is used (see WithSRVPolicy)
+func (c *Client) ValidateSRVRecordWithPolicy(ctx context.Context, srv *net.SRV, policy *SRVPolicy) (err error) {
+	// Check the parameters
+	if srv == nil {
+		return ErrSRVMissing
+	}
+
+	ctx, end := c.startSpan(ctx, "ValidateSRVRecord", "", srv.Target)
+	defer func() { end(err) }()
+
+	if policy == nil {
+		policy = &c.options.srvPolicy
+	}
+
 	// Check the basics of the SRV record
-	if len(srv.Target) == 0 {
+	if len(srv.Target) == 0 || !IsValidHost(srv.Target) {
 		return ErrSRVTargetInvalid
-	} else if srv.Port != port {
-		return fmt.Errorf("srv port %d does not match %d: %w", srv.Port, port, ErrSRVPortMismatch)
-	} else if srv.Priority != priority {
-		return fmt.Errorf("srv priority %d does not match %d: %w", srv.Priority, priority, ErrSRVPriorityMismatch)
-	} else if srv.Weight != weight {
-		return fmt.Errorf("srv weight %d does not match %d: %w", srv.Weight, weight, ErrSRVWeightMismatch)
+	} else if srv.Port == 0 || !srvValueAllowed(srv.Port, policy.AllowedPorts) {
+		return fmt.Errorf("srv port %d does not match %s: %w", srv.Port, formatSRVValues(policy.AllowedPorts), ErrSRVPortMismatch)
+	} else if !srvValueAllowed(srv.Priority, policy.AllowedPriorities) {
+		return fmt.Errorf("srv priority %d does not match %s: %w", srv.Priority, formatSRVValues(policy.AllowedPriorities), ErrSRVPriorityMismatch)
+	} else if !srvValueAllowed(srv.Weight, policy.AllowedWeights) {
+		return fmt.Errorf("srv weight %d does not match %s: %w", srv.Weight, formatSRVValues(policy.AllowedWeights), ErrSRVWeightMismatch)
 	}
 
-	// Test resolving the target
+	// Test resolving the target (IP targets do not need a lookup)
+	if !policy.RequireTargetHost || IsValidIP(srv.Target) {
+		return nil
+	}
 	if addresses, err := c.resolver.LookupHost(ctx, srv.Target); err != nil {
 		return err
 	} else if len(addresses) == 0 {
@@ -140,3 +313,20 @@ func (c *Client) ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, prio
 
 	return nil
 }
+
+// srvValueAllowed will return true if the value is in the list (or the list is empty)
+func srvValueAllowed(value uint16, allowed []uint16) bool {
+	return len(allowed) == 0 || slices.Contains(allowed, value)
+}
+
+// formatSRVValues will format the allowed values for error messages
+func formatSRVValues(allowed []uint16) string {
+	switch len(allowed) {
+	case 0:
+		return "any"
+	case 1:
+		return fmt.Sprintf("%d", allowed[0])
+	default:
+		return fmt.Sprintf("%v", allowed)
+	}
+}
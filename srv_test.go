@@ -8,8 +8,28 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/tester"
 )
 
+// newTestSRVClient will return a client resolving the given SRV records for testDomain
+func newTestSRVClient(t *testing.T, records []*net.SRV, opts ...ClientOps) ClientInterface {
+	client := newTestClient(t, opts...)
+	_ = client.WithCustomResolver(tester.NewCustomResolver(
+		client.GetResolver(),
+		map[string][]string{
+			"primary." + testDomain: {"44.225.125.175"},
+			"backup." + testDomain:  {"44.225.125.176"},
+			"empty." + testDomain:   {},
+		},
+		map[string][]*net.SRV{
+			DefaultServiceName + DefaultProtocol + testDomain: records,
+		},
+		nil,
+	))
+	return client
+}
+
 // TestClient_GetSRVRecord will test the method GetSRVRecord()
 func TestClient_GetSRVRecord(t *testing.T) {
 	// t.Parallel() (turned off - race condition)
@@ -90,6 +110,15 @@ func TestClient_GetSRVRecord(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("canceled context does not fall back", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		srv, err := client.GetSRVRecordCtx(ctx, DefaultServiceName, DefaultProtocol, "unknown-domain.com")
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, srv)
+	})
 }
 
 // ExampleClient_GetSRVRecord example using GetSRVRecord()
@@ -110,6 +139,243 @@ func BenchmarkClient_GetSRVRecord(b *testing.B) {
 	}
 }
 
+// TestClient_GetSRVRecords will test the method GetSRVRecords()
+func TestClient_GetSRVRecords(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	t.Run("ordered by priority", func(t *testing.T) {
+		client := newTestSRVClient(t, []*net.SRV{
+			{Target: "backup." + testDomain + ".", Port: 8443, Priority: 20, Weight: 10},
+			{Target: "primary." + testDomain + ".", Port: 443, Priority: 0, Weight: 0},
+		})
+
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "primary."+testDomain, records[0].Target)
+		assert.Equal(t, "backup."+testDomain, records[1].Target)
+		assert.Equal(t, uint16(8443), records[1].Port)
+
+		// The first record is returned by GetSRVRecord()
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "primary."+testDomain, srv.Target)
+	})
+
+	t.Run("resolver records are not modified", func(t *testing.T) {
+		records := []*net.SRV{{Target: "primary." + testDomain + ".", Port: 443, Priority: 10, Weight: 10}}
+		client := newTestSRVClient(t, records)
+
+		found, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "primary."+testDomain, found[0].Target)
+		assert.Equal(t, "primary."+testDomain+".", records[0].Target)
+	})
+
+	t.Run("weighted random selection within a priority", func(t *testing.T) {
+		client := newTestSRVClient(t, []*net.SRV{
+			{Target: "primary." + testDomain, Port: 443, Priority: 10, Weight: 90},
+			{Target: "backup." + testDomain, Port: 443, Priority: 10, Weight: 10},
+		})
+
+		var primary int
+		const rounds = 2000
+		for i := 0; i < rounds; i++ {
+			records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			if records[0].Target == "primary."+testDomain {
+				primary++
+			}
+		}
+
+		// Expect ~90% (very wide bounds to avoid flaky results)
+		assert.Greater(t, primary, rounds*75/100)
+		assert.Less(t, primary, rounds*99/100)
+	})
+
+	t.Run("service not available", func(t *testing.T) {
+		client := newTestSRVClient(t, []*net.SRV{{Target: ".", Port: 0, Priority: 0, Weight: 0}})
+
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+		require.ErrorIs(t, err, ErrSRVServiceUnavailable)
+		require.Nil(t, records)
+	})
+
+	t.Run("fallback without records", func(t *testing.T) {
+		client := newTestClient(t)
+
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, "norecords.com")
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, "norecords.com", records[0].Target)
+		assert.Equal(t, uint16(DefaultPort), records[0].Port)
+	})
+
+	t.Run("invalid domain", func(t *testing.T) {
+		client := newTestClient(t)
+
+		records, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, "")
+		require.ErrorIs(t, err, ErrSRVInvalidDomainName)
+		require.Nil(t, records)
+	})
+}
+
+// Test_orderSRVRecords will test the method orderSRVRecords()
+func Test_orderSRVRecords(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty records", func(t *testing.T) {
+		assert.Empty(t, orderSRVRecords(nil))
+	})
+
+	t.Run("priorities are respected", func(t *testing.T) {
+		records := []*net.SRV{
+			{Target: "c", Priority: 30, Weight: 5},
+			{Target: "a1", Priority: 10, Weight: 5},
+			{Target: "b", Priority: 20, Weight: 0},
+			{Target: "a2", Priority: 10, Weight: 5},
+		}
+		ordered := orderSRVRecords(records)
+		require.Len(t, ordered, 4)
+		assert.ElementsMatch(t, []string{"a1", "a2"}, []string{ordered[0].Target, ordered[1].Target})
+		assert.Equal(t, "b", ordered[2].Target)
+		assert.Equal(t, "c", ordered[3].Target)
+
+		// Original order is not changed
+		assert.Equal(t, "c", records[0].Target)
+	})
+
+	t.Run("zero weights are shuffled uniformly", func(t *testing.T) {
+		records := []*net.SRV{
+			{Target: "a", Priority: 10},
+			{Target: "b", Priority: 10},
+		}
+		first := 0
+		for range 1000 {
+			ordered := orderSRVRecords(records)
+			require.Len(t, ordered, 2)
+			if ordered[0].Target == "a" {
+				first++
+			}
+		}
+		assert.InDelta(t, 500, first, 100)
+	})
+}
+
+// BenchmarkClient_GetSRVRecords benchmarks the method GetSRVRecords()
+func BenchmarkClient_GetSRVRecords(b *testing.B) {
+	client := newTestSRVClient(nil, []*net.SRV{
+		{Target: "primary." + testDomain, Port: 443, Priority: 10, Weight: 60},
+		{Target: "backup." + testDomain, Port: 443, Priority: 10, Weight: 40},
+		{Target: "empty." + testDomain, Port: 443, Priority: 20, Weight: 10},
+	})
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+	}
+}
+
+// TestClient_ValidateSRVRecordWithPolicy will test the method ValidateSRVRecordWithPolicy()
+func TestClient_ValidateSRVRecordWithPolicy(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestSRVClient(t, nil)
+
+	t.Run("default policy accepts any values", func(t *testing.T) {
+		policy := DefaultSRVPolicy()
+		err := client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "primary." + testDomain, Port: 8443, Priority: 0, Weight: 0,
+		}, &policy)
+		require.NoError(t, err)
+	})
+
+	t.Run("client policy is used by default", func(t *testing.T) {
+		err := client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "primary." + testDomain, Port: 8443, Priority: 5, Weight: 50,
+		}, nil)
+		require.NoError(t, err)
+
+		strictClient := newTestSRVClient(t, nil, WithSRVPolicy(StrictSRVPolicy()))
+		err = strictClient.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "primary." + testDomain, Port: 8443, Priority: 5, Weight: 50,
+		}, nil)
+		require.ErrorIs(t, err, ErrSRVPortMismatch)
+	})
+
+	t.Run("strict policy", func(t *testing.T) {
+		policy := StrictSRVPolicy()
+		tests := []struct {
+			name          string
+			srv           *net.SRV
+			expectedError error
+		}{
+			{"valid", &net.SRV{Target: "primary." + testDomain, Port: 443, Priority: 10, Weight: 10}, nil},
+			{"invalid port", &net.SRV{Target: "primary." + testDomain, Port: 8443, Priority: 10, Weight: 10}, ErrSRVPortMismatch},
+			{"invalid priority", &net.SRV{Target: "primary." + testDomain, Port: 443, Priority: 1, Weight: 10}, ErrSRVPriorityMismatch},
+			{"invalid weight", &net.SRV{Target: "primary." + testDomain, Port: 443, Priority: 10, Weight: 1}, ErrSRVWeightMismatch},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				err := client.ValidateSRVRecordWithPolicy(context.Background(), test.srv, &policy)
+				if test.expectedError == nil {
+					require.NoError(t, err)
+				} else {
+					require.ErrorIs(t, err, test.expectedError)
+				}
+			})
+		}
+	})
+
+	t.Run("multiple allowed values", func(t *testing.T) {
+		policy := &SRVPolicy{AllowedPorts: []uint16{443, 8443}}
+		err := client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "primary." + testDomain, Port: 8443,
+		}, policy)
+		require.NoError(t, err)
+
+		err = client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "primary." + testDomain, Port: 80,
+		}, policy)
+		require.ErrorIs(t, err, ErrSRVPortMismatch)
+		assert.Contains(t, err.Error(), "srv port 80 does not match [443 8443]")
+	})
+
+	t.Run("invalid records", func(t *testing.T) {
+		policy := DefaultSRVPolicy()
+
+		err := client.ValidateSRVRecordWithPolicy(context.Background(), nil, &policy)
+		require.ErrorIs(t, err, ErrSRVMissing)
+
+		err = client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{Target: "", Port: 443}, &policy)
+		require.ErrorIs(t, err, ErrSRVTargetInvalid)
+
+		err = client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{Target: "bad target", Port: 443}, &policy)
+		require.ErrorIs(t, err, ErrSRVTargetInvalid)
+
+		err = client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{Target: "primary." + testDomain, Port: 0}, &policy)
+		require.ErrorIs(t, err, ErrSRVPortMismatch)
+
+		err = client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{Target: "empty." + testDomain, Port: 443}, &policy)
+		require.ErrorIs(t, err, ErrSRVTargetNoHost)
+	})
+
+	t.Run("target lookup is optional", func(t *testing.T) {
+		err := client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "empty." + testDomain, Port: 443,
+		}, &SRVPolicy{RequireTargetHost: false})
+		require.NoError(t, err)
+	})
+
+	t.Run("ip targets do not need a lookup", func(t *testing.T) {
+		policy := DefaultSRVPolicy()
+		err := client.ValidateSRVRecordWithPolicy(context.Background(), &net.SRV{
+			Target: "127.0.0.1", Port: 8443,
+		}, &policy)
+		require.NoError(t, err)
+	})
+}
+
 // TestClient_ValidateSRVRecord will test the method ValidateSRVRecord()
 func TestClient_ValidateSRVRecord(t *testing.T) {
 	// t.Parallel() (turned off - race condition)
@@ -11,10 +11,19 @@ import (
 // CheckSSL will do a basic check on the host to see if there is a valid SSL cert
 //
 // All paymail requests should be via HTTPS and have a valid certificate
+// (use CheckTLS for a detailed report, on the port of the SRV record)
 func (c *Client) CheckSSL(host string) (valid bool, err error) {
+	return c.CheckSSLCtx(context.Background(), host)
+}
+
+// CheckSSLCtx is the context-aware version of CheckSSL
+func (c *Client) CheckSSLCtx(ctx context.Context, host string) (valid bool, err error) {
+	ctx, end := c.startSpan(ctx, "CheckSSL", "", host)
+	defer func() { end(err) }()
+
 	// Lookup the host
 	var ips []net.IPAddr
-	if ips, err = c.resolver.LookupIPAddr(context.Background(), host); err != nil {
+	if ips, err = c.resolver.LookupIPAddr(ctx, host); err != nil {
 		return valid, err
 	}
 
@@ -22,6 +31,11 @@ func (c *Client) CheckSSL(host string) (valid bool, err error) {
 	if len(ips) > 0 {
 		for _, ip := range ips {
 
+			// Stop if the context is done
+			if err = ctx.Err(); err != nil {
+				return false, err
+			}
+
 			// Set the dialer
 			dialer := &tls.Dialer{
 				NetDialer: &net.Dialer{
@@ -29,13 +43,13 @@ func (c *Client) CheckSSL(host string) (valid bool, err error) {
 					Deadline: time.Now().Add(c.options.sslDeadline),
 				},
 				Config: &tls.Config{
+					RootCAs:    c.options.tlsRootCAs,
 					ServerName: host,
 					MinVersion: tls.VersionTLS12,
 				},
 			}
 
 			// Set the connection
-			ctx := context.Background()
 			conn, dialErr := dialer.DialContext(ctx, DefaultProtocol, fmt.Sprintf("[%s]:%d", ip.String(), DefaultPort))
 			if dialErr != nil {
 				// catch missing ipv6 connectivity
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -53,6 +54,22 @@ func TestClient_CheckSSL(t *testing.T) {
 	})
 }
 
+// TestClient_CheckSSLCtx will test the method CheckSSLCtx()
+func TestClient_CheckSSLCtx(t *testing.T) {
+	// t.Parallel() cannot use newTestClient() race condition
+
+	client := newTestClient(t)
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		valid, err := client.CheckSSLCtx(ctx, "example.com")
+		require.ErrorIs(t, err, context.Canceled)
+		assert.False(t, valid)
+	})
+}
+
 // ExampleClient_CheckSSL example using CheckSSL()
 //
 // See more examples in /examples/
@@ -0,0 +1,140 @@
+package paymail
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Telemetry attribute keys
+const (
+	AttributeBRFC       = attribute.Key("paymail.brfc")              // The BRFC id of the capability
+	AttributeDomain     = attribute.Key("paymail.domain")            // The paymail domain (or host)
+	AttributeOperation  = attribute.Key("paymail.operation")         // The operation (GetPKI on the client, the capability key on the server)
+	AttributeResult     = attribute.Key("paymail.result")            // The result of the operation (ok or error)
+	AttributeStatusCode = attribute.Key("http.response.status_code") // The HTTP status code of the response
+)
+
+// Telemetry results
+const (
+	ResultError = "error"
+	ResultOK    = "ok"
+)
+
+// InstrumentationName is the name of the OpenTelemetry tracer and meter
+const InstrumentationName = "github.com/bsv-blockchain/go-paymail"
+
+// telemetry holds the OpenTelemetry tracer and instruments for the client
+type telemetry struct {
+	duration metric.Float64Histogram
+	meter    metric.Meter
+	requests metric.Int64Counter
+	tracer   trace.Tracer
+}
+
+// newTelemetry will create the tracer and instruments (using the global providers if not set)
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) (*telemetry, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	t := &telemetry{
+		meter:  meterProvider.Meter(InstrumentationName, metric.WithInstrumentationVersion(version)),
+		tracer: tracerProvider.Tracer(InstrumentationName, trace.WithInstrumentationVersion(version)),
+	}
+
+	var err error
+	if t.requests, err = t.meter.Int64Counter(
+		"paymail.client.requests",
+		metric.WithDescription("Number of paymail client operations"),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		return nil, err
+	}
+	if t.duration, err = t.meter.Float64Histogram(
+		"paymail.client.duration",
+		metric.WithDescription("Duration of paymail client operations"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// observeCircuits will report the circuit breaker state of every host (0 closed, 1 open, 2 half-open)
+func (t *telemetry) observeCircuits(breaker *circuitBreaker) error {
+	_, err := t.meter.Int64ObservableGauge(
+		"paymail.client.circuit.state",
+		metric.WithDescription("Circuit breaker state per host (0 closed, 1 open, 2 half-open)"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			for host, state := range breaker.states() {
+				observer.Observe(int64(state), metric.WithAttributes(AttributeDomain.String(host)))
+			}
+			return nil
+		}),
+	)
+	return err
+}
+
+// startSpan will start the span for a client operation
+//
+// The returned function must be called with the result of the operation, it ends the span
+// and records the metrics. The domain is only set on the span (the metrics would be unbounded).
+func (c *Client) startSpan(ctx context.Context, operation, brfc, domain string) (context.Context, func(err error)) {
+	attributes := []attribute.KeyValue{AttributeOperation.String(operation), AttributeBRFC.String(brfc)}
+
+	start := time.Now()
+	ctx, span := c.telemetry.tracer.Start(ctx, "paymail.client."+operation,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	if len(domain) > 0 {
+		span.SetAttributes(AttributeDomain.String(domain))
+	}
+
+	return ctx, func(err error) {
+		result := ResultOK
+		if err != nil {
+			result = ResultError
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.SetAttributes(AttributeResult.String(result))
+		span.End()
+
+		measurement := metric.WithAttributes(append(attributes, AttributeResult.String(result))...)
+		c.telemetry.requests.Add(ctx, 1, measurement)
+		c.telemetry.duration.Record(ctx, time.Since(start).Seconds(), measurement)
+	}
+}
+
+// traceRequest is the interceptor that creates a span for every HTTP request
+func (c *Client) traceRequest(ctx context.Context, req *Request, next RequestHandler) (StandardResponse, error) {
+	ctx, span := c.telemetry.tracer.Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL),
+		),
+	)
+	defer span.End()
+
+	response, err := next(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return response, err
+	}
+
+	span.SetAttributes(AttributeStatusCode.Int(response.StatusCode))
+	if response.StatusCode >= 400 {
+		span.SetStatus(codes.Error, "")
+	}
+	return response, err
+}
@@ -0,0 +1,194 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTelemetry will return the in-memory span exporter and metric reader (and the client options to use them)
+func newTestTelemetry() (*tracetest.InMemoryExporter, *sdkmetric.ManualReader, []ClientOps) {
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	return exporter, reader, []ClientOps{
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	}
+}
+
+// findTestSpan will return the exported span with the given name
+func findTestSpan(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no span named %s", name)
+	return tracetest.SpanStub{}
+}
+
+// spanAttribute will return the value of the span attribute
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// collectTestMetrics will return the data points of the counter with the given name
+func collectTestMetrics(t *testing.T, reader *sdkmetric.ManualReader, name string) []metricdata.DataPoint[int64] {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				require.True(t, ok)
+				return sum.DataPoints
+			}
+		}
+	}
+	return nil
+}
+
+// TestClient_Telemetry will test the spans and metrics set with WithTracerProvider() and WithMeterProvider()
+func TestClient_Telemetry(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("capability call", func(t *testing.T) {
+		exporter, reader, opts := newTestTelemetry()
+		client := newTestClient(t, opts...)
+
+		mockGetPKI(http.StatusOK)
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.NoError(t, err)
+
+		span := findTestSpan(t, exporter, "paymail.client.GetPKI")
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+		assert.Equal(t, BRFCPki, spanAttribute(span, AttributeBRFC).AsString())
+		assert.Equal(t, testDomain, spanAttribute(span, AttributeDomain).AsString())
+		assert.Equal(t, ResultOK, spanAttribute(span, AttributeResult).AsString())
+		assert.Equal(t, codes.Unset, span.Status.Code)
+
+		httpSpan := findTestSpan(t, exporter, "HTTP GET")
+		assert.Equal(t, span.SpanContext.SpanID(), httpSpan.Parent.SpanID())
+		assert.Equal(t, int64(http.StatusOK), spanAttribute(httpSpan, AttributeStatusCode).AsInt64())
+
+		points := collectTestMetrics(t, reader, "paymail.client.requests")
+		require.Len(t, points, 1)
+		assert.Equal(t, int64(1), points[0].Value)
+		result, _ := points[0].Attributes.Value(AttributeResult)
+		assert.Equal(t, ResultOK, result.AsString())
+		_, hasDomain := points[0].Attributes.Value(AttributeDomain)
+		assert.False(t, hasDomain)
+	})
+
+	t.Run("capability error", func(t *testing.T) {
+		exporter, reader, opts := newTestTelemetry()
+		client := newTestClient(t, opts...)
+
+		mockGetPKI(http.StatusNotFound)
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.Error(t, err)
+
+		span := findTestSpan(t, exporter, "paymail.client.GetPKI")
+		assert.Equal(t, ResultError, spanAttribute(span, AttributeResult).AsString())
+		assert.Equal(t, codes.Error, span.Status.Code)
+		require.Len(t, span.Events, 1)
+		assert.Equal(t, "exception", span.Events[0].Name)
+
+		httpSpan := findTestSpan(t, exporter, "HTTP GET")
+		assert.Equal(t, int64(http.StatusNotFound), spanAttribute(httpSpan, AttributeStatusCode).AsInt64())
+		assert.Equal(t, codes.Error, httpSpan.Status.Code)
+
+		points := collectTestMetrics(t, reader, "paymail.client.requests")
+		require.Len(t, points, 1)
+		result, _ := points[0].Attributes.Value(AttributeResult)
+		assert.Equal(t, ResultError, result.AsString())
+	})
+
+	t.Run("srv lookup", func(t *testing.T) {
+		exporter, _, opts := newTestTelemetry()
+		client := newTestClient(t, opts...)
+
+		_, err := client.GetSRVRecords(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+
+		span := findTestSpan(t, exporter, "paymail.client.GetSRVRecords")
+		assert.Equal(t, testDomain, spanAttribute(span, AttributeDomain).AsString())
+		assert.Equal(t, ResultOK, spanAttribute(span, AttributeResult).AsString())
+	})
+
+	t.Run("spans are children of the caller span", func(t *testing.T) {
+		exporter, _, opts := newTestTelemetry()
+		client := newTestClient(t, opts...)
+
+		mockGetPKI(http.StatusOK)
+
+		ctx, parent := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "parent")
+		_, err := client.GetPKICtx(ctx, testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		parent.End()
+		require.NoError(t, err)
+
+		span := findTestSpan(t, exporter, "paymail.client.GetPKI")
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	})
+
+	t.Run("default global providers", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockGetPKI(http.StatusOK)
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.NoError(t, err)
+	})
+}
+
+// ExampleWithTracerProvider example using WithTracerProvider()
+//
+// See more examples in /examples/
+func ExampleWithTracerProvider() {
+	// Export the spans (use your own exporter, IE: OTLP)
+	exporter := tracetest.NewInMemoryExporter()
+
+	// Load the client
+	client := newTestClient(nil, WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))))
+
+	mockGetPKI(http.StatusOK)
+
+	// Get the PKI
+	_, _ = client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	for _, span := range exporter.GetSpans() {
+		fmt.Println(span.Name)
+	}
+	// Output:HTTP GET
+	// paymail.client.GetPKI
+}
+
+// BenchmarkClient_Telemetry benchmarks a request with tracing and metrics enabled
+func BenchmarkClient_Telemetry(b *testing.B) {
+	client := newTestClient(nil,
+		WithTracerProvider(sdktrace.NewTracerProvider()),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewManualReader()))),
+	)
+	mockGetPKI(http.StatusOK)
+	for i := 0; i < b.N; i++ {
+		_, _ = client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+	}
+}
@@ -0,0 +1,283 @@
+// Package fakeprovider is an in-process paymail provider for end-to-end tests
+//
+// The provider runs the real server package behind an httptest TLS server, and registers
+// the matching SRV and host records in a fake resolver. Clients created with NewClient()
+// resolve and pay the provider's paymail addresses without mocking any url.
+//
+// It lives in its own package (and not in tester) because it imports the server package,
+// which would create an import cycle for the go-paymail package tests.
+package fakeprovider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/server"
+	"github.com/bsv-blockchain/go-paymail/spv"
+	"github.com/bsv-blockchain/go-paymail/tester"
+)
+
+// DefaultDomain is the paymail domain served by the provider (if not set with WithDomain)
+const DefaultDomain = "paymail.test"
+
+// targetHost is the SRV target of the provider (the httptest certificate is valid for this address)
+const targetHost = "127.0.0.1"
+
+// ErrAccountExists is when an account is added twice
+var ErrAccountExists = errors.New("account already exists")
+
+// MerkleRootVerifier verifies the merkle roots of the BEEF transactions received by the provider
+type MerkleRootVerifier func(ctx context.Context, merkleRoots []*spv.MerkleRootConfirmationRequestItem) error
+
+// Account is a paymail account served by the provider
+type Account struct {
+	Alias      string         // Alias of the paymail (alias@)
+	Name       string         // Name of the user (public profile)
+	PrivateKey *ec.PrivateKey // Key used for the PKI, the destinations and signing
+}
+
+// Payment is a transaction received by the provider
+type Payment struct {
+	Alias     string               // Alias of the receiving account
+	Beef      string               // The BEEF (if received with the BEEF capability)
+	Hex       string               // The raw transaction
+	MetaData  *paymail.P2PMetaData // Sender information and note
+	Reference string               // Reference from the P2P payment destination (or PIKE outputs)
+	TxID      string               // The transaction id
+}
+
+// ContactRequest is a PIKE contact request received by the provider
+type ContactRequest struct {
+	Contact  *paymail.PikeContactRequestPayload // The requesting contact
+	Receiver string                             // Paymail of the account the request was sent to
+}
+
+// ProviderOps allow functional options to be supplied
+// that overwrite default provider options.
+type ProviderOps func(p *Provider)
+
+// Provider is an in-process paymail provider (served over TLS)
+type Provider struct {
+	accounts      map[string]*Account
+	contacts      []*ContactRequest
+	domain        string
+	httpServer    *httptest.Server
+	logger        *zerolog.Logger
+	mu            sync.Mutex
+	payments      []*Payment
+	references    map[string]string // Reference => alias
+	serverOptions []server.ConfigOps
+	verifier      MerkleRootVerifier
+}
+
+// WithDomain will set the paymail domain served by the provider.
+// Default is DefaultDomain.
+func WithDomain(domain string) ProviderOps {
+	return func(p *Provider) {
+		if len(domain) > 0 {
+			p.domain = strings.ToLower(domain)
+		}
+	}
+}
+
+// WithAccount will add an account with a new random key.
+func WithAccount(alias, name string) ProviderOps {
+	return WithAccountKey(alias, name, nil)
+}
+
+// WithAccountKey will add an account using the given key (a new random key if nil).
+func WithAccountKey(alias, name string, key *ec.PrivateKey) ProviderOps {
+	return func(p *Provider) {
+		_ = p.AddAccount(alias, name, key) // Duplicates are ignored
+	}
+}
+
+// WithServerOptions will add options for the paymail server configuration.
+// By default, all the capabilities (generic, P2P, BEEF and PIKE) are enabled.
+func WithServerOptions(opts ...server.ConfigOps) ProviderOps {
+	return func(p *Provider) {
+		p.serverOptions = append(p.serverOptions, opts...)
+	}
+}
+
+// WithMerkleRootVerifier will set the verifier for the merkle roots of received BEEF transactions.
+// Default is accepting all merkle roots.
+func WithMerkleRootVerifier(verifier MerkleRootVerifier) ProviderOps {
+	return func(p *Provider) {
+		p.verifier = verifier
+	}
+}
+
+// WithLogger will set the logger for the paymail server.
+// Default is discarding all logs.
+func WithLogger(logger *zerolog.Logger) ProviderOps {
+	return func(p *Provider) {
+		p.logger = logger
+	}
+}
+
+// New will start a new provider (the provider must be closed with Close())
+func New(opts ...ProviderOps) (*Provider, error) {
+	logger := zerolog.New(io.Discard)
+	p := &Provider{
+		accounts:   make(map[string]*Account),
+		domain:     DefaultDomain,
+		logger:     &logger,
+		references: make(map[string]string),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+
+	// Start the TLS server (the handlers are set once the configuration is loaded)
+	var handler http.Handler
+	p.httpServer = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	p.httpServer.StartTLS()
+
+	// The server uses a client of the provider (IE: sender PKI lookups)
+	client, err := p.NewClient()
+	if err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	// Load the paymail server
+	locator := &server.PaymailServiceLocator{}
+	service := &serviceProvider{provider: p}
+	locator.RegisterPaymailService(service)
+	locator.RegisterPikeContactService(service)
+	locator.RegisterPikePaymentService(service)
+
+	var config *server.Configuration
+	if config, err = server.NewConfig(locator, append([]server.ConfigOps{
+		server.WithDomain(p.domain),
+		server.WithDomain(targetHost),
+		server.WithP2PCapabilities(),
+		server.WithBeefCapabilities(),
+		server.WithPikeContactCapabilities(),
+		server.WithPikePaymentCapabilities(),
+		server.WithLogger(p.logger),
+		server.WithPaymailClient(client),
+	}, p.serverOptions...)...); err != nil {
+		p.Close()
+		return nil, err
+	}
+	handler = server.Handlers(config)
+
+	return p, nil
+}
+
+// Close will shut down the provider
+func (p *Provider) Close() {
+	p.httpServer.Close()
+}
+
+// Domain will return the paymail domain served by the provider
+func (p *Provider) Domain() string {
+	return p.domain
+}
+
+// URL will return the base url of the provider (https://127.0.0.1:port)
+func (p *Provider) URL() string {
+	return p.httpServer.URL
+}
+
+// Port will return the port of the provider
+func (p *Provider) Port() int {
+	_, port, _ := net.SplitHostPort(p.httpServer.Listener.Addr().String())
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// Address will return the paymail address for the alias (alias@domain)
+func (p *Provider) Address(alias string) string {
+	return strings.ToLower(alias) + "@" + p.domain
+}
+
+// AddAccount will add an account using the given key (a new random key if nil)
+func (p *Provider) AddAccount(alias, name string, key *ec.PrivateKey) (err error) {
+	if key == nil {
+		if key, err = ec.NewPrivateKey(); err != nil {
+			return err
+		}
+	}
+	alias = strings.ToLower(alias)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.accounts[alias]; ok {
+		return ErrAccountExists
+	}
+
+	p.accounts[alias] = &Account{Alias: alias, Name: name, PrivateKey: key}
+	return nil
+}
+
+// Account will return the account for the alias (nil if not found)
+func (p *Provider) Account(alias string) *Account {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.accounts[strings.ToLower(alias)]
+}
+
+// Payments will return all the transactions received by the provider (in order)
+func (p *Provider) Payments() []*Payment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Payment(nil), p.payments...)
+}
+
+// ContactRequests will return all the PIKE contact requests received by the provider (in order)
+func (p *Provider) ContactRequests() []*ContactRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*ContactRequest(nil), p.contacts...)
+}
+
+// RegisterRecords will add the SRV and host records of the provider to the resolver
+func (p *Provider) RegisterRecords(resolver *tester.Resolver) {
+	resolver.AddSRV(paymail.DefaultServiceName, paymail.DefaultProtocol, p.domain, &net.SRV{
+		Port:     uint16(p.Port()), //nolint:gosec // G115: the port of a listener always fits
+		Priority: paymail.DefaultPriority,
+		Target:   targetHost + ".",
+		Weight:   paymail.DefaultWeight,
+	})
+	resolver.AddHost(targetHost, targetHost)
+}
+
+// Resolver will return a resolver with the records of the provider (other lookups are not found)
+func (p *Provider) Resolver() *tester.Resolver {
+	resolver := &tester.Resolver{}
+	p.RegisterRecords(resolver)
+	return resolver
+}
+
+// HTTPClient will return a Resty client that trusts the certificate of the provider
+func (p *Provider) HTTPClient() *resty.Client {
+	return resty.NewWithClient(p.httpServer.Client())
+}
+
+// NewClient will return a paymail client using the resolver and the HTTP client of the provider
+func (p *Provider) NewClient(opts ...paymail.ClientOps) (paymail.ClientInterface, error) {
+	client, err := paymail.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return client.WithCustomHTTPClient(p.HTTPClient()).WithCustomResolver(p.Resolver()), nil
+}
@@ -0,0 +1,320 @@
+package fakeprovider
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/server"
+	"github.com/bsv-blockchain/go-paymail/spv"
+)
+
+// testSourceTxID is the (fake) parent of the test source transactions
+const testSourceTxID = "e6ac5fa4e7f8e3e8d52d2b1a1d1bd9df4b0e4e49bbf5e0e3fd1fc5b2b4b6b0c1"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode) // Quiet the route debug logs
+	os.Exit(m.Run())
+}
+
+// newTestProvider will start a provider with the alice and bob accounts
+func newTestProvider(t *testing.T, opts ...ProviderOps) *Provider {
+	p, err := New(append([]ProviderOps{WithAccount("alice", "Alice"), WithAccount("bob", "Bob")}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// testUTXOSource is a UTXOSource returning a single utxo
+type testUTXOSource struct {
+	utxo *paymail.PayerUTXO
+}
+
+// UTXOs will return the utxo
+func (s *testUTXOSource) UTXOs(_ context.Context, _ uint64) ([]*paymail.PayerUTXO, error) {
+	return []*paymail.PayerUTXO{s.utxo}, nil
+}
+
+// testChangeProvider is a ChangeProvider returning a fixed script
+type testChangeProvider struct {
+	changeScript *script.Script
+}
+
+// ChangeScript will return the script
+func (p *testChangeProvider) ChangeScript(_ context.Context) (*script.Script, error) {
+	return p.changeScript, nil
+}
+
+// newTestPayer will return a payer spending a utxo of the account (with a mined source transaction if mined)
+func newTestPayer(t *testing.T, p *Provider, client paymail.ClientInterface, alias string, mined bool) *paymail.Payer {
+	lockingScript, err := lockingScript(p.Account(alias))
+	require.NoError(t, err)
+
+	// The source transaction (with a merkle path, so it can be sent as BEEF)
+	sourceTx := transaction.NewTransaction()
+	require.NoError(t, sourceTx.AddInputFrom(testSourceTxID, 0, lockingScript.String(), 10001, nil))
+	sourceTx.Inputs[0].UnlockingScript = &script.Script{}
+	sourceTx.AddOutput(&transaction.TransactionOutput{LockingScript: lockingScript, Satoshis: 10000})
+	if mined {
+		// A block with the source transaction only (the sibling is a duplicate)
+		isTxID, duplicate := true, true
+		sourceTx.MerklePath = &transaction.MerklePath{
+			BlockHeight: 800000,
+			Path: [][]*transaction.PathElement{{
+				{Offset: 0, Hash: sourceTx.TxID(), Txid: &isTxID},
+				{Offset: 1, Duplicate: &duplicate},
+			}},
+		}
+	}
+
+	payer, err := paymail.NewPayer(client,
+		&testUTXOSource{utxo: &paymail.PayerUTXO{
+			LockingScript:     lockingScript,
+			Satoshis:          10000,
+			SourceTransaction: sourceTx,
+			TxID:              sourceTx.TxID().String(),
+		}},
+		&testChangeProvider{changeScript: lockingScript},
+		paymail.NewP2PKHSigner(p.Account(alias).PrivateKey),
+	)
+	require.NoError(t, err)
+	return payer
+}
+
+// TestNew will test the method New()
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	t.Run("defaults", func(t *testing.T) {
+		p := newTestProvider(t)
+		assert.Equal(t, DefaultDomain, p.Domain())
+		assert.Equal(t, "alice@"+DefaultDomain, p.Address("Alice"))
+		assert.NotZero(t, p.Port())
+		assert.Contains(t, p.URL(), "https://127.0.0.1:")
+		assert.NotNil(t, p.Account("ALICE"))
+		assert.Nil(t, p.Account("carol"))
+	})
+
+	t.Run("custom domain", func(t *testing.T) {
+		p := newTestProvider(t, WithDomain("Example.com"))
+		assert.Equal(t, "example.com", p.Domain())
+	})
+
+	t.Run("account key", func(t *testing.T) {
+		key, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		p := newTestProvider(t, WithAccountKey("carol", "Carol", key))
+		assert.Equal(t, key, p.Account("carol").PrivateKey)
+		assert.ErrorIs(t, p.AddAccount("carol", "Carol", nil), ErrAccountExists)
+	})
+
+	t.Run("records", func(t *testing.T) {
+		p := newTestProvider(t)
+		resolver := p.Resolver()
+
+		cname, records, err := resolver.LookupSRV(context.Background(), paymail.DefaultServiceName, paymail.DefaultProtocol, p.Domain())
+		require.NoError(t, err)
+		assert.Equal(t, "_bsvalias._tcp."+DefaultDomain+".", cname)
+		require.Len(t, records, 1)
+		assert.Equal(t, "127.0.0.1.", records[0].Target)
+		assert.Equal(t, p.Port(), int(records[0].Port))
+
+		_, _, err = resolver.LookupSRV(context.Background(), paymail.DefaultServiceName, paymail.DefaultProtocol, "unknown.com")
+		require.Error(t, err)
+	})
+}
+
+// TestProvider_EndToEnd will test the client against the provider (no mocked urls)
+func TestProvider_EndToEnd(t *testing.T) {
+	t.Parallel()
+
+	t.Run("resolve and get the pki", func(t *testing.T) {
+		p := newTestProvider(t)
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		resolver, err := paymail.NewResolver(client)
+		require.NoError(t, err)
+		endpoint, err := resolver.Resolve(context.Background(), p.Address("alice"))
+		require.NoError(t, err)
+		assert.Equal(t, "127.0.0.1", endpoint.Host)
+		assert.NotEmpty(t, endpoint.P2PPaymentDestinationURL())
+		assert.NotEmpty(t, endpoint.BeefTransactionURL())
+		assert.NotEmpty(t, endpoint.PikeInviteURL())
+
+		pki, err := client.GetPKI(endpoint.PKIURL(), "alice", p.Domain())
+		require.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(p.Account("alice").PrivateKey.PubKey().Compressed()), pki.PubKey)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		p := newTestProvider(t)
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		_, err = client.GetPKI(p.URL()+"/v1/bsvalias/id/{alias}@{domain.tld}", "carol", p.Domain())
+		var responseErr *paymail.ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		assert.Equal(t, 400, responseErr.StatusCode)
+	})
+
+	t.Run("pay with beef", func(t *testing.T) {
+		p := newTestProvider(t)
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		result, err := newTestPayer(t, p, client, "bob", true).Pay(context.Background(), &paymail.Payment{
+			Note:         "thanks",
+			Satoshis:     1000,
+			SenderHandle: p.Address("bob"),
+			SenderKey:    p.Account("bob").PrivateKey,
+			To:           p.Address("alice"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, paymail.PaymentMethodBEEF, result.Method)
+
+		payments := p.Payments()
+		require.Len(t, payments, 1)
+		assert.Equal(t, "alice", payments[0].Alias)
+		assert.Equal(t, result.TxID, payments[0].TxID)
+		assert.Equal(t, result.Reference, payments[0].Reference)
+		assert.NotEmpty(t, payments[0].Beef)
+		assert.Equal(t, "thanks", payments[0].MetaData.Note)
+		assert.Equal(t, p.Address("bob"), payments[0].MetaData.Sender)
+	})
+
+	t.Run("pay with hex", func(t *testing.T) {
+		p := newTestProvider(t)
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		result, err := newTestPayer(t, p, client, "bob", false).Pay(context.Background(), &paymail.Payment{
+			Satoshis:     1000,
+			SenderHandle: p.Address("bob"),
+			SenderKey:    p.Account("bob").PrivateKey,
+			To:           p.Address("alice"),
+		})
+		require.NoError(t, err)
+
+		payments := p.Payments()
+		require.Len(t, payments, 1)
+		assert.Equal(t, result.TxID, payments[0].TxID)
+		assert.Empty(t, payments[0].Beef)
+	})
+
+	t.Run("merkle roots are verified", func(t *testing.T) {
+		p := newTestProvider(t, WithMerkleRootVerifier(
+			func(_ context.Context, _ []*spv.MerkleRootConfirmationRequestItem) error {
+				return assert.AnError
+			},
+		))
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		_, err = newTestPayer(t, p, client, "bob", true).Pay(context.Background(), &paymail.Payment{
+			Satoshis:     1000,
+			SenderHandle: p.Address("bob"),
+			SenderKey:    p.Account("bob").PrivateKey,
+			To:           p.Address("alice"),
+		})
+		require.Error(t, err)
+		assert.Empty(t, p.Payments())
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		p := newTestProvider(t)
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		_, err = client.SendP2PTransaction(p.URL()+"/v1/bsvalias/receive-transaction/{alias}@{domain.tld}", "alice", p.Domain(),
+			&paymail.P2PTransaction{Hex: transaction.NewTransaction().Hex(), MetaData: &paymail.P2PMetaData{}, Reference: "unknown"})
+		var responseErr *paymail.ResponseError
+		require.ErrorAs(t, err, &responseErr)
+		assert.Equal(t, ErrUnknownReference.Code, responseErr.Code)
+		assert.Empty(t, p.Payments())
+	})
+
+	t.Run("pike contact request", func(t *testing.T) {
+		p := newTestProvider(t)
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		_, err = client.AddContactRequest(p.URL()+"/v1/bsvalias/contact/invite/{alias}@{domain.tld}", "alice", p.Domain(),
+			&paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: p.Address("bob")})
+		require.NoError(t, err)
+
+		contacts := p.ContactRequests()
+		require.Len(t, contacts, 1)
+		assert.Equal(t, p.Address("alice"), contacts[0].Receiver)
+		assert.Equal(t, p.Address("bob"), contacts[0].Contact.Paymail)
+	})
+
+	t.Run("verified address resolution", func(t *testing.T) {
+		p := newTestProvider(t, WithServerOptions(server.WithSenderValidation()))
+		client, err := p.NewClient(paymail.WithResolutionVerification())
+		require.NoError(t, err)
+
+		resolver, err := paymail.NewResolver(client)
+		require.NoError(t, err)
+		endpoint, err := resolver.Resolve(context.Background(), p.Address("alice"))
+		require.NoError(t, err)
+
+		senderRequest := &paymail.SenderRequest{Dt: time.Now().UTC().Format(time.RFC3339), SenderHandle: p.Address("bob")}
+		sigBytes, err := senderRequest.Sign(hex.EncodeToString(p.Account("bob").PrivateKey.Serialize()))
+		require.NoError(t, err)
+		senderRequest.Signature = paymail.EncodeSignature(sigBytes)
+
+		resolution, err := client.ResolveAddress(endpoint.PaymentDestinationURL(), "alice", p.Domain(), senderRequest)
+		require.NoError(t, err)
+		assert.NotEmpty(t, resolution.Output)
+		assert.NotEmpty(t, resolution.Signature)
+	})
+
+	t.Run("server options", func(t *testing.T) {
+		p := newTestProvider(t, WithServerOptions(server.WithSenderValidation()))
+		client, err := p.NewClient()
+		require.NoError(t, err)
+
+		resolver, err := paymail.NewResolver(client)
+		require.NoError(t, err)
+		endpoint, err := resolver.Resolve(context.Background(), p.Address("alice"))
+		require.NoError(t, err)
+		assert.True(t, endpoint.SenderValidation())
+	})
+}
+
+// ExampleNew example using New()
+func ExampleNew() {
+	// Start the provider (close it when done)
+	p, err := New(WithAccount("alice", "Alice"))
+	if err != nil {
+		fmt.Printf("error starting the provider: %s", err.Error())
+		return
+	}
+	defer p.Close()
+
+	// Load a client that resolves the provider
+	client, _ := p.NewClient()
+	resolver, _ := paymail.NewResolver(client)
+
+	// Resolve the paymail address
+	endpoint, err := resolver.Resolve(context.Background(), p.Address("alice"))
+	if err != nil {
+		fmt.Printf("error resolving: %s", err.Error())
+		return
+	}
+	fmt.Printf("found %s with %d capabilities", endpoint.Address, len(endpoint.Capabilities.Capabilities))
+	// Output:found alice@paymail.test with 9 capabilities
+}
@@ -0,0 +1,199 @@
+package fakeprovider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/template/p2pkh"
+
+	"github.com/bsv-blockchain/go-paymail"
+	"github.com/bsv-blockchain/go-paymail/errors"
+	"github.com/bsv-blockchain/go-paymail/server"
+	"github.com/bsv-blockchain/go-paymail/spv"
+)
+
+// ErrUnknownReference is when a transaction is received with a reference that was not issued by the provider
+var ErrUnknownReference = errors.SPVError{Message: "unknown payment reference", StatusCode: 400, Code: "error-fake-provider-unknown-reference"}
+
+// serviceProvider is the implementation of the server service providers (backed by the provider)
+type serviceProvider struct {
+	provider *Provider
+}
+
+// account will return the account for the paymail (nil if not found)
+func (s *serviceProvider) account(alias, domain string) *Account {
+	if !strings.EqualFold(domain, s.provider.domain) {
+		return nil
+	}
+	return s.provider.Account(alias)
+}
+
+// GetPaymailByAlias will return the account information
+func (s *serviceProvider) GetPaymailByAlias(_ context.Context, alias, domain string,
+	_ *server.RequestMetadata,
+) (*paymail.AddressInformation, error) {
+	a := s.account(alias, domain)
+	if a == nil {
+		return nil, nil //nolint:nilnil // The server returns the not found error
+	}
+	return &paymail.AddressInformation{
+		Alias:  a.Alias,
+		Domain: s.provider.domain,
+		ID:     a.Alias,
+		Name:   a.Name,
+		PubKey: hex.EncodeToString(a.PrivateKey.PubKey().Compressed()),
+	}, nil
+}
+
+// CreateAddressResolutionResponse will return the P2PKH output of the account
+func (s *serviceProvider) CreateAddressResolutionResponse(_ context.Context, alias, domain string,
+	senderValidation bool, _ *server.RequestMetadata,
+) (*paymail.ResolutionPayload, error) {
+	a := s.account(alias, domain)
+	if a == nil {
+		return nil, errors.ErrCouldNotFindPaymail
+	}
+
+	lockingScript, err := lockingScript(a)
+	if err != nil {
+		return nil, err
+	}
+	response := &paymail.ResolutionPayload{Output: lockingScript.String()}
+
+	// Sign the output (the hex string) if sender validation is enabled
+	if senderValidation {
+		var sigBytes []byte
+		if sigBytes, err = bsm.SignMessage(a.PrivateKey, []byte(response.Output)); err != nil {
+			return nil, err
+		}
+		response.Signature = paymail.EncodeSignature(sigBytes)
+	}
+	return response, nil
+}
+
+// CreateP2PDestinationResponse will return the P2PKH output of the account and a new reference
+func (s *serviceProvider) CreateP2PDestinationResponse(_ context.Context, alias, domain string,
+	satoshis uint64, _ *server.RequestMetadata,
+) (*paymail.PaymentDestinationPayload, error) {
+	outputs, reference, err := s.outputs(alias, domain, satoshis)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &paymail.PaymentDestinationPayload{Reference: reference}
+	for _, output := range outputs {
+		response.Outputs = append(response.Outputs, &paymail.PaymentOutput{Satoshis: output.Satoshis, Script: output.Script})
+	}
+	return response, nil
+}
+
+// RecordTransaction will record the payment (the reference must be issued by the provider)
+func (s *serviceProvider) RecordTransaction(_ context.Context,
+	p2pTx *paymail.P2PTransaction, metaData *server.RequestMetadata,
+) (*paymail.P2PTransactionPayload, error) {
+	tx, err := transaction.NewTransactionFromHex(p2pTx.Hex)
+	if err != nil {
+		return nil, errors.ErrProcessingHex
+	}
+
+	p := s.provider
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if alias, ok := p.references[p2pTx.Reference]; !ok || !strings.EqualFold(alias, metaData.Alias) {
+		return nil, ErrUnknownReference
+	}
+	delete(p.references, p2pTx.Reference)
+
+	payment := &Payment{
+		Alias:     strings.ToLower(metaData.Alias),
+		Beef:      p2pTx.Beef,
+		Hex:       p2pTx.Hex,
+		MetaData:  p2pTx.MetaData,
+		Reference: p2pTx.Reference,
+		TxID:      tx.TxID().String(),
+	}
+	p.payments = append(p.payments, payment)
+
+	response := &paymail.P2PTransactionPayload{TxID: payment.TxID}
+	if p2pTx.MetaData != nil {
+		response.Note = p2pTx.MetaData.Note
+	}
+	return response, nil
+}
+
+// VerifyMerkleRoots will verify the merkle roots with the verifier (or accept all of them)
+func (s *serviceProvider) VerifyMerkleRoots(ctx context.Context,
+	merkleProofs []*spv.MerkleRootConfirmationRequestItem,
+) error {
+	if s.provider.verifier == nil {
+		return nil
+	}
+	return s.provider.verifier(ctx, merkleProofs)
+}
+
+// AddContact will record the PIKE contact request
+func (s *serviceProvider) AddContact(_ context.Context, requesterPaymail string,
+	contact *paymail.PikeContactRequestPayload,
+) error {
+	alias, domain, _ := paymail.SanitizePaymail(requesterPaymail)
+	if s.account(alias, domain) == nil {
+		return errors.ErrCouldNotFindPaymail
+	}
+
+	p := s.provider
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.contacts = append(p.contacts, &ContactRequest{Contact: contact, Receiver: requesterPaymail})
+	return nil
+}
+
+// CreatePikeOutputResponse will return the P2PKH output template of the account and a new reference
+func (s *serviceProvider) CreatePikeOutputResponse(_ context.Context, alias, domain, _ string,
+	satoshis uint64, _ *server.RequestMetadata,
+) (*paymail.PikePaymentOutputsResponse, error) {
+	outputs, reference, err := s.outputs(alias, domain, satoshis)
+	if err != nil {
+		return nil, err
+	}
+	return &paymail.PikePaymentOutputsResponse{Outputs: outputs, Reference: reference}, nil
+}
+
+// outputs will return the output of the account and register a new reference
+func (s *serviceProvider) outputs(alias, domain string, satoshis uint64) ([]*paymail.OutputTemplate, string, error) {
+	a := s.account(alias, domain)
+	if a == nil {
+		return nil, "", errors.ErrCouldNotFindPaymail
+	}
+
+	lockingScript, err := lockingScript(a)
+	if err != nil {
+		return nil, "", err
+	}
+
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	reference := hex.EncodeToString(b)
+
+	p := s.provider
+	p.mu.Lock()
+	p.references[reference] = a.Alias
+	p.mu.Unlock()
+
+	return []*paymail.OutputTemplate{{Satoshis: satoshis, Script: lockingScript.String()}}, reference, nil
+}
+
+// lockingScript will return the P2PKH locking script of the account
+func lockingScript(a *Account) (*script.Script, error) {
+	address, err := script.NewAddressFromPublicKey(a.PrivateKey.PubKey(), true)
+	if err != nil {
+		return nil, err
+	}
+	return p2pkh.Lock(address)
+}
@@ -20,7 +20,9 @@ type Resolver struct {
 	srvRecords   map[string][]*net.SRV
 }
 
-// NewCustomResolver will return a custom resolver with specific records hard coded ,
+// NewCustomResolver will return a custom resolver with specific records hard coded
+//
+// Lookups for unknown records are sent to the live resolver (or return a not found error if nil)
 func NewCustomResolver(liveResolver interfaces.DNSResolver, hosts map[string][]string,
 	srvRecords map[string][]*net.SRV, ipAddresses map[string][]net.IPAddr,
 ) interfaces.DNSResolver {
@@ -37,6 +39,8 @@ func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error
 	records, ok := r.hosts[host]
 	if ok {
 		return records, nil
+	} else if r.liveResolver == nil {
+		return nil, notFound(host)
 	}
 	return r.liveResolver.LookupHost(ctx, host)
 }
@@ -46,6 +50,8 @@ func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr,
 	records, ok := r.ipAddresses[host]
 	if ok {
 		return records, nil
+	} else if r.liveResolver == nil {
+		return nil, notFound(host)
 	}
 	return r.liveResolver.LookupIPAddr(ctx, host)
 }
@@ -58,10 +64,41 @@ func (r *Resolver) LookupSRV(ctx context.Context, service, proto, name string) (
 			return fmt.Sprintf("_%s._%s", service, proto), records, nil
 		}
 		return fmt.Sprintf("_%s._%s.%s.", service, proto, name), records, nil
+	} else if r.liveResolver == nil {
+		return "", nil, notFound(fmt.Sprintf("_%s._%s.%s", service, proto, name))
 	}
 	return r.liveResolver.LookupSRV(ctx, service, proto, name)
 }
 
+// AddHost will add (or replace) the addresses returned for a host
+func (r *Resolver) AddHost(host string, addresses ...string) {
+	if r.hosts == nil {
+		r.hosts = make(map[string][]string)
+	}
+	r.hosts[host] = addresses
+}
+
+// AddIPAddr will add (or replace) the ip addresses returned for a host
+func (r *Resolver) AddIPAddr(host string, addresses ...net.IPAddr) {
+	if r.ipAddresses == nil {
+		r.ipAddresses = make(map[string][]net.IPAddr)
+	}
+	r.ipAddresses[host] = addresses
+}
+
+// AddSRV will add (or replace) the SRV records returned for a service, protocol and name
+func (r *Resolver) AddSRV(service, proto, name string, records ...*net.SRV) {
+	if r.srvRecords == nil {
+		r.srvRecords = make(map[string][]*net.SRV)
+	}
+	r.srvRecords[service+proto+name] = records
+}
+
+// notFound will return the DNS error for a record that does not exist
+func notFound(name string) error {
+	return &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
 // MockResty will return a mocked Resty client
 func MockResty() *resty.Client {
 	// Create a Resty Client
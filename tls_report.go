@@ -0,0 +1,197 @@
+package paymail
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strconv"
+	"time"
+)
+
+// TLSReport is the detailed TLS inspection of a paymail host (one result per A/AAAA record)
+type TLSReport struct {
+	CheckTime time.Time      `json:"check_time"`
+	Domain    string         `json:"domain,omitempty"` // The paymail domain (if checked with CheckPaymailTLS)
+	Host      string         `json:"host"`             // The host (SRV target) that was checked
+	Port      int            `json:"port"`             // The port (SRV port) that was checked
+	Results   []*TLSIPResult `json:"results"`          // Results for every ip address of the host
+	Valid     bool           `json:"valid"`            // True if every reachable ip address has a valid certificate
+}
+
+// TLSIPResult is the TLS inspection of a single ip address of the host
+type TLSIPResult struct {
+	Chain         []string  `json:"chain,omitempty"`         // Subjects of the certificate chain (leaf first)
+	CipherSuite   string    `json:"cipher_suite,omitempty"`  // Negotiated cipher suite (IE: TLS_AES_128_GCM_SHA256)
+	DaysRemaining int       `json:"days_remaining"`          // Days until the leaf certificate expires (negative if expired)
+	DomainMatch   bool      `json:"domain_match"`            // The leaf certificate is valid for the paymail domain
+	ErrorMessage  string    `json:"error_message,omitempty"` // Connection or verification error
+	Expires       time.Time `json:"expires"`                 // Expiry of the leaf certificate
+	HostMatch     bool      `json:"host_match"`              // The leaf certificate is valid for the host (SRV target)
+	IP            string    `json:"ip"`                      // The ip address
+	Issuer        string    `json:"issuer,omitempty"`        // Issuer of the leaf certificate
+	OCSPStapled   bool      `json:"ocsp_stapled"`            // The server stapled an OCSP response
+	Reachable     bool      `json:"reachable"`               // The TLS handshake completed
+	SANs          []string  `json:"sans,omitempty"`          // DNS names and ip addresses of the leaf certificate
+	Subject       string    `json:"subject,omitempty"`       // Subject of the leaf certificate
+	Valid         bool      `json:"valid"`                   // The chain is trusted and the leaf is valid for the host
+	Verified      bool      `json:"verified"`                // The chain is trusted (and not expired)
+	Version       string    `json:"version,omitempty"`       // Negotiated TLS version (IE: TLS 1.3)
+}
+
+// CheckTLS will inspect the TLS certificates of the host on the port (every A/AAAA record is checked)
+//
+// Unlike CheckSSL, the report contains the negotiated version and cipher, the chain, the SAN match,
+// the expiry and OCSP stapling for every ip address. If the port is zero, the default port (443) is used
+func (c *Client) CheckTLS(ctx context.Context, host string, port int) (report *TLSReport, err error) {
+	ctx, end := c.startSpan(ctx, "CheckTLS", "", host)
+	defer func() { end(err) }()
+
+	return c.checkTLS(ctx, "", host, port)
+}
+
+// CheckPaymailTLS will inspect the TLS certificates of the SRV target of the paymail domain
+//
+// The SRV record is resolved first (see GetSRVRecord), and the SAN is also matched against the domain
+func (c *Client) CheckPaymailTLS(ctx context.Context, domain string) (report *TLSReport, err error) {
+	ctx, end := c.startSpan(ctx, "CheckPaymailTLS", "", domain)
+	defer func() { end(err) }()
+
+	var srv *net.SRV
+	if srv, err = c.GetSRVRecordCtx(ctx, DefaultServiceName, DefaultProtocol, domain); err != nil {
+		return nil, err
+	}
+	return c.checkTLS(ctx, domain, srv.Target, int(srv.Port))
+}
+
+// checkTLS will inspect the TLS certificates of all the ip addresses of the host
+func (c *Client) checkTLS(ctx context.Context, domain, host string, port int) (*TLSReport, error) {
+	if len(host) == 0 {
+		return nil, ErrSRVTargetInvalid
+	} else if port <= 0 {
+		port = DefaultPort
+	}
+
+	report := &TLSReport{
+		CheckTime: time.Now(),
+		Domain:    domain,
+		Host:      host,
+		Port:      port,
+	}
+
+	// Lookup the host (ip addresses are checked as-is)
+	var ips []net.IPAddr
+	if ip := net.ParseIP(host); ip != nil {
+		ips = []net.IPAddr{{IP: ip}}
+	} else {
+		var err error
+		if ips, err = c.resolver.LookupIPAddr(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+
+	reachable := 0
+	for _, ip := range ips {
+		// Stop if the context is done
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := c.inspectTLS(ctx, domain, host, ip.IP.String(), port)
+		report.Results = append(report.Results, result)
+		if result.Reachable {
+			reachable++
+		}
+	}
+
+	// Valid if every reachable ip address is valid (unreachable IPv6 addresses are common)
+	report.Valid = reachable > 0
+	for _, result := range report.Results {
+		if result.Reachable && !result.Valid {
+			report.Valid = false
+		}
+	}
+	return report, nil
+}
+
+// inspectTLS will connect to the ip address and inspect the connection and the certificates
+//
+// The certificates are verified after the handshake (not by the handshake), so invalid certificates can be reported
+func (c *Client) inspectTLS(ctx context.Context, domain, host, ip string, port int) *TLSIPResult {
+	result := &TLSIPResult{IP: ip}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{
+			Timeout:  c.options.sslTimeout,
+			Deadline: time.Now().Add(c.options.sslDeadline),
+		},
+		Config: &tls.Config{
+			InsecureSkipVerify: true, //nolint:gosec // G402: the chain is verified below (to report invalid certificates)
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         host,
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		result.ErrorMessage = err.Error()
+		return result
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		result.ErrorMessage = "not a tls connection"
+		return result
+	}
+	state := tlsConn.ConnectionState()
+	result.Reachable = true
+	result.Version = tls.VersionName(state.Version)
+	result.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	result.OCSPStapled = len(state.OCSPResponse) > 0
+	if len(state.PeerCertificates) == 0 {
+		result.ErrorMessage = "no certificate presented"
+		return result
+	}
+
+	// The leaf certificate
+	leaf := state.PeerCertificates[0]
+	result.Subject = leaf.Subject.String()
+	result.Issuer = leaf.Issuer.String()
+	result.Expires = leaf.NotAfter
+	result.DaysRemaining = int(time.Until(leaf.NotAfter).Hours() / 24)
+	result.SANs = append(result.SANs, leaf.DNSNames...)
+	for _, address := range leaf.IPAddresses {
+		result.SANs = append(result.SANs, address.String())
+	}
+	hostErr := leaf.VerifyHostname(host)
+	result.HostMatch = hostErr == nil
+	result.DomainMatch = len(domain) > 0 && leaf.VerifyHostname(domain) == nil
+
+	// Verify the chain (the names are matched separately)
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := leaf.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		Roots:         c.options.tlsRootCAs,
+	})
+	chain := state.PeerCertificates
+	if err == nil {
+		chain = chains[0]
+		result.Verified = true
+	} else {
+		result.ErrorMessage = err.Error()
+	}
+	for _, cert := range chain {
+		result.Chain = append(result.Chain, cert.Subject.String())
+	}
+
+	if result.Verified && hostErr != nil {
+		result.ErrorMessage = hostErr.Error()
+	}
+	result.Valid = result.Verified && result.HostMatch
+	return result
+}
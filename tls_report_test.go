@@ -0,0 +1,275 @@
+package paymail
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bsv-blockchain/go-paymail/tester"
+)
+
+// testTLSHost is the SRV target served by the test TLS servers
+const testTLSHost = "www." + testDomain
+
+// newTestCertificate will return a certificate for the names (signed by a new CA) and the CA pool
+func newTestCertificate(tb testing.TB, notAfter time.Time, names ...string) (tls.Certificate, *x509.CertPool) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	caTemplate := &x509.Certificate{
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		NotAfter:              time.Now().Add(time.Hour),
+		NotBefore:             time.Now().Add(-time.Hour),
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(tb, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(tb, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+	template := &x509.Certificate{
+		DNSNames:     names,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		NotAfter:     notAfter,
+		NotBefore:    time.Now().Add(-time.Hour),
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: names[0]},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(tb, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	return tls.Certificate{Certificate: [][]byte{der, caDER}, PrivateKey: key}, roots
+}
+
+// newTestTLSServer will start a TLS server with the certificate and return its port
+func newTestTLSServer(tb testing.TB, certificate tls.Certificate) int {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	tb.Cleanup(server.Close)
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return p
+}
+
+// newTestTLSClient will return a client resolving testTLSHost to the local ip address (and the SRV record to the port)
+func newTestTLSClient(t *testing.T, roots *x509.CertPool, port int) ClientInterface {
+	client, err := NewClient(WithTLSRootCAs(roots), WithSSLTimeout(time.Second))
+	require.NoError(t, err)
+
+	resolver := &tester.Resolver{}
+	resolver.AddIPAddr(testTLSHost, net.IPAddr{IP: net.ParseIP("127.0.0.1")})
+	resolver.AddIPAddr("unreachable."+testDomain, net.IPAddr{IP: net.ParseIP("127.0.0.1")}, net.IPAddr{IP: net.ParseIP("::1")})
+	resolver.AddSRV(DefaultServiceName, DefaultProtocol, testDomain, &net.SRV{
+		Port: uint16(port), Priority: DefaultPriority, Target: testTLSHost, Weight: DefaultWeight, //nolint:gosec // G115: test port
+	})
+	return client.WithCustomResolver(resolver)
+}
+
+// TestClient_CheckTLS will test the method CheckTLS()
+func TestClient_CheckTLS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid certificate", func(t *testing.T) {
+		certificate, roots := newTestCertificate(t, time.Now().Add(90*24*time.Hour+time.Hour), testTLSHost)
+		certificate.OCSPStaple = []byte("ocsp-response")
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, roots, port).CheckTLS(context.Background(), testTLSHost, port)
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.Equal(t, testTLSHost, report.Host)
+		assert.Equal(t, port, report.Port)
+		assert.Empty(t, report.Domain)
+
+		require.Len(t, report.Results, 1)
+		result := report.Results[0]
+		assert.Equal(t, "127.0.0.1", result.IP)
+		assert.True(t, result.Reachable)
+		assert.True(t, result.Verified)
+		assert.True(t, result.HostMatch)
+		assert.False(t, result.DomainMatch)
+		assert.True(t, result.Valid)
+		assert.True(t, result.OCSPStapled)
+		assert.Equal(t, "TLS 1.3", result.Version)
+		assert.NotEmpty(t, result.CipherSuite)
+		assert.Equal(t, "CN="+testTLSHost, result.Subject)
+		assert.Equal(t, "CN=Test Root CA", result.Issuer)
+		assert.Equal(t, []string{"CN=" + testTLSHost, "CN=Test Root CA"}, result.Chain)
+		assert.Equal(t, []string{testTLSHost}, result.SANs)
+		assert.Equal(t, 90, result.DaysRemaining)
+		assert.Empty(t, result.ErrorMessage)
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		certificate, _ := newTestCertificate(t, time.Now().Add(time.Hour), testTLSHost)
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, x509.NewCertPool(), port).CheckTLS(context.Background(), testTLSHost, port)
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Results, 1)
+		assert.True(t, report.Results[0].Reachable)
+		assert.False(t, report.Results[0].Verified)
+		assert.True(t, report.Results[0].HostMatch)
+		assert.Contains(t, report.Results[0].ErrorMessage, "unknown authority")
+		assert.Len(t, report.Results[0].Chain, 2)
+	})
+
+	t.Run("expired certificate", func(t *testing.T) {
+		certificate, roots := newTestCertificate(t, time.Now().Add(-48*time.Hour), testTLSHost)
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, roots, port).CheckTLS(context.Background(), testTLSHost, port)
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.False(t, report.Results[0].Verified)
+		assert.Equal(t, -2, report.Results[0].DaysRemaining)
+		assert.Contains(t, report.Results[0].ErrorMessage, "expired")
+	})
+
+	t.Run("host mismatch", func(t *testing.T) {
+		certificate, roots := newTestCertificate(t, time.Now().Add(time.Hour), "other.com")
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, roots, port).CheckTLS(context.Background(), testTLSHost, port)
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.True(t, report.Results[0].Verified)
+		assert.False(t, report.Results[0].HostMatch)
+		assert.Contains(t, report.Results[0].ErrorMessage, "not "+testTLSHost)
+	})
+
+	t.Run("unreachable ip addresses are reported", func(t *testing.T) {
+		certificate, roots := newTestCertificate(t, time.Now().Add(time.Hour), "unreachable."+testDomain)
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, roots, port).CheckTLS(context.Background(), "unreachable."+testDomain, port)
+		require.NoError(t, err)
+		require.Len(t, report.Results, 2)
+		assert.True(t, report.Results[0].Reachable)
+		assert.False(t, report.Results[1].Reachable)
+		assert.NotEmpty(t, report.Results[1].ErrorMessage)
+		assert.True(t, report.Valid)
+	})
+
+	t.Run("nothing reachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		require.NoError(t, listener.Close())
+
+		var report *TLSReport
+		report, err = newTestTLSClient(t, nil, port).CheckTLS(context.Background(), "127.0.0.1", port)
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Results, 1)
+		assert.False(t, report.Results[0].Reachable)
+	})
+
+	t.Run("invalid host", func(t *testing.T) {
+		client := newTestTLSClient(t, nil, 443)
+
+		_, err := client.CheckTLS(context.Background(), "", 443)
+		require.ErrorIs(t, err, ErrSRVTargetInvalid)
+
+		_, err = client.CheckTLS(context.Background(), "unknown.com", 443)
+		require.Error(t, err)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := newTestTLSClient(t, nil, 443).CheckTLS(ctx, "127.0.0.1", 443)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestClient_CheckPaymailTLS will test the method CheckPaymailTLS()
+func TestClient_CheckPaymailTLS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses the srv port and matches the domain", func(t *testing.T) {
+		certificate, roots := newTestCertificate(t, time.Now().Add(time.Hour), testTLSHost, testDomain)
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, roots, port).CheckPaymailTLS(context.Background(), testDomain)
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.Equal(t, testDomain, report.Domain)
+		assert.Equal(t, testTLSHost, report.Host)
+		assert.Equal(t, port, report.Port)
+		assert.True(t, report.Results[0].HostMatch)
+		assert.True(t, report.Results[0].DomainMatch)
+	})
+
+	t.Run("domain not in the certificate", func(t *testing.T) {
+		certificate, roots := newTestCertificate(t, time.Now().Add(time.Hour), testTLSHost)
+		port := newTestTLSServer(t, certificate)
+
+		report, err := newTestTLSClient(t, roots, port).CheckPaymailTLS(context.Background(), testDomain)
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.False(t, report.Results[0].DomainMatch)
+	})
+}
+
+// ExampleClient_CheckTLS example using CheckTLS()
+//
+// See more examples in /examples/
+func ExampleClient_CheckTLS() {
+	// Start a local TLS server (the certificate is valid for 127.0.0.1)
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+
+	// Load the client (trusting the certificate of the server)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client, _ := NewClient(WithTLSRootCAs(roots))
+
+	report, err := client.CheckTLS(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		fmt.Printf("error checking TLS: %s", err.Error())
+		return
+	}
+	fmt.Printf("valid: %t, version: %s", report.Valid, report.Results[0].Version)
+	// Output:valid: true, version: TLS 1.3
+}
+
+// BenchmarkClient_CheckTLS benchmarks the method CheckTLS()
+func BenchmarkClient_CheckTLS(b *testing.B) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	client, _ := NewClient(WithTLSRootCAs(roots))
+	for i := 0; i < b.N; i++ {
+		_, _ = client.CheckTLS(context.Background(), "127.0.0.1", port)
+	}
+}
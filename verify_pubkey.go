@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -57,6 +58,14 @@ type VerificationPayload struct {
 //
 // Specs: https://bsvalias.org/05-verify-public-key-owner.html
 func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error) {
+	return c.VerifyPubKeyCtx(context.Background(), verifyURL, alias, domain, pubKey)
+}
+
+// VerifyPubKeyCtx is the context-aware version of VerifyPubKey
+func (c *Client) VerifyPubKeyCtx(ctx context.Context, verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error) {
+	ctx, end := c.startSpan(ctx, "VerifyPubKey", BRFCVerifyPublicKeyOwner, domain)
+	defer func() { end(err) }()
+
 	// Require a valid url
 	if len(verifyURL) == 0 || !strings.Contains(verifyURL, "https://") {
 		err = fmt.Errorf("%s: %s: %w", "invalid url", verifyURL, ErrVerifyPubKeyInvalidURL)
@@ -81,7 +90,7 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
 		return response, err
 	}
 
@@ -90,11 +99,7 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return response, err
-		}
-		err = fmt.Errorf("code %d, message: %s: %w", response.StatusCode, serverError.Message, ErrVerifyPubKeyBadResponse)
+		err = newResponseError(&resp, reqURL, BRFCVerifyPublicKeyOwner, ErrVerifyPubKeyBadResponse)
 		return response, err
 	}
 
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -264,6 +265,22 @@ func TestClient_VerifyPubKey(t *testing.T) {
 		assert.Equal(t, http.StatusOK, verification.StatusCode)
 		assert.NotEqual(t, testPubKey, verification.PubKey)
 	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockVerifyPubKey(http.StatusOK)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		verification, err := client.VerifyPubKeyCtx(
+			ctx, testServerURL+"verifypubkey/{alias}@{domain.tld}/{pubkey}",
+			testAlias, testDomain, testPubKey,
+		)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, verification)
+	})
 }
 
 // mockVerifyPubKey is used for mocking the response